@@ -67,6 +67,14 @@ func main() {
 	handlers.AddInitialUsers(db)
 	// Starte Smart-Scheduler für Kampagnen-Sync (DB-Cache statt Live-API pro Request)
 	services.StartCampaignSyncScheduler(db)
+	// Worker und Hintergrundjobs enden mit dem Herunterfahren; der Validierungs-Worker reiht laufende Jobs wieder ein.
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	services.StartUploadAccessExpiryProcessor(workerCtx, db)
+	services.StartUploadTrashPurger(workerCtx, db)
+	services.StartUploadSessionCleanup(workerCtx, db)
+	services.StartUploadSLAMonitor(workerCtx, db)
+	services.StartValidationJobWorker(workerCtx, db, handlers.RunValidationJob)
 
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
@@ -102,30 +110,79 @@ func main() {
 
 	// Routes
 	app.Post("/api/upload", handlers.AuthRequired(), handleFileUpload)
+	app.Post("/api/uploads/sessions", handlers.AuthRequired(), handleCreateUploadSession)
+	app.Get("/api/uploads/sessions/:sessionId", handlers.AuthRequired(), handleGetUploadSession)
+	app.Patch("/api/uploads/sessions/:sessionId", handlers.AuthRequired(), handlePatchUploadSession)
+	app.Post("/api/uploads/sessions/:sessionId/finalize", handlers.AuthRequired(), handleFinalizeUploadSession)
+	app.Delete("/api/uploads/sessions/:sessionId", handlers.AuthRequired(), handleDeleteUploadSession)
 	app.Post("/api/uploads/manual-request", handlers.AuthRequired(), handleCreateManualRequestUpload)
 
 	// Add new routes
 	app.Get("/api/uploads", handlers.AuthRequired(), handleGetUploads)
 	app.Get("/api/advertisers", handlers.AuthRequired(), handleGetAdvertisers)
+	app.Get("/api/templates/:advertiser", handlers.AuthRequired(), handlers.HandleGetClaimTemplate(db))
+	app.Get("/api/claim-schemas", handlers.AuthRequired(), handlers.HandleListClaimSchemas(db))
+	app.Get("/api/claim-schemas/resolve", handlers.AuthRequired(), handlers.HandleResolveClaimSchema(db))
+	app.Put("/api/claim-schemas", handlers.AuthRequired(), handlers.HandleUpsertClaimSchema(db))
+	app.Delete("/api/claim-schemas/:schemaId", handlers.AuthRequired(), handlers.HandleDeleteClaimSchema(db))
+	app.Get("/api/header-mappings", handlers.AuthRequired(), handlers.HandleListHeaderMappingProfiles(db))
+	app.Put("/api/header-mappings", handlers.AuthRequired(), handlers.HandleUpsertHeaderMappingProfile(db))
+	app.Delete("/api/header-mappings/:profileId", handlers.AuthRequired(), handlers.HandleDeleteHeaderMappingProfile(db))
 	app.Get("/api/publishers", handlers.AuthRequired(), handleGetPublishers)
 	app.Get("/api/users", handlers.AuthRequired(), handleGetUsers)
 	app.Post("/api/users/me/avatar", handlers.AuthRequired(), handlers.HandleUploadAvatar(db))
 	app.Get("/api/users/me/avatar", handlers.AuthRequired(), handlers.HandleGetAvatar(db))
 	app.Delete("/api/users/me/avatar", handlers.AuthRequired(), handlers.HandleDeleteAvatar(db))
+	app.Get("/api/uploads/access/expiring", handlers.AuthRequired(), handlers.HandleListExpiringUploadAccess(db))
+	app.Post("/api/uploads/bulk", handlers.AuthRequired(), handlers.HandleBulkUploads(db))
+	app.Post("/api/uploads/merge", handlers.AuthRequired(), handleMergeUploads)
+	app.Get("/api/uploads/trash", handlers.AuthRequired(), handlers.HandleListTrashedUploads(db))
+	app.Post("/api/uploads/trash/:id/restore", handlers.AuthRequired(), handlers.HandleRestoreTrashedUpload(db))
+	app.Delete("/api/uploads/trash/:id", handlers.AuthRequired(), handlers.HandlePurgeTrashedUpload(db))
+	app.Get("/api/uploads/overdue", handlers.AuthRequired(), handlers.HandleListOverdueUploads(db))
+	app.Get("/api/uploads/sla/policies", handlers.AuthRequired(), handlers.HandleListUploadSLAPolicies(db))
+	app.Put("/api/uploads/sla/policies", handlers.AuthRequired(), handlers.HandleUpsertUploadSLAPolicy(db))
+	app.Delete("/api/uploads/sla/policies/:policyId", handlers.AuthRequired(), handlers.HandleDeleteUploadSLAPolicy(db))
 	app.Post("/api/uploads/:id/access", handlers.AuthRequired(), handleGrantAccessDB)
+	app.Get("/api/uploads/:id/access", handlers.AuthRequired(), handlers.HandleListUploadAccess(db))
+	app.Delete("/api/uploads/:id/access/:advertiserId", handlers.AuthRequired(), handlers.HandleRevokeUploadAccess(db))
 	app.Get("/api/uploads/:id/download", handlers.AuthRequired(), handleDownloadFile)
 	app.Patch("/api/uploads/:id/status", handlers.AuthRequired(), handleUpdateUploadStatus)
 	app.Delete("/api/uploads/:id", handlers.AuthRequired(), handleDeleteUpload)
 	app.Post("/api/uploads/:id/replace", handlers.AuthRequired(), handleReplaceUpload)
 	app.Post("/api/uploads/:id/return-to-publisher", handlers.AuthRequired(), handlers.HandleReturnToPublisher(db))
 	app.Post("/api/uploads/:id/request-feedback", handlers.AuthRequired(), handleRequestFeedbackFromPublisher)
+	app.Get("/api/uploads/:id/transitions", handlers.AuthRequired(), handlers.HandleGetUploadTransitions(db))
+	app.Get("/api/uploads/:id/revisions", handlers.AuthRequired(), handlers.HandleListUploadRevisions(db))
+	app.Get("/api/uploads/:id/revisions/:revisionId/download", handlers.AuthRequired(), handlers.HandleDownloadUploadRevision(db))
+	app.Post("/api/uploads/:id/revisions/:revisionId/restore", handlers.AuthRequired(), handlers.HandleRestoreUploadRevision(db))
+	app.Get("/api/uploads/:id/diff", handlers.AuthRequired(), handlers.HandleGetUploadDiff(db))
+	app.Get("/api/uploads/:id/duplicates", handlers.AuthRequired(), handlers.HandleGetUploadDuplicates(db))
+	app.Post("/api/uploads/:id/scan", handlers.AuthRequired(), handlers.HandleRescanUpload(db))
+	app.Post("/api/uploads/:id/split", handlers.AuthRequired(), handleSplitUpload)
+	app.Get("/api/uploads/:id/merged-rows", handlers.AuthRequired(), handlers.HandleListMergedRows(db))
+	app.Get("/api/uploads/comments/mentions", handlers.AuthRequired(), handlers.HandleListMyCommentMentions(db))
+	app.Get("/api/uploads/:id/comments", handlers.AuthRequired(), handlers.HandleListUploadComments(db))
+	app.Post("/api/uploads/:id/comments", handlers.AuthRequired(), handlers.HandleCreateUploadComment(db))
+	app.Patch("/api/uploads/:id/comments/:commentId", handlers.AuthRequired(), handlers.HandleUpdateUploadComment(db))
+	app.Delete("/api/uploads/:id/comments/:commentId", handlers.AuthRequired(), handlers.HandleDeleteUploadComment(db))
 	app.Get("/api/uploads/:id/content", handlers.AuthRequired(), handleGetFileContent)
 	app.Post("/api/uploads/:id/content", handlers.AuthRequired(), handleSaveFileContent)
+	app.Patch("/api/uploads/:id/content", handlers.AuthRequired(), handlePatchFileContent)
 
 	// ✅ Validation für Admin-Preview
+	app.Get("/api/uploads/:id/headers", handlers.AuthRequired(), handlers.HandlePreviewUploadHeaders(db))
 	app.Get("/api/uploads/:id/validate", handlers.AuthRequired(), handlers.HandleValidateUpload(db))
+	app.Post("/api/uploads/:id/validate", handlers.AuthRequired(), handlers.HandleEnqueueValidation(db))
+	app.Get("/api/uploads/:id/validation-jobs", handlers.AuthRequired(), handlers.HandleListUploadValidationJobs(db))
+	app.Get("/api/validation-jobs/:jobId", handlers.AuthRequired(), handlers.HandleGetValidationJob(db))
+	app.Post("/api/validation-jobs/:jobId/cancel", handlers.AuthRequired(), handlers.HandleCancelValidationJob(db))
 	// ✅ Gespeicherte Validierungsergebnisse laden
 	app.Get("/api/uploads/:id/validation", handlers.AuthRequired(), handlers.HandleGetValidation(db))
+	// ✅ Historie der Validierungsläufe + Vergleich zweier Läufe (diff vor :runId registrieren)
+	app.Get("/api/uploads/:id/validation-runs", handlers.AuthRequired(), handlers.HandleListValidationRuns(db))
+	app.Get("/api/uploads/:id/validation-runs/diff", handlers.AuthRequired(), handlers.HandleDiffValidationRuns(db))
+	app.Get("/api/uploads/:id/validation-runs/:runId", handlers.AuthRequired(), handlers.HandleGetValidationRun(db))
 	// ✅ Alle Validierungsergebnisse auf einmal laden
 	app.Get("/api/uploads/validations", handlers.AuthRequired(), handlers.HandleGetAllValidations(db))
 	// Nachbuchungen CSV: persistieren + versioniert archivieren
@@ -160,11 +217,14 @@ func main() {
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 	<-stop
 
+	stopWorkers()
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	if err := app.ShutdownWithContext(shutdownCtx); err != nil {
 		log.Printf("graceful shutdown failed: %v", err)
 	}
+	services.WaitValidationJobWorker(shutdownCtx)
+	services.WaitBackgroundJobs(shutdownCtx)
 }
 
 func hydrateEnvFromSecretFiles() {
@@ -202,7 +262,6 @@ func handleFileUpload(c *fiber.Ctx) error {
 	default:
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
 	}
-	userEmail, _ := claims["email"].(string)
 
 	file, err := c.FormFile("file")
 	if err != nil {
@@ -216,34 +275,360 @@ func handleFileUpload(c *fiber.Ctx) error {
 		})
 	}
 
-	filename := buildStoredUploadPath(file.Filename)
+	// Neue Dateien landen zuerst in der Quarantäne und werden erst nach sauberem Scan freigegeben.
+	filename, err := services.QuarantineUploadPath(buildStoredUploadPath(file.Filename))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save file",
+		})
+	}
 	if err := c.SaveFile(file, filename); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to save file",
 		})
 	}
 
+	payload, ok := createFileUpload(c, claims, filename, file.Filename, file.Size, file.Header.Get("Content-Type"))
+	if !ok {
+		return nil
+	}
+	return c.JSON(payload)
+}
+
+// createFileUpload legt für eine bereits gespeicherte Datei in Quarantäne den Upload an (Duplikatprüfung,
+// Inhaltsscan, Status, Revision, Ordertokens). Genutzt vom Multipart-Upload und vom Abschluss einer Upload-Session.
+// Bei ok=false wurde die Fehlerantwort bereits geschrieben und die Datei entfernt. Abgelehnte oder nicht
+// prüfbare Dateien werden trotzdem angelegt, bleiben aber in Quarantäne (Status 422 bzw. 202).
+func createFileUpload(c *fiber.Ctx, claims map[string]interface{}, storedPath string, originalName string, size int64, contentType string) (fiber.Map, bool) {
+	userEmail, _ := claims["email"].(string)
+
+	// Exakte Duplikate desselben Uploaders je nach UPLOAD_DUPLICATE_POLICY ablehnen oder markieren.
+	sum, err := services.UploadFileSHA256(storedPath)
+	if err != nil {
+		_ = os.Remove(storedPath)
+		_ = c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to hash file"})
+		return nil, false
+	}
+	duplicates, err := services.FindExactDuplicateUploads(db, sum, userEmail, 0)
+	if err != nil {
+		_ = os.Remove(storedPath)
+		_ = c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to check for duplicates"})
+		return nil, false
+	}
+	var duplicateOf *uint
+	if len(duplicates) > 0 {
+		if services.UploadDuplicatePolicy() == services.UploadDuplicatePolicyReject {
+			_ = os.Remove(storedPath)
+			_ = c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":       "Identical file was already uploaded",
+				"duplicateOf": duplicates[0].ID,
+				"filename":    duplicates[0].Filename,
+			})
+			return nil, false
+		}
+		duplicateOf = &duplicates[0].ID
+	}
+
+	scan := services.ScanUploadFile(c.Context(), services.ConfiguredUploadScanners(), storedPath)
+	if scan.Status == models.UploadScanStatusClean {
+		released, err := services.ReleaseQuarantinedFile(storedPath)
+		if err != nil {
+			_ = os.Remove(storedPath)
+			_ = c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save file"})
+			return nil, false
+		}
+		storedPath = released
+	}
+	scannedAt := time.Now()
+
+	role, _ := claims["role"].(string)
+	kind := models.UploadKindPublisherFile
+	if role == "admin" {
+		kind = models.UploadKindAdminUpload
+	}
 	upload := models.Upload{
-		Filename:       file.Filename,
-		FileSize:       file.Size,
-		ContentType:    file.Header.Get("Content-Type"),
-		UploadedBy:     userEmail,
-		LastModifiedBy: userEmail,
-		Status:         "pending",
-		FilePath:       filename,
+		Filename:            originalName,
+		FileSize:            size,
+		ContentType:         contentType,
+		UploadedBy:          userEmail,
+		LastModifiedBy:      userEmail,
+		Status:              models.UploadStatusPending,
+		Kind:                kind,
+		FilePath:            storedPath,
+		SHA256:              sum,
+		DuplicateOfUploadID: duplicateOf,
+		ScanStatus:          scan.Status,
+		ScanFindings:        scan.Findings,
+		ScannedAt:           &scannedAt,
+	}
+	// Ordertokens schon beim Hochladen erfassen, damit Doppel-Einreichungen sofort auffallen.
+	// Dateien in Quarantäne werden nicht geparst.
+	var data [][]string
+	tableErr := errors.New("file is quarantined")
+	if scan.Status == models.UploadScanStatusClean {
+		data, tableErr = lib.ReadUploadAsTable(storedPath)
+		if tableErr != nil {
+			log.Printf("warning: could not read uploaded table for order tokens: %v", tableErr)
+		}
 	}
-	if err := db.Create(&upload).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save upload in DB"})
+	actor := handlers.WorkflowActorFromClaims(jwt.MapClaims(claims))
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&upload).Error; err != nil {
+			return err
+		}
+		if err := services.RecordInitialUploadStatus(tx, upload, actor, "file_upload"); err != nil {
+			return err
+		}
+		if _, err := services.RecordUploadRevision(tx, upload, actor, "file_upload", nil); err != nil {
+			return err
+		}
+		if duplicateOf != nil {
+			if err := services.CreateAuditEvent(tx, actor.UserID, "UPLOAD_DUPLICATE_FLAGGED", "upload", upload.ID, "", nil, nil, map[string]any{
+				"duplicate_of_upload_id": *duplicateOf,
+				"sha256":                 sum,
+			}); err != nil {
+				return err
+			}
+		}
+		if tableErr != nil {
+			return nil
+		}
+		_, err := refreshCandidatesAndCollectDuplicateWarnings(tx, upload, data)
+		return err
+	}); err != nil {
+		_ = os.Remove(storedPath)
+		_ = c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save upload in DB"})
+		return nil, false
 	}
 
-	return c.JSON(fiber.Map{
-		"message":  "File uploaded successfully",
-		"filename": file.Filename,
-		"path":     filename,
-		"uploadId": upload.ID,
+	// Publisher sehen nur Überschneidungen mit eigenen Uploads, Admins alle.
+	scope := userEmail
+	if role == "admin" {
+		scope = ""
+	}
+	nearDuplicates := []services.NearDuplicateUpload{}
+	if report, err := services.FindNearDuplicateUploads(db, upload, scope, 5); err != nil {
+		log.Printf("warning: near-duplicate check failed for upload=%d: %v", upload.ID, err)
+	} else {
+		nearDuplicates = report.Duplicates
+	}
+
+	payload := fiber.Map{
+		"message":        "File uploaded successfully",
+		"filename":       originalName,
+		"path":           storedPath,
+		"uploadId":       upload.ID,
+		"sha256":         sum,
+		"duplicateOf":    duplicateOf,
+		"nearDuplicates": nearDuplicates,
+		"scanStatus":     scan.Status,
+		"scanFindings":   scan.Findings,
+	}
+	switch scan.Status {
+	case models.UploadScanStatusRejected:
+		c.Status(fiber.StatusUnprocessableEntity)
+		payload["error"] = "File was rejected by the content scan and stays quarantined"
+		delete(payload, "message")
+	case models.UploadScanStatusError:
+		c.Status(fiber.StatusAccepted)
+		payload["message"] = "File uploaded but quarantined until the content scan passes"
+	}
+	return payload, true
+}
+
+type createUploadSessionRequest struct {
+	Filename    string `json:"filename"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"contentType"`
+}
+
+// handleCreateUploadSession startet einen fortsetzbaren Upload für große Dateien.
+// Danach: PATCH mit Upload-Offset je Chunk, HEAD/GET für den Fortschritt, POST .../finalize zum Abschluss.
+func handleCreateUploadSession(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(jwt.MapClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+	}
+	userEmail, _ := claims["email"].(string)
+
+	var body createUploadSessionRequest
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	if body.Size == 0 {
+		body.Size, _ = strconv.ParseInt(c.Get("Upload-Length"), 10, 64)
+	}
+	filename := filepath.Base(strings.TrimSpace(body.Filename))
+	ext := strings.ToLower(filepath.Ext(filename))
+	if filename == "." || filename == "" || !slices.Contains(allowedUploadExtensions(), ext) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fmt.Sprintf("file extension %q is not allowed", ext)})
+	}
+	maxBytes := services.UploadResumableMaxBytes()
+	if body.Size <= 0 || body.Size > maxBytes {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": fmt.Sprintf("size must be between 1 and %d bytes", maxBytes)})
+	}
+
+	actor := handlers.WorkflowActorFromClaims(claims)
+	session, err := services.CreateUploadSession(db, actor.UserID, userEmail, filename, strings.TrimSpace(body.ContentType), body.Size)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create upload session"})
+	}
+
+	c.Set("Location", "/api/uploads/sessions/"+session.ID)
+	setUploadSessionHeaders(c, session)
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"id":            session.ID,
+		"offset":        session.Offset,
+		"size":          session.Size,
+		"expiresAt":     session.ExpiresAt,
+		"chunkMaxBytes": services.UploadChunkMaxBytes(),
 	})
 }
 
+// handleGetUploadSession liefert den Fortschritt (auch als HEAD mit Upload-Offset/Upload-Length).
+func handleGetUploadSession(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(jwt.MapClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+	}
+	userEmail, _ := claims["email"].(string)
+
+	session, err := services.GetUploadSession(db, c.Params("sessionId"), userEmail)
+	if err != nil {
+		return uploadSessionError(c, err)
+	}
+	setUploadSessionHeaders(c, session)
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	if c.Method() == fiber.MethodHead {
+		return c.SendStatus(fiber.StatusOK)
+	}
+	return c.JSON(session)
+}
+
+// handlePatchUploadSession hängt einen Chunk an; Header Upload-Offset muss dem aktuellen Stand entsprechen.
+func handlePatchUploadSession(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(jwt.MapClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+	}
+	userEmail, _ := claims["email"].(string)
+
+	offset, err := strconv.ParseInt(strings.TrimSpace(c.Get("Upload-Offset")), 10, 64)
+	if err != nil || offset < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Upload-Offset header is required"})
+	}
+	chunk := c.Body()
+	if len(chunk) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Chunk is empty"})
+	}
+	if int64(len(chunk)) > services.UploadChunkMaxBytes() {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": fmt.Sprintf("chunk too large (max %d bytes)", services.UploadChunkMaxBytes())})
+	}
+
+	session, err := services.AppendUploadSessionChunk(db, c.Params("sessionId"), userEmail, offset, chunk)
+	if err != nil {
+		return uploadSessionError(c, err)
+	}
+	setUploadSessionHeaders(c, session)
+	return c.JSON(fiber.Map{"offset": session.Offset, "size": session.Size})
+}
+
+// handleFinalizeUploadSession prüft die vollständige Datei wie einen Multipart-Upload und legt den Upload an.
+func handleFinalizeUploadSession(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(jwt.MapClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+	}
+	userEmail, _ := claims["email"].(string)
+
+	session, err := services.BeginUploadSessionFinalize(db, c.Params("sessionId"), userEmail)
+	switch {
+	case errors.Is(err, services.ErrUploadSessionIncomplete):
+		setUploadSessionHeaders(c, session)
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":  "Upload session is incomplete",
+			"offset": session.Offset,
+			"size":   session.Size,
+		})
+	case errors.Is(err, services.ErrUploadSessionClosed):
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{
+			"error":    "Upload session is no longer open",
+			"status":   session.Status,
+			"uploadId": session.UploadID,
+		})
+	case err != nil:
+		return uploadSessionError(c, err)
+	}
+
+	if err := validateUploadSource(session.Filename, session.Size, services.UploadResumableMaxBytes(), func() (io.ReadCloser, error) {
+		return os.Open(session.TempPath)
+	}); err != nil {
+		// Session bleibt offen: der Client kann den Abschluss wiederholen oder sie selbst abbrechen.
+		if reopenErr := services.ReopenUploadSession(db, session); reopenErr != nil {
+			log.Printf("warning: failed to reopen upload session %s: %v", session.ID, reopenErr)
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "sessionId": session.ID, "status": models.UploadSessionStatusOpen})
+	}
+
+	storedPath, err := services.QuarantineUploadPath(buildStoredUploadPath(session.Filename))
+	if err != nil {
+		_ = services.FinishUploadSession(db, session, nil)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save file"})
+	}
+	if err := os.Rename(session.TempPath, storedPath); err != nil {
+		_ = services.FinishUploadSession(db, session, nil)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save file"})
+	}
+
+	payload, ok := createFileUpload(c, map[string]interface{}(claims), storedPath, session.Filename, session.Size, session.ContentType)
+	if !ok {
+		_ = services.FinishUploadSession(db, session, nil)
+		return nil
+	}
+	uploadID, _ := payload["uploadId"].(uint)
+	if err := services.FinishUploadSession(db, session, &uploadID); err != nil {
+		log.Printf("warning: failed to close upload session %s: %v", session.ID, err)
+	}
+	payload["sessionId"] = session.ID
+	return c.JSON(payload)
+}
+
+// handleDeleteUploadSession bricht eine offene Session ab.
+func handleDeleteUploadSession(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(jwt.MapClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+	}
+	userEmail, _ := claims["email"].(string)
+
+	if err := services.AbortUploadSession(db, c.Params("sessionId"), userEmail); err != nil {
+		return uploadSessionError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "Upload session aborted"})
+}
+
+func setUploadSessionHeaders(c *fiber.Ctx, session models.UploadSession) {
+	c.Set("Upload-Offset", strconv.FormatInt(session.Offset, 10))
+	c.Set("Upload-Length", strconv.FormatInt(session.Size, 10))
+}
+
+func uploadSessionError(c *fiber.Ctx, err error) error {
+	var offsetErr *services.UploadSessionOffsetError
+	switch {
+	case errors.Is(err, services.ErrUploadSessionNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Upload session not found"})
+	case errors.As(err, &offsetErr):
+		c.Set("Upload-Offset", strconv.FormatInt(offsetErr.Expected, 10))
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error(), "offset": offsetErr.Expected})
+	case errors.Is(err, services.ErrUploadChunkTooLarge):
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrUploadSessionClosed):
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{"error": "Upload session is no longer open"})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Upload session failed"})
+	}
+}
+
 func handleCreateManualRequestUpload(c *fiber.Ctx) error {
 	u := c.Locals("user")
 	var claims map[string]interface{}
@@ -269,6 +654,7 @@ func handleCreateManualRequestUpload(c *fiber.Ctx) error {
 		AdvertiserPresetName string     `json:"advertiserPresetName"`
 		PublisherID          uint       `json:"publisherId"`
 		PublisherEmail       string     `json:"publisherEmail"`
+		CampaignID           string     `json:"campaignId"`
 	}
 	if err := c.BodyParser(&body); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
@@ -301,74 +687,44 @@ func handleCreateManualRequestUpload(c *fiber.Ctx) error {
 		}
 	}
 
-	headers := []string{
-		"Publisher ID",
-		"Vollständiger Name des Endkunden",
-		"Adresse des Endkunden",
-		"E-Mailadresse des Endkunden",
-		"Sonstige Daten/Dokumente des Endkunden (Optional)",
-		"Höhe der Provision (Optional)",
-		"Grund der Anfrage",
-		"Timestamp",
-		"SubID",
-		"Ordertoken/OrderID",
-		"Ordertoken/Order ID",
-		"Abgeschlossener Tarif",
-		"Belieferungsbeginn (Optional)",
-		"Feedback",
-		"Status in der uppr Performance Platform",
-		"Sonstiges Feedback",
+	// Zeilen kommen positionsweise in der Spaltenreihenfolge des Advertiser-Schemas (siehe /api/templates/:advertiser.json).
+	schema, err := services.ResolveClaimSchema(db, []uint{advertiser.ID}, body.CampaignID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to resolve claim schema"})
+	}
+	headers := schema.ColumnNames()
+	rows, err := schema.ManualRequestRows(body.Rows)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error(), "columns": headers})
+	}
+	if len(rows) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "At least one row is required"})
 	}
 
 	filenameBase := fmt.Sprintf("manual_request_%s.csv", time.Now().Format("20060102_150405"))
 	if role == "advertiser" {
 		filenameBase = fmt.Sprintf("manual_request_advertiser_%s.csv", time.Now().Format("20060102_150405"))
 	}
-	storedPath := buildStoredUploadPath(filenameBase)
-
-	outFile, err := os.Create(storedPath)
+	// Die erzeugte Datei durchläuft dieselben Scanner wie Uploads (z. B. eingetippte Formeln).
+	storedPath, err := services.QuarantineUploadPath(buildStoredUploadPath(filenameBase))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create manual request file"})
 	}
-	defer outFile.Close()
-
-	writer := csv.NewWriter(outFile)
-	if err := writer.Write(headers); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to write header"})
-	}
-	for _, row := range body.Rows {
-		normalized := make([]string, len(headers))
-		for i := 0; i < len(headers) && i < len(row); i++ {
-			normalized[i] = strings.TrimSpace(row[i])
-		}
-		// Nur sinnvolle Zeilen speichern.
-		rowHasContent := false
-		for _, cell := range normalized {
-			if cell != "" {
-				rowHasContent = true
-				break
-			}
-		}
-		if !rowHasContent {
-			continue
-		}
-		if err := writer.Write(normalized); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to write data row"})
-		}
-	}
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to finalize csv"})
+	if err := writeManualRequestCSV(storedPath, headers, rows); err != nil {
+		_ = os.Remove(storedPath)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to write manual request file"})
 	}
-
 	fileInfo, statErr := os.Stat(storedPath)
 	if statErr != nil {
+		_ = os.Remove(storedPath)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to read created file"})
 	}
 
-	initialStatus := "pending"
+	initialStatus := models.UploadStatusPending
+	kind := models.UploadKindPublisherManualRequest
 	if role == "advertiser" {
-		initialStatus = "feedback_submitted_advertiser"
+		initialStatus = models.UploadStatusFeedbackSubmittedAdvertiser
+		kind = models.UploadKindAdvertiserManualRequest
 	}
 	upload := models.Upload{
 		Filename:       filenameBase,
@@ -377,24 +733,31 @@ func handleCreateManualRequestUpload(c *fiber.Ctx) error {
 		UploadedBy:     userEmail,
 		LastModifiedBy: userEmail,
 		Status:         initialStatus,
-		FilePath:       storedPath,
+		Kind:           kind,
 	}
 	if role == "advertiser" {
 		upload.UploadedBy = targetPublisher.Email
 	}
-	if err := db.Create(&upload).Error; err != nil {
-		_ = os.Remove(storedPath)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save upload in DB"})
-	}
-
-	access := models.UploadAccess{
-		UploadID:     upload.ID,
-		AdvertiserID: advertiser.ID,
+	storedPath, ok := scanIncomingFile(c, &upload, storedPath)
+	if !ok {
+		return nil
 	}
-	if err := db.Create(&access).Error; err != nil {
-		_ = db.Delete(&upload).Error
+	upload.FilePath = storedPath
+	actor := handlers.WorkflowActorFromClaims(jwt.MapClaims(claims))
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&upload).Error; err != nil {
+			return err
+		}
+		if err := services.RecordInitialUploadStatus(tx, upload, actor, "manual_request"); err != nil {
+			return err
+		}
+		if _, err := services.RecordUploadRevision(tx, upload, actor, "manual_request", nil); err != nil {
+			return err
+		}
+		return services.GrantUploadAccess(tx, upload.ID, advertiser.ID, nil)
+	}); err != nil {
 		_ = os.Remove(storedPath)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to assign advertiser"})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save upload in DB"})
 	}
 
 	return c.JSON(fiber.Map{
@@ -404,6 +767,24 @@ func handleCreateManualRequestUpload(c *fiber.Ctx) error {
 	})
 }
 
+// writeManualRequestCSV schreibt Header und Zeilen einer Manuellanfrage als CSV-Datei.
+func writeManualRequestCSV(path string, headers []string, rows [][]string) error {
+	outFile, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(outFile)
+	if err := writer.Write(headers); err != nil {
+		_ = outFile.Close()
+		return err
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		_ = outFile.Close()
+		return err
+	}
+	return outFile.Close()
+}
+
 func resolveManualRequestAdvertiser(advertiserID uint, presetName string) (models.User, error) {
 	var advertiser models.User
 	if advertiserID > 0 {
@@ -458,6 +839,9 @@ func resolveManualRequestPublisher(publisherID uint, publisherEmail string) (mod
 }
 
 // Handle get uploads
+//
+// Ohne limit/cursor wird wie bisher die komplette (gefilterte) Liste als Array geliefert.
+// Mit limit oder cursor antwortet der Endpoint mit {items, nextCursor}.
 func handleGetUploads(c *fiber.Ctx) error {
 	u := c.Locals("user")
 	var claims map[string]interface{}
@@ -472,91 +856,200 @@ func handleGetUploads(c *fiber.Ctx) error {
 	role := claims["role"].(string)
 	userEmail := claims["email"].(string)
 
-	var uploads []models.Upload
-	var err error
-
-	if role == "admin" {
-		err = db.Order("created_at desc").Find(&uploads).Error
-	} else if role == "advertiser" {
-		// 1. Eigene Uploads
-		var ownUploads []models.Upload
-		db.Where("uploaded_by = ?", userEmail).Order("created_at desc").Find(&ownUploads)
-
-		// 2. Zugewiesene Uploads
+	scope := services.UploadListScope{Role: role, Email: userEmail}
+	switch role {
+	case "admin", "publisher":
+	case "advertiser":
 		var user models.User
-		err2 := db.Where("email = ?", userEmail).First(&user).Error
-		var accessUploads []models.Upload
-
-		if err2 == nil {
-			var accesses []models.UploadAccess
-			db.Where("advertiser_id = ?", user.ID).Find(&accesses)
-
-			var uploadIDs []uint
-			for _, a := range accesses {
-				uploadIDs = append(uploadIDs, a.UploadID)
-			}
-			if len(uploadIDs) > 0 {
-				db.Where("id IN ? AND status <> ?", uploadIDs, "pending").Order("created_at desc").Find(&accessUploads)
-			}
-		}
-
-		// merge ohne Duplikate
-		uploadMap := make(map[uint]models.Upload)
-		for _, u := range ownUploads {
-			uploadMap[u.ID] = u
-		}
-		for _, u := range accessUploads {
-			uploadMap[u.ID] = u
+		if err := db.Where("email = ?", userEmail).First(&user).Error; err == nil {
+			scope.AdvertiserID = user.ID
 		}
-		for _, u := range uploadMap {
-			uploads = append(uploads, u)
-		}
-
-	} else if role == "publisher" {
-		err = db.Where("uploaded_by = ?", userEmail).Order("created_at desc").Find(&uploads).Error
-	} else {
+	default:
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed"})
 	}
 
+	opts, paginated, parseErr := parseUploadListOptions(c)
+	if parseErr != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": parseErr.Error()})
+	}
+
+	page, err := services.ListUploads(db, scope, opts)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidUploadCursor) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid cursor"})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch uploads"})
 	}
 
-	// Mapping: UploadID -> AdvertiserID (nur letzter Eintrag zählt)
-	var accesses []models.UploadAccess
-	db.Find(&accesses)
-	m := map[uint]uint{}
-	for _, a := range accesses {
-		m[a.UploadID] = a.AdvertiserID
+	uploadIDs := make([]uint, 0, len(page.Uploads))
+	for _, upload := range page.Uploads {
+		uploadIDs = append(uploadIDs, upload.ID)
 	}
 
-	// Hole alle User (für E-Mail)
-	var users []models.User
-	db.Find(&users)
+	// Mapping: UploadID -> alle Advertiser-Freigaben (in Vergabereihenfolge)
+	m := map[uint][]models.UploadAccess{}
 	userMap := map[uint]string{}
-	for _, u := range users {
-		userMap[u.ID] = u.Email
+	if len(uploadIDs) > 0 {
+		accessQuery := db.Where("upload_id IN ?", uploadIDs)
+		if role == "advertiser" {
+			// Advertiser sehen nur ihre eigene Freigabe, nicht welche anderen Advertiser die Datei haben.
+			accessQuery = accessQuery.Where("advertiser_id = ?", scope.AdvertiserID)
+		}
+		var accesses []models.UploadAccess
+		accessQuery.Order("created_at asc, id asc").Find(&accesses)
+		advertiserIDs := make([]uint, 0, len(accesses))
+		for _, a := range accesses {
+			m[a.UploadID] = append(m[a.UploadID], a)
+			advertiserIDs = append(advertiserIDs, a.AdvertiserID)
+		}
+
+		// Nur die benötigten User laden (für E-Mail)
+		if len(advertiserIDs) > 0 {
+			var users []models.User
+			db.Where("id IN ?", advertiserIDs).Find(&users)
+			for _, u := range users {
+				userMap[u.ID] = u.Email
+			}
+		}
 	}
 
-	// Baue Response mit assigned_advertiser_email
+	// Baue Response mit assigned_advertisers; assigned_advertiser_email (zuletzt vergebene
+	// Freigabe) bleibt für bestehende Clients erhalten.
+	type AssignedAdvertiser struct {
+		ID        uint       `json:"id"`
+		Email     string     `json:"email"`
+		ExpiresAt *time.Time `json:"expires_at"`
+		Status    string     `json:"status"`
+	}
 	type UploadWithAdvertiser struct {
 		models.Upload
-		AssignedAdvertiserEmail *string `json:"assigned_advertiser_email"`
+		AssignedAdvertiserEmail *string              `json:"assigned_advertiser_email"`
+		AssignedAdvertisers     []AssignedAdvertiser `json:"assigned_advertisers"`
 	}
-	uploadsWithAdvertiser := make([]UploadWithAdvertiser, 0, len(uploads))
-	for _, u := range uploads {
+	uploadsWithAdvertiser := make([]UploadWithAdvertiser, 0, len(page.Uploads))
+	for _, u := range page.Uploads {
 		var emailPtr *string
-		if advID, ok := m[u.ID]; ok {
-			if email, ok2 := userMap[advID]; ok2 {
-				emailPtr = &email
+		assigned := make([]AssignedAdvertiser, 0, len(m[u.ID]))
+		for _, a := range m[u.ID] {
+			email, ok := userMap[a.AdvertiserID]
+			if !ok {
+				continue
 			}
+			emailPtr = &email
+			assigned = append(assigned, AssignedAdvertiser{
+				ID:        a.AdvertiserID,
+				Email:     email,
+				ExpiresAt: a.ExpiresAt,
+				Status:    a.Status,
+			})
 		}
 		uploadsWithAdvertiser = append(uploadsWithAdvertiser, UploadWithAdvertiser{
 			Upload:                  u,
 			AssignedAdvertiserEmail: emailPtr,
+			AssignedAdvertisers:     assigned,
 		})
 	}
-	return c.JSON(uploadsWithAdvertiser)
+	if !paginated {
+		return c.JSON(uploadsWithAdvertiser)
+	}
+
+	var nextCursor *string
+	if page.NextCursor != "" {
+		nextCursor = &page.NextCursor
+	}
+	return c.JSON(fiber.Map{
+		"items":      uploadsWithAdvertiser,
+		"nextCursor": nextCursor,
+		"limit":      opts.Limit,
+	})
+}
+
+func parseUploadListOptions(c *fiber.Ctx) (services.UploadListOptions, bool, error) {
+	opts := services.UploadListOptions{
+		SortField: "created_at",
+		SortDesc:  true,
+	}
+
+	if sortField := strings.ToLower(strings.TrimSpace(c.Query("sort"))); sortField != "" {
+		if !services.IsValidUploadSortField(sortField) {
+			return opts, false, fmt.Errorf("invalid sort field %q", sortField)
+		}
+		opts.SortField = sortField
+	}
+	switch strings.ToLower(strings.TrimSpace(c.Query("order"))) {
+	case "", "desc":
+		opts.SortDesc = true
+	case "asc":
+		opts.SortDesc = false
+	default:
+		return opts, false, errors.New("order must be asc or desc")
+	}
+
+	for _, part := range strings.Split(c.Query("status"), ",") {
+		if status := strings.TrimSpace(part); status != "" {
+			opts.Filter.Statuses = append(opts.Filter.Statuses, status)
+		}
+	}
+	opts.Filter.UploadedBy = strings.TrimSpace(c.Query("uploadedBy"))
+	opts.Filter.Search = strings.TrimSpace(c.Query("q"))
+
+	if raw := strings.TrimSpace(c.Query("advertiserId")); raw != "" {
+		advertiserID, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil || advertiserID == 0 {
+			return opts, false, errors.New("invalid advertiserId")
+		}
+		opts.Filter.AdvertiserID = uint(advertiserID)
+	} else if email := strings.TrimSpace(c.Query("advertiserEmail")); email != "" {
+		var advertiser models.User
+		if err := db.Where("email = ? AND role = ?", email, "advertiser").First(&advertiser).Error; err != nil {
+			return opts, false, errors.New("unknown advertiserEmail")
+		}
+		opts.Filter.AdvertiserID = advertiser.ID
+	}
+
+	if raw := strings.TrimSpace(c.Query("from")); raw != "" {
+		from, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return opts, false, errors.New("from must be formatted as YYYY-MM-DD")
+		}
+		opts.Filter.CreatedFrom = &from
+	}
+	if raw := strings.TrimSpace(c.Query("to")); raw != "" {
+		to, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return opts, false, errors.New("to must be formatted as YYYY-MM-DD")
+		}
+		// "to" ist inklusiv gemeint: alles bis Tagesende.
+		to = to.AddDate(0, 0, 1)
+		opts.Filter.CreatedTo = &to
+	}
+
+	if kind := strings.ToLower(strings.TrimSpace(c.Query("kind"))); kind != "" {
+		if !services.IsValidUploadListKind(kind) {
+			return opts, false, fmt.Errorf("invalid kind %q", kind)
+		}
+		opts.Filter.Kind = kind
+	}
+
+	rawLimit := strings.TrimSpace(c.Query("limit"))
+	opts.Cursor = strings.TrimSpace(c.Query("cursor"))
+	paginated := rawLimit != "" || opts.Cursor != ""
+	if !paginated {
+		return opts, false, nil
+	}
+
+	opts.Limit = services.DefaultUploadPageSize
+	if rawLimit != "" {
+		limit, err := strconv.Atoi(rawLimit)
+		if err != nil || limit <= 0 {
+			return opts, false, errors.New("limit must be a positive number")
+		}
+		if limit > services.MaxUploadPageSize {
+			limit = services.MaxUploadPageSize
+		}
+		opts.Limit = limit
+	}
+	return opts, true, nil
 }
 
 // Handle get advertisers
@@ -677,50 +1170,46 @@ func handleGrantAccessDB(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can grant access"})
 	}
 
-	id := c.Params("id")
+	uploadID := c.Params("id")
+	// Einzelne Freigabe (advertiserId/expiresAt) oder mehrere über "assignments".
 	var body struct {
-		AdvertiserId uint       `json:"advertiserId"`
-		ExpiresAt    *time.Time `json:"expiresAt"`
+		services.UploadAccessAssignment
+		Assignments []services.UploadAccessAssignment `json:"assignments"`
 	}
 	if err := c.BodyParser(&body); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
 	}
-
-	uploadID := id
-	access := models.UploadAccess{
-		UploadID:     parseUint(uploadID),
-		AdvertiserID: body.AdvertiserId,
-		ExpiresAt:    body.ExpiresAt,
+	assignments := body.Assignments
+	if body.AdvertiserID != 0 {
+		assignments = append(assignments, body.UploadAccessAssignment)
+	}
+	if len(assignments) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "advertiserId or assignments is required"})
 	}
+	if err := services.ValidateUploadAccessAssignments(db, assignments); err != nil {
+		if errors.Is(err, services.ErrInvalidAdvertiserSelection) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid advertiser selection"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to grant access"})
+	}
+
+	actor := handlers.WorkflowActorFromClaims(jwt.MapClaims(claims))
 	if err := db.Transaction(func(tx *gorm.DB) error {
 		var upload models.Upload
 		if err := tx.First(&upload, uploadID).Error; err != nil {
 			return err
 		}
-		if err := tx.Where("upload_id = ?", uploadID).Delete(&models.UploadAccess{}).Error; err != nil {
-			return err
-		}
-		if err := tx.Create(&access).Error; err != nil {
-			return err
-		}
-		nextStatus := "assigned"
-		if isAdvertiserManualRequest(upload) && upload.Status == "feedback" {
-			// Sonderfall: nach Publisher-Rücklauf sendet Admin final an Advertiser.
-			nextStatus = "returned_to_publisher"
-		}
-		return tx.Model(&models.Upload{}).Where("id = ?", uploadID).Update("status", nextStatus).Error
+		return services.AssignUploadAccess(tx, &upload, assignments, actor)
 	}); err != nil {
+		if errors.Is(err, services.ErrIllegalUploadTransition) {
+			return handlers.UploadTransitionConflict(c, err)
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to grant access"})
 	}
 
 	return c.JSON(fiber.Map{"message": "Access granted successfully"})
 }
 
-func parseUint(s string) uint {
-	n, _ := strconv.ParseUint(s, 10, 64)
-	return uint(n)
-}
-
 func handleDownloadFile(c *fiber.Ctx) error {
 	u := c.Locals("user")
 	var claims map[string]interface{}
@@ -757,10 +1246,20 @@ func handleDownloadFile(c *fiber.Ctx) error {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed to download this file"})
 		}
 	}
+	if services.IsUploadQuarantined(upload) {
+		return handlers.UploadQuarantined(c, upload)
+	}
 
 	return c.Download(upload.FilePath, upload.Filename)
 }
 
+// uploadStatusActions ordnet die per PUT /status setzbaren Zielstatus den Upload-Aktionen zu.
+var uploadStatusActions = map[string]string{
+	models.UploadStatusApproved:  services.UploadActionApprove,
+	models.UploadStatusRejected:  services.UploadActionReject,
+	models.UploadStatusCompleted: services.UploadActionComplete,
+}
+
 func handleUpdateUploadStatus(c *fiber.Ctx) error {
 	u := c.Locals("user")
 	var claims map[string]interface{}
@@ -772,48 +1271,47 @@ func handleUpdateUploadStatus(c *fiber.Ctx) error {
 	default:
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
 	}
-	role := claims["role"].(string)
-
 	id := c.Params("id")
 	var body struct {
 		Status string `json:"status"`
+		Reason string `json:"reason"`
 	}
 	if err := c.BodyParser(&body); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
 	}
-	if body.Status != "approved" && body.Status != "rejected" && body.Status != "completed" {
+	action, ok := uploadStatusActions[body.Status]
+	if !ok {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid status value"})
 	}
 
-	if body.Status == "completed" {
-		if role != "publisher" && role != "advertiser" {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only publisher or advertiser can complete uploads"})
-		}
-		if role == "advertiser" {
-			var upload models.Upload
-			if err := db.First(&upload, id).Error; err != nil {
-				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "File not found"})
-			}
-			if !isAdvertiserManualRequest(upload) {
-				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Advertiser can only complete advertiser manual requests"})
-			}
-			userEmail := claims["email"].(string)
-			var user models.User
-			if err := db.Where("email = ?", userEmail).First(&user).Error; err != nil {
-				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed"})
-			}
-			ok, accessErr := hasActiveUploadAccess(upload.ID, user.ID)
-			if accessErr != nil || !ok {
-				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed"})
-			}
-		}
-	} else {
-		if role != "admin" {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can update status"})
+	var upload models.Upload
+	if err := db.First(&upload, id).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "File not found"})
+	}
+
+	// Gleiche Rollenregeln wie bei Bulk-Aktionen.
+	user, ok := handlers.LoadCurrentUser(c, db)
+	if !ok {
+		return nil
+	}
+	if err := services.CheckUploadActionPermission(db, user, upload, action); err != nil {
+		if errors.Is(err, services.ErrUploadActionForbidden) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed to set this status"})
 		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to check permissions"})
 	}
 
-	if err := db.Model(&models.Upload{}).Where("id = ?", id).Update("status", body.Status).Error; err != nil {
+	reason := strings.TrimSpace(body.Reason)
+	if reason == "" {
+		reason = "status_update"
+	}
+	actor := handlers.WorkflowActorFromClaims(jwt.MapClaims(claims))
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		return services.UpdateUploadStatus(tx, &upload, body.Status, actor, reason)
+	}); err != nil {
+		if errors.Is(err, services.ErrIllegalUploadTransition) {
+			return handlers.UploadTransitionConflict(c, err)
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update status"})
 	}
 
@@ -847,6 +1345,20 @@ func handleRequestFeedbackFromPublisher(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed"})
 	}
 
+	// Special Case Advertiser-Manuellanfrage:
+	// Publisher schickt die Datei zur Admin-Prüfung zurück => "Netzwerk-Verarbeitung".
+	nextStatus := models.UploadStatusFeedbackSubmitted
+	if services.IsAdvertiserManualRequest(upload) {
+		nextStatus = models.UploadStatusFeedback
+	}
+	if upload.Status != nextStatus {
+		if err := services.CheckUploadTransition(upload, nextStatus, role); err != nil {
+			return handlers.UploadTransitionConflict(c, err)
+		}
+	}
+
+	previous := upload
+	attachedPath := ""
 	message := ""
 	contentType := strings.ToLower(strings.TrimSpace(c.Get("Content-Type")))
 	if strings.Contains(contentType, "multipart/form-data") {
@@ -857,18 +1369,27 @@ func handleRequestFeedbackFromPublisher(c *fiber.Ctx) error {
 				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 			}
 			if strings.ToLower(filepath.Ext(file.Filename)) == ".csv" {
-				newPath := buildStoredUploadPath(file.Filename)
+				// Der Anhang wird neuer Dateistand und unterliegt denselben Sperren wie Bearbeiten/Ersetzen.
+				if err := services.EnsureUploadContentEditable(upload); err != nil {
+					return handlers.UploadContentLocked(c, err)
+				}
+				newPath, err := services.QuarantineUploadPath(buildStoredUploadPath(file.Filename))
+				if err != nil {
+					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save attached csv"})
+				}
 				if err := c.SaveFile(file, newPath); err != nil {
 					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save attached csv"})
 				}
-				oldPath := upload.FilePath
+				newPath, ok := scanIncomingFile(c, &upload, newPath)
+				if !ok {
+					return nil
+				}
+				// Die bisherige Datei bleibt als Revision erhalten.
+				attachedPath = newPath
 				upload.FilePath = newPath
 				upload.Filename = file.Filename
 				upload.FileSize = file.Size
 				upload.ContentType = file.Header.Get("Content-Type")
-				if err := os.Remove(oldPath); err != nil && !os.IsNotExist(err) {
-					log.Printf("warning: failed to remove previous upload file: %v", err)
-				}
 			}
 		}
 	} else {
@@ -881,17 +1402,38 @@ func handleRequestFeedbackFromPublisher(c *fiber.Ctx) error {
 		message = strings.TrimSpace(body.Message)
 	}
 
-	// Special Case Advertiser-Manuellanfrage:
-	// Publisher schickt die Datei zur Admin-Prüfung zurück => "Netzwerk-Verarbeitung".
-	if isAdvertiserManualRequest(upload) {
-		upload.Status = "feedback"
-	} else {
-		upload.Status = "feedback_submitted"
-	}
 	upload.FeedbackMessage = message
 	upload.LastModifiedBy = userEmail
 	upload.UpdatedAt = time.Now()
-	if err := db.Save(&upload).Error; err != nil {
+	actor := handlers.WorkflowActorFromClaims(jwt.MapClaims(claims))
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if attachedPath != "" {
+			if err := services.LockEditableUpload(tx, upload.ID); err != nil {
+				return err
+			}
+		}
+		if err := services.TransitionUploadStatus(tx, &upload, nextStatus, actor, "feedback_requested"); err != nil {
+			return err
+		}
+		if attachedPath != "" {
+			if err := services.EnsureBaselineUploadRevision(tx, previous); err != nil {
+				return err
+			}
+			if _, err := services.RecordUploadRevision(tx, upload, actor, "feedback_attachment", nil); err != nil {
+				return err
+			}
+		}
+		return tx.Save(&upload).Error
+	}); err != nil {
+		if attachedPath != "" {
+			_ = os.Remove(attachedPath)
+		}
+		if errors.Is(err, services.ErrUploadContentLocked) {
+			return handlers.UploadContentLocked(c, err)
+		}
+		if errors.Is(err, services.ErrIllegalUploadTransition) {
+			return handlers.UploadTransitionConflict(c, err)
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to request feedback"})
 	}
 
@@ -903,6 +1445,45 @@ func handleRequestFeedbackFromPublisher(c *fiber.Ctx) error {
 
 // Handle delete upload
 func handleDeleteUpload(c *fiber.Ctx) error {
+	u := c.Locals("user")
+	var claims map[string]interface{}
+	switch v := u.(type) {
+	case map[string]interface{}:
+		claims = v
+	case jwt.MapClaims:
+		claims = map[string]interface{}(v)
+	default:
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+	}
+	id := c.Params("id")
+	var upload models.Upload
+	if err := db.First(&upload, id).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "File not found"})
+	}
+
+	user, ok := handlers.LoadCurrentUser(c, db)
+	if !ok {
+		return nil
+	}
+	if err := services.CheckUploadActionPermission(db, user, upload, services.UploadActionDelete); err != nil {
+		if errors.Is(err, services.ErrUploadActionForbidden) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed to delete this file"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to check permissions"})
+	}
+
+	// Upload landet im Papierkorb; Datei und abhängige Daten entfernt erst der Purge-Job.
+	actor := handlers.WorkflowActorFromClaims(jwt.MapClaims(claims))
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		return services.TrashUpload(tx, upload, actor)
+	}); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete upload in DB"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Upload moved to trash"})
+}
+
+func handleReplaceUpload(c *fiber.Ctx) error {
 	u := c.Locals("user")
 	var claims map[string]interface{}
 	switch v := u.(type) {
@@ -922,43 +1503,286 @@ func handleDeleteUpload(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "File not found"})
 	}
 
-	if role != "admin" && upload.UploadedBy != userEmail {
-		if role == "advertiser" && isAdvertiserManualRequest(upload) {
+	if role != "admin" {
+		if upload.UploadedBy == userEmail {
+			// ok
+		} else if role == "advertiser" {
 			var user models.User
 			if err := db.Where("email = ?", userEmail).First(&user).Error; err != nil {
-				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed to delete this file"})
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed to replace this file"})
 			}
-			ok, accessErr := hasActiveUploadAccess(upload.ID, user.ID)
-			if accessErr != nil || !ok {
-				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed to delete this file"})
+			ok, err := hasActiveUploadAccess(upload.ID, user.ID)
+			if err != nil || !ok {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed to replace this file"})
 			}
 		} else {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed to delete this file"})
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed to replace this file"})
+		}
+	}
+
+	if err := services.EnsureUploadContentEditable(upload); err != nil {
+		return handlers.UploadContentLocked(c, err)
+	}
+	if role == "advertiser" && upload.Status != models.UploadStatusFeedback {
+		if err := services.CheckUploadTransition(upload, models.UploadStatusFeedback, role); err != nil {
+			return handlers.UploadTransitionConflict(c, err)
+		}
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No file uploaded"})
+	}
+	if err := validateUploadFile(file); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	filename, err := services.QuarantineUploadPath(buildStoredUploadPath(file.Filename))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save file"})
+	}
+	if err := c.SaveFile(file, filename); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save file"})
+	}
+	previous := upload
+	filename, ok := scanIncomingFile(c, &upload, filename)
+	if !ok {
+		return nil
+	}
+
+	upload.Filename = file.Filename
+	upload.FileSize = file.Size
+	upload.ContentType = file.Header.Get("Content-Type")
+	upload.FilePath = filename
+	upload.UpdatedAt = time.Now()
+	upload.LastModifiedBy = userEmail
+	actor := handlers.WorkflowActorFromClaims(jwt.MapClaims(claims))
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := services.LockEditableUpload(tx, upload.ID); err != nil {
+			return err
+		}
+		if role == "advertiser" {
+			// Advertiser-Bearbeitung geht zuerst in die Netzwerk-Verarbeitung.
+			// "Rückfrage" ist ein separater Publisher-Schritt und wird nur dort gesetzt.
+			if err := services.TransitionUploadStatus(tx, &upload, models.UploadStatusFeedback, actor, "file_replaced"); err != nil {
+				return err
+			}
+			if err := services.MarkUploadAccessProgress(tx, upload.ID, userEmail, models.UploadAccessStatusSubmitted); err != nil {
+				return err
+			}
+		}
+		if err := services.EnsureBaselineUploadRevision(tx, previous); err != nil {
+			return err
+		}
+		if _, err := services.RecordUploadRevision(tx, upload, actor, "file_replaced", nil); err != nil {
+			return err
+		}
+		return tx.Save(&upload).Error
+	}); err != nil {
+		_ = os.Remove(filename)
+		if errors.Is(err, services.ErrUploadContentLocked) {
+			return handlers.UploadContentLocked(c, err)
+		}
+		if errors.Is(err, services.ErrIllegalUploadTransition) {
+			return handlers.UploadTransitionConflict(c, err)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update upload in DB"})
+	}
+	return c.JSON(fiber.Map{"message": "File replaced successfully"})
+}
+
+type splitUploadChild struct {
+	UploadID     uint   `json:"uploadId"`
+	Filename     string `json:"filename"`
+	Value        string `json:"value"`
+	Rows         int    `json:"rows"`
+	AdvertiserID uint   `json:"advertiserId,omitempty"`
+	Status       string `json:"status"`
+}
+
+// handleSplitUpload teilt eine Sammeldatei nach den Werten einer Spalte (z. B. Kampagne, Advertiser,
+// Publisher ID) in Teil-Uploads mit dem Original-Header auf (nur Admin). Mit assign=true wird jeder
+// Teil direkt dem passenden Advertiser freigegeben (advertiserMap oder Abgleich über ID/E-Mail/Name/Firma).
+func handleSplitUpload(c *fiber.Ctx) error {
+	u := c.Locals("user")
+	var claims map[string]interface{}
+	switch v := u.(type) {
+	case map[string]interface{}:
+		claims = v
+	case jwt.MapClaims:
+		claims = map[string]interface{}(v)
+	default:
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+	}
+	role, _ := claims["role"].(string)
+	userEmail, _ := claims["email"].(string)
+	if role != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can split uploads"})
+	}
+
+	var body struct {
+		Column        string          `json:"column"`
+		Assign        bool            `json:"assign"`
+		AdvertiserMap map[string]uint `json:"advertiserMap"`
+		ExpiresAt     *time.Time      `json:"expiresAt"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	column := strings.TrimSpace(body.Column)
+	if column == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "column is required"})
+	}
+
+	var parent models.Upload
+	if err := db.First(&parent, c.Params("id")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "File not found"})
+	}
+	if services.IsUploadQuarantined(parent) {
+		return handlers.UploadQuarantined(c, parent)
+	}
+	if err := services.EnsureUploadNotSplit(db, parent.ID); err != nil {
+		if errors.Is(err, services.ErrUploadAlreadySplit) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Upload was already split; delete the child uploads first"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load child uploads"})
+	}
+
+	data, err := lib.ReadUploadAsTable(parent.FilePath)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Failed to read file: " + err.Error()})
+	}
+	groups, err := lib.SplitTableByColumn(data, column)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if len(groups) < 2 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "column has fewer than two distinct values, nothing to split"})
+	}
+
+	// Advertiser je Wert: explizite Zuordnung vor automatischem Abgleich.
+	advertiserByValue := map[string]uint{}
+	unmatched := []string{}
+	if body.Assign {
+		values := make([]string, 0, len(groups))
+		for _, g := range groups {
+			values = append(values, g.Value)
+		}
+		matches, err := services.MatchSplitAdvertisers(db, values)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to match advertisers"})
+		}
+		assignments := []services.UploadAccessAssignment{}
+		for _, value := range values {
+			id, ok := body.AdvertiserMap[value]
+			if !ok {
+				id = matches[value]
+			}
+			if id == 0 {
+				unmatched = append(unmatched, value)
+				continue
+			}
+			advertiserByValue[value] = id
+			assignments = append(assignments, services.UploadAccessAssignment{AdvertiserID: id})
+		}
+		if len(assignments) > 0 {
+			if err := services.ValidateUploadAccessAssignments(db, assignments); err != nil {
+				if errors.Is(err, services.ErrInvalidAdvertiserSelection) {
+					return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid advertiser selection"})
+				}
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to validate advertisers"})
+			}
 		}
 	}
 
-	if err := db.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Where("upload_id = ?", upload.ID).Delete(&models.UploadAccess{}).Error; err != nil {
-			return err
+	written := make([]string, 0, len(groups))
+	removeWritten := func() {
+		for _, path := range written {
+			_ = os.Remove(path)
 		}
-		if err := tx.Where("upload_id = ?", upload.ID).Delete(&models.ValidationResult{}).Error; err != nil {
-			return err
+	}
+	children := make([]models.Upload, len(groups))
+	for i, g := range groups {
+		filename := services.SplitChildFilename(parent, g.Value)
+		storedPath := buildStoredUploadPath(strings.TrimSuffix(filename, filepath.Ext(filename)) + filepath.Ext(parent.FilePath))
+		if err := lib.WriteUploadTable(storedPath, g.Data); err != nil {
+			removeWritten()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to write child file"})
 		}
-		if err := tx.Where("upload_id = ?", upload.ID).Delete(&models.UploadOrderCandidate{}).Error; err != nil {
+		written = append(written, storedPath)
+		info, err := os.Stat(storedPath)
+		if err != nil {
+			removeWritten()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to write child file"})
+		}
+		// Die Teile stammen aus der bereits geprüften Ausgangsdatei.
+		children[i] = models.Upload{
+			Filename:       filename,
+			FileSize:       info.Size(),
+			ContentType:    parent.ContentType,
+			LastModifiedBy: userEmail,
+			FilePath:       storedPath,
+			ScanStatus:     parent.ScanStatus,
+			ScanFindings:   parent.ScanFindings,
+			ScannedAt:      parent.ScannedAt,
+		}
+	}
+
+	actor := handlers.WorkflowActorFromClaims(jwt.MapClaims(claims))
+	result := make([]splitUploadChild, len(groups))
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		// Erneut unter Zeilensperre prüfen: ein paralleler Split kann seit der Vorprüfung durchgelaufen sein.
+		if err := services.EnsureUploadNotSplit(tx, parent.ID); err != nil {
 			return err
 		}
-		return tx.Delete(&upload).Error
+		childIDs := make([]uint, len(groups))
+		for i, g := range groups {
+			advertiserID := advertiserByValue[g.Value]
+			if err := services.CreateSplitChildUpload(tx, parent, &children[i], advertiserID, body.ExpiresAt, actor); err != nil {
+				return err
+			}
+			if _, err := refreshCandidatesAndCollectDuplicateWarnings(tx, children[i], g.Data); err != nil {
+				return err
+			}
+			childIDs[i] = children[i].ID
+			result[i] = splitUploadChild{
+				UploadID:     children[i].ID,
+				Filename:     children[i].Filename,
+				Value:        g.Value,
+				Rows:         g.Rows,
+				AdvertiserID: advertiserID,
+				Status:       children[i].Status,
+			}
+		}
+		return services.CreateAuditEvent(tx, actor.UserID, "UPLOAD_SPLIT", "upload", parent.ID, "", nil, map[string]any{
+			"column":           column,
+			"child_upload_ids": childIDs,
+		}, nil)
 	}); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete upload in DB"})
-	}
-	if err := os.Remove(upload.FilePath); err != nil && !os.IsNotExist(err) {
-		log.Printf("warning: failed to delete upload file after DB delete: %v", err)
+		removeWritten()
+		if errors.Is(err, services.ErrUploadAlreadySplit) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Upload was already split; delete the child uploads first"})
+		}
+		if errors.Is(err, services.ErrIllegalUploadTransition) {
+			return handlers.UploadTransitionConflict(c, err)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to split upload"})
 	}
 
-	return c.JSON(fiber.Map{"message": "Upload deleted successfully"})
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"parentId":  parent.ID,
+		"column":    column,
+		"children":  result,
+		"unmatched": unmatched,
+	})
 }
 
-func handleReplaceUpload(c *fiber.Ctx) error {
+const maxMergeUploadIDs = 50
+
+// handleMergeUploads führt mehrere Uploads mit kompatiblem Header zu einer Sammeldatei zusammen (nur Admin).
+// Zeilen werden über den Ordertoken dedupliziert, die Herkunft jeder Zeile wird gespeichert und die
+// Quellen gehen in den Status "superseded".
+func handleMergeUploads(c *fiber.Ctx) error {
 	u := c.Locals("user")
 	var claims map[string]interface{}
 	switch v := u.(type) {
@@ -969,68 +1793,128 @@ func handleReplaceUpload(c *fiber.Ctx) error {
 	default:
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
 	}
-	role := claims["role"].(string)
-	userEmail := claims["email"].(string)
+	role, _ := claims["role"].(string)
+	userEmail, _ := claims["email"].(string)
+	if role != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can merge uploads"})
+	}
 
-	id := c.Params("id")
-	var upload models.Upload
-	if err := db.First(&upload, id).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "File not found"})
+	var body struct {
+		IDs      []uint `json:"ids"`
+		Filename string `json:"filename"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	ids := make([]uint, 0, len(body.IDs))
+	seen := map[uint]bool{}
+	for _, id := range body.IDs {
+		if id != 0 && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) < 2 || len(ids) > maxMergeUploadIDs {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ids must contain between 2 and 50 upload ids"})
 	}
 
-	if role != "admin" {
-		if upload.UploadedBy == userEmail {
-			// ok
-		} else if role == "advertiser" {
-			var user models.User
-			if err := db.Where("email = ?", userEmail).First(&user).Error; err != nil {
-				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed to replace this file"})
-			}
-			ok, err := hasActiveUploadAccess(upload.ID, user.ID)
-			if err != nil || !ok {
-				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed to replace this file"})
-			}
-		} else {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed to replace this file"})
+	// Reihenfolge der ids bestimmt, welches Vorkommen eines Ordertokens erhalten bleibt.
+	sources := make([]models.Upload, len(ids))
+	tables := make([]lib.MergeSource, len(ids))
+	actor := handlers.WorkflowActorFromClaims(jwt.MapClaims(claims))
+	allClean := true
+	for i, id := range ids {
+		if err := db.First(&sources[i], id).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": fmt.Sprintf("Upload %d not found", id)})
+		}
+		if services.IsUploadQuarantined(sources[i]) {
+			return handlers.UploadQuarantined(c, sources[i])
 		}
+		if err := services.CheckUploadTransition(sources[i], models.UploadStatusSuperseded, actor.Role); err != nil {
+			return handlers.UploadTransitionConflict(c, err)
+		}
+		data, err := lib.ReadUploadAsTable(sources[i].FilePath)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fmt.Sprintf("Failed to read upload %d: %v", id, err)})
+		}
+		tables[i] = lib.MergeSource{ID: id, Data: data}
+		allClean = allClean && sources[i].ScanStatus == models.UploadScanStatusClean
 	}
 
-	file, err := c.FormFile("file")
+	// Schlüsselspalte wie beim Einlesen über Schema und Mapping-Profil der ersten Quelle auflösen.
+	headerCtx, err := services.LoadUploadHeaderContext(db, sources[0], "")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No file uploaded"})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to resolve header mapping"})
 	}
-	if err := validateUploadFile(file); err != nil {
+	merged, err := lib.MergeTables(tables, headerCtx.TableKey())
+	if err != nil {
+		var mismatch *lib.HeaderMismatchError
+		if errors.As(err, &mismatch) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": "Headers are not compatible", "mismatch": mismatch})
+		}
+		if errors.Is(err, lib.ErrNoOrderTokenColumn) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": "No order token column found, uploads cannot be deduplicated"})
+		}
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	filename := buildStoredUploadPath(file.Filename)
-	if err := c.SaveFile(file, filename); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save file"})
+	ext := filepath.Ext(sources[0].FilePath)
+	filename := strings.TrimSpace(body.Filename)
+	if filename == "" {
+		base := strings.TrimSuffix(filepath.Base(sources[0].Filename), filepath.Ext(sources[0].Filename))
+		filename = fmt.Sprintf("%s_merged_%s", base, time.Now().Format("20060102"))
+	}
+	filename = strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename)) + ext
+	storedPath := buildStoredUploadPath(filename)
+	if err := lib.WriteUploadTable(storedPath, merged.Data); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to write merged file"})
+	}
+	info, err := os.Stat(storedPath)
+	if err != nil {
+		_ = os.Remove(storedPath)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to write merged file"})
 	}
-	oldFilePath := upload.FilePath
 
-	upload.Filename = file.Filename
-	upload.FileSize = file.Size
-	upload.ContentType = file.Header.Get("Content-Type")
-	upload.FilePath = filename
-	upload.UpdatedAt = time.Now()
-	upload.LastModifiedBy = userEmail
-	if role == "advertiser" {
-		// Advertiser-Bearbeitung geht zuerst in die Netzwerk-Verarbeitung.
-		// "Rückfrage" ist ein separater Publisher-Schritt und wird nur dort gesetzt.
-		upload.Status = "feedback"
+	uploadedBy, kind := services.MergedUploadOwner(sources, userEmail)
+	upload := models.Upload{
+		Filename:       filename,
+		FileSize:       info.Size(),
+		ContentType:    sources[0].ContentType,
+		UploadedBy:     uploadedBy,
+		LastModifiedBy: userEmail,
+		Kind:           kind,
+		FilePath:       storedPath,
+	}
+	// Die Zeilen stammen aus bereits geprüften Quellen.
+	if allClean {
+		now := time.Now()
+		upload.ScanStatus = models.UploadScanStatusClean
+		upload.ScannedAt = &now
 	}
+	var warnings []saveContentWarning
 	if err := db.Transaction(func(tx *gorm.DB) error {
-		return tx.Save(&upload).Error
+		if err := services.CreateMergedUpload(tx, sources, &upload, merged.Origins, actor); err != nil {
+			return err
+		}
+		warnings, err = refreshCandidatesAndCollectDuplicateWarnings(tx, upload, merged.Data)
+		return err
 	}); err != nil {
-		_ = os.Remove(filename)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update upload in DB"})
-	}
-	if err := os.Remove(oldFilePath); err != nil && !os.IsNotExist(err) {
-		log.Printf("warning: failed to remove previous upload file: %v", err)
+		_ = os.Remove(storedPath)
+		if errors.Is(err, services.ErrIllegalUploadTransition) {
+			return handlers.UploadTransitionConflict(c, err)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to merge uploads"})
 	}
 
-	return c.JSON(fiber.Map{"message": "File replaced successfully"})
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"uploadId":    upload.ID,
+		"filename":    upload.Filename,
+		"rows":        len(merged.Origins),
+		"keyColumn":   merged.KeyColumn,
+		"sources":     ids,
+		"skippedRows": merged.Skipped,
+		"warnings":    warnings,
+	})
 }
 
 // Handle get file content
@@ -1072,90 +1956,302 @@ func handleGetFileContent(c *fiber.Ctx) error {
 		}
 	}
 
+	if services.IsUploadQuarantined(upload) {
+		return handlers.UploadQuarantined(c, upload)
+	}
+
 	// ✅ Datei lesen und parsen (shared lib)
 	data, err := lib.ReadUploadAsTable(upload.FilePath)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Failed to read file: " + err.Error()})
 	}
 
+	// Der gelesene Stand muss beim Speichern als If-Match zurückgeschickt werden.
+	revisionID, err := services.HeadUploadRevisionID(db, upload.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load revision"})
+	}
+	etag := services.UploadRevisionETag(revisionID)
+	c.Set(fiber.HeaderETag, etag)
+
 	return c.JSON(fiber.Map{
-		"data":     data,
-		"filename": upload.Filename,
+		"data":       data,
+		"filename":   upload.Filename,
+		"revisionId": revisionID,
+		"etag":       etag,
 	})
 }
 
 // Handle save file content
 func handleSaveFileContent(c *fiber.Ctx) error {
+	edit, ok := prepareUploadContentEdit(c)
+	if !ok {
+		return nil
+	}
+
+	// Request Body parsen
+	var body struct {
+		Data [][]string `json:"data"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	return commitUploadContentEdit(c, edit, body.Data, func(tx *gorm.DB) ([]saveContentWarning, error) {
+		return refreshCandidatesAndCollectDuplicateWarnings(tx, edit.upload, body.Data)
+	})
+}
+
+// Handle patch file content: einzelne Zell-/Zeilenoperationen statt der ganzen Tabelle.
+func handlePatchFileContent(c *fiber.Ctx) error {
+	edit, ok := prepareUploadContentEdit(c)
+	if !ok {
+		return nil
+	}
+
+	var body struct {
+		Operations []lib.TableOp `json:"operations"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	if len(body.Operations) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "operations must not be empty"})
+	}
+
+	current, err := lib.ReadUploadAsTable(edit.upload.FilePath)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Failed to read file: " + err.Error()})
+	}
+	// Kopfzeile und Ordertoken-Spalte wie bei Validierung und Kandidaten (Schema + Mapping-Profil).
+	headerCtx, err := services.LoadUploadHeaderContext(db, edit.upload, "")
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to resolve header mapping"})
+	}
+	patch, err := lib.ApplyTableOps(current, body.Operations, headerCtx.TableKey())
+	if err != nil {
+		var opErr *lib.TableOpError
+		if errors.As(err, &opErr) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error":          opErr.Err,
+				"operationIndex": opErr.Index,
+				"op":             opErr.Op,
+			})
+		}
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return commitUploadContentEdit(c, edit, patch.Data, func(tx *gorm.DB) ([]saveContentWarning, error) {
+		return refreshChangedCandidatesAndCollectDuplicateWarnings(tx, edit.upload, headerCtx, patch)
+	})
+}
+
+// scanIncomingFile prüft eine Ersatzdatei in Quarantäne. Nur saubere Dateien werden freigegeben und
+// der Scanstatus am Upload gesetzt; sonst wird die Datei verworfen und die Fehlerantwort geschrieben.
+func scanIncomingFile(c *fiber.Ctx, upload *models.Upload, path string) (string, bool) {
+	scan := services.ScanUploadFile(c.Context(), services.ConfiguredUploadScanners(), path)
+	switch scan.Status {
+	case models.UploadScanStatusRejected:
+		_ = os.Remove(path)
+		_ = c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":        "File was rejected by the content scan",
+			"scanStatus":   scan.Status,
+			"scanFindings": scan.Findings,
+		})
+		return "", false
+	case models.UploadScanStatusError:
+		_ = os.Remove(path)
+		_ = c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error":        "Content scan unavailable, please try again later",
+			"scanStatus":   scan.Status,
+			"scanFindings": scan.Findings,
+		})
+		return "", false
+	}
+	released, err := services.ReleaseQuarantinedFile(path)
+	if err != nil {
+		_ = os.Remove(path)
+		_ = c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save file"})
+		return "", false
+	}
+	now := time.Now()
+	upload.ScanStatus = scan.Status
+	upload.ScanFindings = scan.Findings
+	upload.ScannedAt = &now
+	return released, true
+}
+
+type uploadContentEdit struct {
+	claims             map[string]interface{}
+	role               string
+	userEmail          string
+	upload             models.Upload
+	expectedRevisionID uint
+}
+
+// prepareUploadContentEdit prüft Berechtigung, Workflow und If-Match für eine Inhaltsänderung.
+// Bei ok=false wurde die Fehlerantwort bereits geschrieben.
+func prepareUploadContentEdit(c *fiber.Ctx) (uploadContentEdit, bool) {
+	var edit uploadContentEdit
 	u := c.Locals("user")
-	var claims map[string]interface{}
 	switch v := u.(type) {
 	case map[string]interface{}:
-		claims = v
+		edit.claims = v
 	case jwt.MapClaims:
-		claims = map[string]interface{}(v)
+		edit.claims = map[string]interface{}(v)
 	default:
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		_ = c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		return edit, false
 	}
-	role := claims["role"].(string)
-	userEmail := claims["email"].(string)
+	edit.role, _ = edit.claims["role"].(string)
+	edit.userEmail, _ = edit.claims["email"].(string)
+	role := edit.role
 
-	id := c.Params("id")
-	var upload models.Upload
-	if err := db.First(&upload, id).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "File not found"})
+	if err := db.First(&edit.upload, c.Params("id")).Error; err != nil {
+		_ = c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "File not found"})
+		return edit, false
 	}
 
 	// Berechtigung prüfen
-	if role != "admin" {
-		if upload.UploadedBy != userEmail {
-			if role == "advertiser" {
-				var user models.User
-				if err := db.Where("email = ?", userEmail).First(&user).Error; err != nil {
-					return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed"})
-				}
-				ok, err := hasActiveUploadAccess(upload.ID, user.ID)
-				if err != nil || !ok {
-					return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed"})
-				}
-			} else {
-				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed"})
+	if role != "admin" && edit.upload.UploadedBy != edit.userEmail {
+		allowed := false
+		if role == "advertiser" {
+			var user models.User
+			if err := db.Where("email = ?", edit.userEmail).First(&user).Error; err == nil {
+				ok, err := hasActiveUploadAccess(edit.upload.ID, user.ID)
+				allowed = err == nil && ok
 			}
 		}
+		if !allowed {
+			_ = c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed"})
+			return edit, false
+		}
 	}
 
-	// Request Body parsen
-	var body struct {
-		Data [][]string `json:"data"`
+	if services.IsUploadQuarantined(edit.upload) {
+		_ = handlers.UploadQuarantined(c, edit.upload)
+		return edit, false
 	}
-	if err := c.BodyParser(&body); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+	if err := services.EnsureUploadContentEditable(edit.upload); err != nil {
+		_ = handlers.UploadContentLocked(c, err)
+		return edit, false
 	}
 
-	// ✅ Datei schreiben (shared lib)
-	if err := lib.WriteUploadTable(upload.FilePath, body.Data); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Failed to save file: " + err.Error()})
+	if role == "advertiser" && edit.upload.Status != models.UploadStatusFeedback {
+		if err := services.CheckUploadTransition(edit.upload, models.UploadStatusFeedback, role); err != nil {
+			_ = handlers.UploadTransitionConflict(c, err)
+			return edit, false
+		}
+	}
+
+	// Optimistic Locking: gespeichert wird nur auf Basis des zuletzt gelesenen Stands.
+	ifMatch := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if ifMatch == "" {
+		_ = c.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{"error": "If-Match header with the current revision is required"})
+		return edit, false
+	}
+	expectedRevisionID, ok := services.ParseUploadRevisionETag(ifMatch)
+	if !ok {
+		_ = c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid If-Match header"})
+		return edit, false
 	}
+	if err := services.CheckUploadHeadRevision(db, edit.upload.ID, expectedRevisionID); err != nil {
+		var revisionConflict *services.UploadRevisionConflictError
+		if errors.As(err, &revisionConflict) {
+			_ = handlers.UploadRevisionConflict(c, revisionConflict)
+		} else {
+			_ = c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load revision"})
+		}
+		return edit, false
+	}
+	edit.expectedRevisionID = expectedRevisionID
+	return edit, true
+}
 
-	warnings, refreshErr := refreshCandidatesAndCollectDuplicateWarnings(upload, body.Data)
-	if refreshErr != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to refresh duplicate checks"})
+// commitUploadContentEdit schreibt den neuen Tabellenstand als eigene Datei und legt ihn
+// zusammen mit Statuswechsel und Kandidaten-Refresh atomar als neue Revision an.
+func commitUploadContentEdit(c *fiber.Ctx, edit uploadContentEdit, data [][]string, refresh func(tx *gorm.DB) ([]saveContentWarning, error)) error {
+	upload := edit.upload
+	role := edit.role
+	userEmail := edit.userEmail
+
+	// ✅ Neuen Stand als eigene Datei schreiben (shared lib); die bisherige Datei bleibt als Revision erhalten.
+	previous := upload
+	newPath := buildRevisionFilePath(upload)
+	if err := lib.WriteUploadTable(newPath, data); err != nil {
+		_ = os.Remove(newPath)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Failed to save file: " + err.Error()})
+	}
+	fileInfo, statErr := os.Stat(newPath)
+	if statErr != nil {
+		_ = os.Remove(newPath)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to read saved file"})
+	}
+	// Bearbeitete Inhalte durchlaufen dieselben Scanner wie neue Dateien (z. B. eingetippte Formeln);
+	// bei Befund oder Scannerausfall wird die Änderung verworfen.
+	if _, ok := scanIncomingFile(c, &upload, newPath); !ok {
+		return nil
 	}
 
 	// Upload-Metadaten aktualisieren
+	upload.FilePath = newPath
+	upload.FileSize = fileInfo.Size()
 	upload.LastModifiedBy = userEmail
 	upload.UpdatedAt = time.Now()
-	if role == "advertiser" {
-		// Advertiser-Bearbeitung geht zuerst in die Netzwerk-Verarbeitung.
-		// "Rückfrage" ist ein separater Publisher-Schritt und wird nur dort gesetzt.
-		upload.Status = "feedback"
-	}
-	if err := db.Save(&upload).Error; err != nil {
+	actor := handlers.WorkflowActorFromClaims(jwt.MapClaims(edit.claims))
+	var revision models.UploadRevision
+	var warnings []saveContentWarning
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		// Upload sperren und Status/Stand erneut prüfen, damit parallele Speichervorgänge nicht beide durchgehen.
+		if err := services.LockEditableUpload(tx, upload.ID); err != nil {
+			return err
+		}
+		if err := services.CheckUploadHeadRevision(tx, upload.ID, edit.expectedRevisionID); err != nil {
+			return err
+		}
+		if role == "advertiser" {
+			// Advertiser-Bearbeitung geht zuerst in die Netzwerk-Verarbeitung.
+			// "Rückfrage" ist ein separater Publisher-Schritt und wird nur dort gesetzt.
+			if err := services.TransitionUploadStatus(tx, &upload, models.UploadStatusFeedback, actor, "content_edited"); err != nil {
+				return err
+			}
+			if err := services.MarkUploadAccessProgress(tx, upload.ID, userEmail, models.UploadAccessStatusSubmitted); err != nil {
+				return err
+			}
+		}
+		var err error
+		if warnings, err = refresh(tx); err != nil {
+			return err
+		}
+		if err := services.EnsureBaselineUploadRevision(tx, previous); err != nil {
+			return err
+		}
+		if revision, err = services.RecordUploadRevision(tx, upload, actor, "content_edited", nil); err != nil {
+			return err
+		}
+		return tx.Save(&upload).Error
+	}); err != nil {
+		_ = os.Remove(newPath)
+		var revisionConflict *services.UploadRevisionConflictError
+		if errors.As(err, &revisionConflict) {
+			return handlers.UploadRevisionConflict(c, revisionConflict)
+		}
+		if errors.Is(err, services.ErrUploadContentLocked) {
+			return handlers.UploadContentLocked(c, err)
+		}
+		if errors.Is(err, services.ErrIllegalUploadTransition) {
+			return handlers.UploadTransitionConflict(c, err)
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update upload metadata"})
 	}
 
+	etag := services.UploadRevisionETag(revision.ID)
+	c.Set(fiber.HeaderETag, etag)
 	return c.JSON(fiber.Map{
-		"message":  "File saved successfully",
-		"warnings": warnings,
+		"message":    "File saved successfully",
+		"warnings":   warnings,
+		"revisionId": revision.ID,
+		"etag":       etag,
 	})
 }
 
@@ -1179,10 +2275,15 @@ type duplicateCandidateRow struct {
 	RowNo         int
 }
 
-func refreshCandidatesAndCollectDuplicateWarnings(upload models.Upload, data [][]string) ([]saveContentWarning, error) {
-	candidates := extractOrderTokenCandidatesFromTable(data)
+func refreshCandidatesAndCollectDuplicateWarnings(tx *gorm.DB, upload models.Upload, data [][]string) ([]saveContentWarning, error) {
+	headerCtx, err := services.LoadUploadHeaderContext(tx, upload, "")
+	if err != nil {
+		return nil, err
+	}
+	candidates := extractOrderTokenCandidatesFromTable(data, headerCtx)
 
-	if err := db.Where("upload_id = ?", upload.ID).Delete(&models.UploadOrderCandidate{}).Error; err != nil {
+	// Hart löschen: der eindeutige Index (upload_id, row_no) gilt auch für soft-gelöschte Zeilen.
+	if err := tx.Unscoped().Where("upload_id = ?", upload.ID).Delete(&models.UploadOrderCandidate{}).Error; err != nil {
 		return nil, err
 	}
 
@@ -1210,11 +2311,64 @@ func refreshCandidatesAndCollectDuplicateWarnings(upload models.Upload, data [][
 	}
 
 	if len(rowsToInsert) > 0 {
-		if err := db.Create(&rowsToInsert).Error; err != nil {
+		if err := tx.Create(&rowsToInsert).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return collectDuplicateOrderTokenWarnings(tx, upload, tokenSet)
+}
+
+// refreshChangedCandidatesAndCollectDuplicateWarnings aktualisiert nur die Kandidaten der Zeilen, die
+// der Patch geändert, eingefügt oder verschoben hat, und entfernt die hinter dem neuen Tabellenende.
+// Duplikat-Warnungen werden nur für diese Zeilen ermittelt.
+func refreshChangedCandidatesAndCollectDuplicateWarnings(tx *gorm.DB, upload models.Upload, headerCtx services.HeaderContext, patch lib.TablePatch) ([]saveContentWarning, error) {
+	// Hart löschen: der eindeutige Index (upload_id, row_no) gilt auch für soft-gelöschte Zeilen.
+	stale := tx.Unscoped().Where("upload_id = ? AND row_no > ?", upload.ID, patch.RowCount)
+	if len(patch.ChangedRows) > 0 {
+		stale = tx.Unscoped().Where("upload_id = ? AND (row_no IN ? OR row_no > ?)", upload.ID, patch.ChangedRows, patch.RowCount)
+	}
+	if err := stale.Delete(&models.UploadOrderCandidate{}).Error; err != nil {
+		return nil, err
+	}
+	if len(patch.ChangedRows) == 0 {
+		return []saveContentWarning{}, nil
+	}
+
+	mapping, rows := headerCtx.MapTableRows(patch.Data, patch.ChangedRows)
+	now := time.Now()
+	rowsToInsert := make([]models.UploadOrderCandidate, 0, len(rows))
+	tokenSet := make(map[string]struct{})
+	for _, rowNo := range patch.ChangedRows {
+		row, ok := rows[rowNo]
+		if !ok {
+			continue
+		}
+		candidate := orderTokenCandidate(mapping, row, rowNo)
+		token := strings.TrimSpace(candidate.OrderToken)
+		if token == "" {
+			continue
+		}
+		rowsToInsert = append(rowsToInsert, models.UploadOrderCandidate{
+			UploadID:           upload.ID,
+			RowNo:              rowNo,
+			CampaignExternalID: "",
+			OrderToken:         token,
+			LastValidatedAt:    &now,
+			RawRow:             map[string]any{"matched_column": candidate.MatchedColumn},
+		})
+		tokenSet[token] = struct{}{}
+	}
+	if len(rowsToInsert) > 0 {
+		if err := tx.Create(&rowsToInsert).Error; err != nil {
 			return nil, err
 		}
 	}
 
+	return collectDuplicateOrderTokenWarnings(tx, upload, tokenSet)
+}
+
+func collectDuplicateOrderTokenWarnings(tx *gorm.DB, upload models.Upload, tokenSet map[string]struct{}) ([]saveContentWarning, error) {
 	tokens := make([]string, 0, len(tokenSet))
 	for token := range tokenSet {
 		tokens = append(tokens, token)
@@ -1231,12 +2385,14 @@ func refreshCandidatesAndCollectDuplicateWarnings(upload models.Upload, data [][
 	}
 
 	var hits []duplicateHit
-	if err := db.Table("upload_order_candidates AS c").
+	query := tx.Table("upload_order_candidates AS c").
 		Select("c.order_token AS order_token, c.upload_id AS upload_id, u.filename AS filename, c.row_no AS row_no").
-		Joins("JOIN uploads AS u ON u.id = c.upload_id").
+		// Zusammengeführte Quellen (superseded) zählen nicht mehr als Doppel-Einreichung.
+		Joins("JOIN uploads AS u ON u.id = c.upload_id AND u.deleted_at IS NULL AND u.status <> ?", models.UploadStatusSuperseded).
 		Where("c.upload_id <> ? AND c.order_token IN ?", upload.ID, tokens).
-		Order("c.order_token ASC, u.filename ASC, c.row_no ASC").
-		Scan(&hits).Error; err != nil {
+		Order("c.order_token ASC, u.filename ASC, c.row_no ASC")
+	// Ausgangsdatei und Teil-Uploads eines Splits teilen ihre Ordertokens zwangsläufig.
+	if err := services.ExcludeSplitRelatives(query, "u", upload).Scan(&hits).Error; err != nil {
 		return nil, err
 	}
 
@@ -1274,74 +2430,40 @@ func refreshCandidatesAndCollectDuplicateWarnings(upload models.Upload, data [][
 	return warnings, nil
 }
 
-func extractOrderTokenCandidatesFromTable(data [][]string) []duplicateCandidateRow {
+// extractOrderTokenCandidatesFromTable liest die Ordertokens über Schema und Mapping-Profil des Uploads.
+func extractOrderTokenCandidatesFromTable(data [][]string, headerCtx services.HeaderContext) []duplicateCandidateRow {
 	if len(data) == 0 {
 		return nil
 	}
 
-	expected := []string{
-		"Ordertoken/OrderID",
-		"Ordertoken/Order ID",
-	}
-	headerIdx := lib.FindHeaderRow(data, expected)
-	rows := lib.TableToMaps(data, headerIdx)
+	mapping, rows := headerCtx.MapTable(data)
 	if len(rows) == 0 {
 		return nil
 	}
 
 	candidates := make([]duplicateCandidateRow, 0, len(rows))
 	for i, row := range rows {
-		matchedColumn := "Ordertoken/OrderID"
-		orderToken := strings.TrimSpace(row["Ordertoken/OrderID"])
-		if orderToken == "" {
-			if alt := strings.TrimSpace(row["Ordertoken/Order ID"]); alt != "" {
-				orderToken = alt
-				matchedColumn = "Ordertoken/Order ID"
-			}
-		}
-
-		if orderToken == "" {
-			for key, value := range row {
-				if strings.Contains(strings.ToLower(strings.TrimSpace(key)), "order") {
-					trimmed := strings.TrimSpace(value)
-					if trimmed != "" {
-						orderToken = trimmed
-						matchedColumn = strings.TrimSpace(key)
-						break
-					}
-				}
-			}
-		}
-
-		candidates = append(candidates, duplicateCandidateRow{
-			OrderToken:    orderToken,
-			MatchedColumn: matchedColumn,
-			RowNo:         i + 1,
-		})
+		candidates = append(candidates, orderTokenCandidate(mapping, row, i+1))
 	}
 	return candidates
 }
 
-func hasActiveUploadAccess(uploadID uint, advertiserID uint) (bool, error) {
-	var access models.UploadAccess
-	err := db.Where(
-		"upload_id = ? AND advertiser_id = ? AND (expires_at IS NULL OR expires_at > ?)",
-		uploadID,
-		advertiserID,
-		time.Now(),
-	).First(&access).Error
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return false, nil
+// orderTokenCandidate liest den Ordertoken einer gemappten Zeile; MatchedColumn ist der Original-Header.
+func orderTokenCandidate(mapping services.HeaderMapping, row map[string]string, rowNo int) duplicateCandidateRow {
+	orderToken, column := services.RowOrderToken(row)
+	matchedColumn := column
+	if source := mapping.SourceFor(column); source != "" {
+		matchedColumn = source
 	}
-	if err != nil {
-		return false, err
+	return duplicateCandidateRow{
+		OrderToken:    orderToken,
+		MatchedColumn: matchedColumn,
+		RowNo:         rowNo,
 	}
-	return true, nil
 }
 
-func isAdvertiserManualRequest(upload models.Upload) bool {
-	filename := strings.ToLower(strings.TrimSpace(upload.Filename))
-	return strings.HasPrefix(filename, "manual_request_advertiser_")
+func hasActiveUploadAccess(uploadID uint, advertiserID uint) (bool, error) {
+	return services.HasActiveUploadAccess(db, uploadID, advertiserID)
 }
 
 func validateSecurityConfig() {
@@ -1472,8 +2594,9 @@ func corsConfigFromEnv() cors.Config {
 	allowOrigins := strings.TrimSpace(os.Getenv("CORS_ALLOW_ORIGINS"))
 	base := cors.Config{
 		AllowOrigins:     allowOrigins,
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
-		AllowMethods:     "GET, POST, PUT, PATCH, DELETE, OPTIONS",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, If-Match, Upload-Offset, Upload-Length",
+		ExposeHeaders:    "ETag, Location, Upload-Offset, Upload-Length",
+		AllowMethods:     "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS",
 		AllowCredentials: false,
 		MaxAge:           300,
 	}
@@ -1534,6 +2657,13 @@ func buildStoredUploadPath(originalFilename string) string {
 	return filepath.Join("uploads", stored)
 }
 
+// buildRevisionFilePath erzeugt einen neuen Speicherpfad für einen bearbeiteten Stand;
+// die Dateiendung folgt der bisherigen Datei, damit CSV/XLSX gleich geschrieben werden.
+func buildRevisionFilePath(upload models.Upload) string {
+	name := strings.TrimSuffix(filepath.Base(upload.Filename), filepath.Ext(upload.Filename))
+	return buildStoredUploadPath(name + filepath.Ext(upload.FilePath))
+}
+
 func authRateLimitMiddleware() fiber.Handler {
 	return limiter.New(limiter.Config{
 		Max:        envIntWithDefault("AUTH_RATE_LIMIT_MAX", 30),
@@ -1553,23 +2683,29 @@ func validateUploadFile(file *multipart.FileHeader) error {
 	if file == nil {
 		return errors.New("no file uploaded")
 	}
+	return validateUploadSource(file.Filename, file.Size, uploadMaxBytes(), func() (io.ReadCloser, error) {
+		return file.Open()
+	})
+}
 
-	maxBytes := uploadMaxBytes()
-	if file.Size <= 0 {
+// validateUploadSource prüft Größe, Dateiendung und erkannten Inhaltstyp einer Datei,
+// unabhängig davon, ob sie per Multipart oder über eine Upload-Session kam.
+func validateUploadSource(filename string, size int64, maxBytes int64, open func() (io.ReadCloser, error)) error {
+	if size <= 0 {
 		return errors.New("uploaded file is empty")
 	}
-	if file.Size > maxBytes {
+	if size > maxBytes {
 		return fmt.Errorf("file too large (max %d bytes)", maxBytes)
 	}
 
-	filename := strings.TrimSpace(file.Filename)
+	filename = strings.TrimSpace(filename)
 	ext := strings.ToLower(filepath.Ext(filename))
 	allowedExtensions := allowedUploadExtensions()
 	if !slices.Contains(allowedExtensions, ext) {
 		return fmt.Errorf("file extension %q is not allowed", ext)
 	}
 
-	contentType, err := detectContentType(file)
+	contentType, err := detectContentType(open)
 	if err != nil {
 		return fmt.Errorf("failed to inspect file content: %w", err)
 	}
@@ -1581,8 +2717,8 @@ func validateUploadFile(file *multipart.FileHeader) error {
 	return nil
 }
 
-func detectContentType(file *multipart.FileHeader) (string, error) {
-	reader, err := file.Open()
+func detectContentType(open func() (io.ReadCloser, error)) (string, error) {
+	reader, err := open()
 	if err != nil {
 		return "", err
 	}
@@ -10,6 +10,7 @@ import (
 	"gorm.io/gorm"
 
 	"nba-dashboard/internal/models"
+	"nba-dashboard/internal/services"
 )
 
 // InitDB stellt die Verbindung zur Datenbank her.
@@ -56,8 +57,146 @@ func RunAutoMigrate(db *gorm.DB) error {
 		&models.OutboundJob{},
 		&models.AuditEvent{},
 		&models.UploadOrderCandidate{},
+		&models.UploadStatusTransition{},
+		&models.UploadRevision{},
+		&models.UploadComment{},
+		&models.UploadCommentMention{},
+		&models.UploadSLAPolicy{},
+		&models.UploadSLAEscalation{},
+		&models.UploadSession{},
+		&models.UploadMergedRow{},
+		&models.ClaimSchema{},
+		&models.HeaderMappingProfile{},
+		&models.ValidationJob{},
 	); err != nil {
 		return fmt.Errorf("failed to migrate tables: %w", err)
 	}
+	if err := backfillUploadKinds(db); err != nil {
+		return fmt.Errorf("failed to backfill upload kinds: %w", err)
+	}
+	if err := backfillUploadHashes(db); err != nil {
+		return fmt.Errorf("failed to backfill upload hashes: %w", err)
+	}
+	if err := migrateValidationRunHistory(db); err != nil {
+		return fmt.Errorf("failed to migrate validation run history: %w", err)
+	}
+	if err := migrateValidationJobActiveIndex(db); err != nil {
+		return fmt.Errorf("failed to migrate validation job index: %w", err)
+	}
+	return nil
+}
+
+// migrateValidationJobActiveIndex erlaubt je Upload nur einen eingereihten bzw. laufenden Validierungsjob.
+// Ältere aktive Doppel aus der Zeit vor dem Index werden vorher abgebrochen.
+func migrateValidationJobActiveIndex(db *gorm.DB) error {
+	active := []string{models.ValidationJobQueued, models.ValidationJobRunning}
+	result := db.Exec(`UPDATE validation_jobs SET status = ?, finished_at = NOW()
+		WHERE status IN ? AND id NOT IN (SELECT MAX(id) FROM validation_jobs WHERE status IN ? GROUP BY upload_id)`,
+		models.ValidationJobCancelled, active, active)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("✅ cancelled duplicate active validation jobs=%d", result.RowsAffected)
+	}
+	return db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_validation_jobs_active_upload
+		ON validation_jobs (upload_id) WHERE status IN ('queued', 'running')`).Error
+}
+
+// migrateValidationRunHistory entfernt den alten Unique-Index (ein Ergebnis je Upload), damit jeder
+// Validierungslauf als eigene Zeile erhalten bleibt, und zählt die Zeilen bestehender Ergebnisse nach.
+func migrateValidationRunHistory(db *gorm.DB) error {
+	if db.Migrator().HasIndex(&models.ValidationResult{}, "idx_validation_results_upload_id") {
+		if err := db.Migrator().DropIndex(&models.ValidationResult{}, "idx_validation_results_upload_id"); err != nil {
+			return err
+		}
+		log.Printf("✅ dropped unique index idx_validation_results_upload_id")
+	}
+	result := db.Exec(`UPDATE validation_results SET rows_count = jsonb_array_length(validated_rows)
+		WHERE rows_count = 0 AND jsonb_typeof(validated_rows) = 'array'`)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("✅ backfilled validation rows_count rows=%d", result.RowsAffected)
+	}
+	return nil
+}
+
+// backfillUploadKinds setzt Upload.Kind für Bestandsdaten, die vor Einführung der Spalte angelegt wurden.
+// Bis dahin wurde die Herkunft nur am Dateinamen bzw. an der Rolle des Uploaders erkannt.
+func backfillUploadKinds(db *gorm.DB) error {
+	steps := []struct {
+		kind  string
+		where string
+		args  []any
+	}{
+		{models.UploadKindAdvertiserManualRequest, "LOWER(filename) LIKE ?", []any{`manual\_request\_advertiser\_%`}},
+		{models.UploadKindPublisherManualRequest, "LOWER(filename) LIKE ?", []any{`manual\_request\_%`}},
+		{models.UploadKindAdminUpload, "uploaded_by IN (SELECT email FROM users WHERE role = ?)", []any{"admin"}},
+		{models.UploadKindPublisherFile, "1 = 1", nil},
+	}
+	for _, step := range steps {
+		result := db.Unscoped().Model(&models.Upload{}).
+			Where("kind = ''").
+			Where(step.where, step.args...).
+			Update("kind", step.kind)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("✅ backfilled upload kind=%s rows=%d", step.kind, result.RowsAffected)
+		}
+	}
+	return nil
+}
+
+// uploadHashBatchSize begrenzt, wie viele Uploads backfillUploadHashes je Block von der Platte hasht.
+const uploadHashBatchSize = 200
+
+// backfillUploadHashes übernimmt den Hash der jeweils neuesten Revision für Uploads ohne sha256 und
+// hasht die übrigen (z. B. Uploads ohne Revision) blockweise von der Platte. Fehlende Dateien bleiben leer.
+func backfillUploadHashes(db *gorm.DB) error {
+	result := db.Exec(`UPDATE uploads SET sha256 = r.sha256
+		FROM upload_revisions AS r
+		WHERE uploads.sha256 = '' AND r.upload_id = uploads.id
+		AND r.revision_no = (SELECT MAX(r2.revision_no) FROM upload_revisions AS r2 WHERE r2.upload_id = uploads.id)`)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("✅ backfilled upload sha256 from revisions rows=%d", result.RowsAffected)
+	}
+
+	hashed, skipped := 0, 0
+	var lastID uint
+	for {
+		var uploads []models.Upload
+		if err := db.Unscoped().Select("id", "file_path").
+			Where("id > ? AND sha256 = ''", lastID).
+			Order("id asc").
+			Limit(uploadHashBatchSize).
+			Find(&uploads).Error; err != nil {
+			return err
+		}
+		for _, upload := range uploads {
+			sum, err := services.UploadFileSHA256(upload.FilePath)
+			if err != nil {
+				skipped++
+				continue
+			}
+			if err := db.Unscoped().Model(&models.Upload{}).Where("id = ?", upload.ID).UpdateColumn("sha256", sum).Error; err != nil {
+				return err
+			}
+			hashed++
+		}
+		if len(uploads) < uploadHashBatchSize {
+			break
+		}
+		lastID = uploads[len(uploads)-1].ID
+	}
+	if hashed > 0 || skipped > 0 {
+		log.Printf("✅ backfilled upload sha256 from files rows=%d skipped=%d", hashed, skipped)
+	}
 	return nil
 }
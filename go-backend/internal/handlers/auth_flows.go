@@ -390,6 +390,7 @@ func HandleAuthMe(db *gorm.DB) fiber.Handler {
 		}
 
 		return c.JSON(fiber.Map{
+			"id":                    user.ID,
 			"email":                 user.Email,
 			"role":                  user.Role,
 			"must_complete_profile": user.MustCompleteProfile,
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"nba-dashboard/internal/models"
+	"nba-dashboard/internal/services"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
@@ -311,17 +312,7 @@ func HandleDownloadBookingCSVExport(db *gorm.DB) fiber.Handler {
 }
 
 func createAuditEvent(tx *gorm.DB, actorUserID *uint, action string, entityType string, entityID uint, requestID string, before map[string]any, after map[string]any, metadata map[string]any) error {
-	event := models.AuditEvent{
-		ActorUserID: actorUserID,
-		Action:      action,
-		EntityType:  entityType,
-		EntityID:    strconv.FormatUint(uint64(entityID), 10),
-		RequestID:   requestID,
-		BeforeData:  before,
-		AfterData:   after,
-		Metadata:    metadata,
-	}
-	return tx.Create(&event).Error
+	return services.CreateAuditEvent(tx, actorUserID, action, entityType, entityID, requestID, before, after, metadata)
 }
 
 func bookingDedupe(campaignID string, rec map[string]string) string {
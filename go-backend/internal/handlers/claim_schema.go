@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"nba-dashboard/internal/models"
+	"nba-dashboard/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+type claimSchemaRequest struct {
+	Name         string                     `json:"name"`
+	AdvertiserID uint                       `json:"advertiserId"`
+	CampaignID   string                     `json:"campaignId"`
+	Columns      []models.ClaimColumn       `json:"columns"`
+	Checks       *models.CustomerDataChecks `json:"checks"`
+}
+
+// HandleListClaimSchemas listet alle Spaltenschemas samt eingebautem Standard (nur Admin).
+func HandleListClaimSchemas(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		if role, _ := claims["role"].(string); role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can view claim schemas"})
+		}
+
+		schemas, err := services.ListClaimSchemas(db)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch claim schemas"})
+		}
+		return c.JSON(fiber.Map{
+			"schemas":        schemas,
+			"defaultColumns": services.DefaultClaimColumns,
+			"defaultChecks":  services.DefaultCustomerDataChecks,
+		})
+	}
+}
+
+// HandleResolveClaimSchema zeigt, welches Schema für ?advertiserId=, ?campaignId= bzw. ?uploadId= greift (nur Admin).
+func HandleResolveClaimSchema(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		if role, _ := claims["role"].(string); role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can view claim schemas"})
+		}
+		campaignID := strings.TrimSpace(c.Query("campaignId"))
+
+		if raw := strings.TrimSpace(c.Query("uploadId")); raw != "" {
+			uploadID, err := strconv.ParseUint(raw, 10, 64)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid uploadId"})
+			}
+			var upload models.Upload
+			if err := db.First(&upload, uploadID).Error; err != nil {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Upload not found"})
+			}
+			resolved, err := services.ResolveUploadClaimSchema(db, upload, campaignID)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to resolve claim schema"})
+			}
+			return c.JSON(resolved)
+		}
+
+		var advertiserIDs []uint
+		if raw := strings.TrimSpace(c.Query("advertiserId")); raw != "" {
+			parsed, err := strconv.ParseUint(raw, 10, 64)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid advertiserId"})
+			}
+			advertiserIDs = append(advertiserIDs, uint(parsed))
+		}
+		resolved, err := services.ResolveClaimSchema(db, advertiserIDs, campaignID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to resolve claim schema"})
+		}
+		return c.JSON(resolved)
+	}
+}
+
+// HandleUpsertClaimSchema legt das Schema für Advertiser und/oder Kampagne an oder ersetzt es.
+// advertiserId 0 und leere campaignId setzen den globalen Standard.
+func HandleUpsertClaimSchema(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		if role, _ := claims["role"].(string); role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can change claim schemas"})
+		}
+
+		var body claimSchemaRequest
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		actor := WorkflowActorFromClaims(claims)
+
+		schema, err := services.UpsertClaimSchema(db, models.ClaimSchema{
+			Name:               body.Name,
+			AdvertiserID:       body.AdvertiserID,
+			CampaignExternalID: body.CampaignID,
+			Columns:            body.Columns,
+			Checks:             body.Checks,
+			UpdatedBy:          actor.Email,
+		})
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidClaimSchema) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "columns need unique non-empty names and a valid type (enum with allowedValues); advertiserId must be an advertiser and campaignId a known campaign"})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save claim schema"})
+		}
+		return c.JSON(schema)
+	}
+}
+
+// HandleDeleteClaimSchema entfernt ein Schema (nur Admin); danach gilt wieder das allgemeinere.
+func HandleDeleteClaimSchema(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		if role, _ := claims["role"].(string); role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can change claim schemas"})
+		}
+		schemaID, err := strconv.ParseUint(c.Params("schemaId"), 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid schema id"})
+		}
+
+		if err := services.DeleteClaimSchema(db, uint(schemaID)); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Claim schema not found"})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete claim schema"})
+		}
+		return c.JSON(fiber.Map{"message": "Claim schema deleted"})
+	}
+}
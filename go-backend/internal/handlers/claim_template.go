@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"nba-dashboard/internal/models"
+	"nba-dashboard/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+var (
+	templateNameNormalizer = regexp.MustCompile(`[^a-z0-9]+`)
+	templateFileLabelChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+)
+
+// HandleGetClaimTemplate liefert die Nachbuchungsvorlage eines Advertisers als .xlsx, .csv oder .json (Schema).
+// :advertiser ist die Advertiser-ID oder Name/Firma, jeweils mit Dateiendung (z. B. /api/templates/12.xlsx);
+// optional ?campaignId= für ein kampagnenspezifisches Schema.
+func HandleGetClaimTemplate(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		param := strings.TrimSpace(c.Params("advertiser"))
+		format := strings.ToLower(strings.TrimPrefix(filepath.Ext(param), "."))
+		key := strings.TrimSuffix(param, filepath.Ext(param))
+		if format != "xlsx" && format != "csv" && format != "json" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "format must be .xlsx, .csv or .json"})
+		}
+
+		advertiser, ok := resolveTemplateAdvertiser(db, key)
+		if !ok {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Advertiser not found"})
+		}
+		schema, err := services.ResolveClaimSchema(db, []uint{advertiser.ID}, c.Query("campaignId"))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to resolve claim schema"})
+		}
+		columns := schema.Columns
+		label := advertiser.Company
+		if strings.TrimSpace(label) == "" {
+			label = advertiser.Name
+		}
+
+		filename := "Nachbuchung_" + templateFileLabel(label)
+		switch format {
+		case "json":
+			return c.JSON(fiber.Map{"advertiserId": advertiser.ID, "advertiser": label, "schemaId": schema.SchemaID, "schemaName": schema.Name, "columns": columns})
+		case "csv":
+			data, err := services.BuildClaimTemplateCSV(columns)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to build template"})
+			}
+			c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+			c.Attachment(filename + ".csv")
+			return c.Send(data)
+		default:
+			data, err := services.BuildClaimTemplateXLSX(columns, "Nachbuchungsvorlage für "+label)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to build template"})
+			}
+			c.Attachment(filename + ".xlsx")
+			c.Set(fiber.HeaderContentType, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+			return c.Send(data)
+		}
+	}
+}
+
+// resolveTemplateAdvertiser sucht den Advertiser per ID oder über Name/Firma (ohne Sonderzeichen, case-insensitive).
+func resolveTemplateAdvertiser(db *gorm.DB, key string) (models.User, bool) {
+	var advertiser models.User
+	if id, err := strconv.ParseUint(key, 10, 64); err == nil {
+		err := db.Where("id = ? AND role = ?", id, "advertiser").First(&advertiser).Error
+		return advertiser, err == nil
+	}
+	needle := templateNameNormalizer.ReplaceAllString(strings.ToLower(key), "")
+	if needle == "" {
+		return advertiser, false
+	}
+	var advertisers []models.User
+	if err := db.Where("role = ?", "advertiser").Find(&advertisers).Error; err != nil {
+		return advertiser, false
+	}
+	for _, item := range advertisers {
+		for _, candidate := range []string{item.Company, item.Name} {
+			if templateNameNormalizer.ReplaceAllString(strings.ToLower(candidate), "") == needle {
+				return item, true
+			}
+		}
+	}
+	return advertiser, false
+}
+
+func templateFileLabel(label string) string {
+	cleaned := strings.Trim(templateFileLabelChars.ReplaceAllString(label, "-"), "-")
+	if cleaned == "" {
+		return "Advertiser"
+	}
+	return cleaned
+}
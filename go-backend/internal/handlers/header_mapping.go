@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"nba-dashboard/internal/lib"
+	"nba-dashboard/internal/models"
+	"nba-dashboard/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+type headerMappingProfileRequest struct {
+	Name         string            `json:"name"`
+	AdvertiserID uint              `json:"advertiserId"`
+	PublisherID  uint              `json:"publisherId"`
+	Mappings     map[string]string `json:"mappings"`
+}
+
+// HandleListHeaderMappingProfiles listet alle Header-Mapping-Profile (nur Admin).
+func HandleListHeaderMappingProfiles(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		if role, _ := claims["role"].(string); role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can view header mappings"})
+		}
+
+		profiles, err := services.ListHeaderMappingProfiles(db)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch header mappings"})
+		}
+		return c.JSON(fiber.Map{"profiles": profiles})
+	}
+}
+
+// HandleUpsertHeaderMappingProfile legt das Profil für Advertiser und/oder Publisher an oder ersetzt es.
+// mappings ordnet Quell-Header (Vergleich ohne Groß-/Kleinschreibung und Sonderzeichen) einer Schema-Spalte zu.
+func HandleUpsertHeaderMappingProfile(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		if role, _ := claims["role"].(string); role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can change header mappings"})
+		}
+
+		var body headerMappingProfileRequest
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		actor := WorkflowActorFromClaims(claims)
+
+		profile, err := services.UpsertHeaderMappingProfile(db, models.HeaderMappingProfile{
+			Name:         body.Name,
+			AdvertiserID: body.AdvertiserID,
+			PublisherID:  body.PublisherID,
+			Mappings:     body.Mappings,
+			UpdatedBy:    actor.Email,
+		})
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidHeaderMapping) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "mappings must map 1-200 non-empty source headers to column names; advertiserId must be an advertiser and publisherId a publisher"})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save header mapping"})
+		}
+		return c.JSON(profile)
+	}
+}
+
+// HandleDeleteHeaderMappingProfile entfernt ein Header-Mapping-Profil (nur Admin).
+func HandleDeleteHeaderMappingProfile(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		if role, _ := claims["role"].(string); role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can change header mappings"})
+		}
+		profileID, err := strconv.ParseUint(c.Params("profileId"), 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid profile id"})
+		}
+
+		if err := services.DeleteHeaderMappingProfile(db, uint(profileID)); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Header mapping not found"})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete header mapping"})
+		}
+		return c.JSON(fiber.Map{"message": "Header mapping deleted"})
+	}
+}
+
+// HandlePreviewUploadHeaders zeigt vor der Validierung, wie die Header eines Uploads auf das Schema abgebildet
+// werden: Zuordnung je Spalte (profile/exact/normalized/alias/none), Vorschläge und fehlende Pflichtspalten.
+// Optional ?campaignId= für ein kampagnenspezifisches Schema (nur Admin).
+func HandlePreviewUploadHeaders(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		if role, _ := claims["role"].(string); role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can preview header mappings"})
+		}
+
+		var upload models.Upload
+		if err := db.First(&upload, c.Params("id")).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Upload not found"})
+		}
+		if services.IsUploadQuarantined(upload) {
+			return UploadQuarantined(c, upload)
+		}
+
+		raw, err := lib.ReadUploadAsTable(upload.FilePath)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		headerCtx, err := services.LoadUploadHeaderContext(db, upload, strings.TrimSpace(c.Query("campaignId")))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to resolve header mapping"})
+		}
+		mapping, rows := headerCtx.MapTable(raw)
+
+		return c.JSON(fiber.Map{
+			"uploadId":   upload.ID,
+			"schema":     headerCtx.Schema,
+			"profileIds": headerCtx.ProfileIDs,
+			"mapping":    mapping,
+			"rowCount":   len(rows),
+		})
+	}
+}
@@ -1,8 +1,9 @@
 package handlers
 
 import (
+	"errors"
 	"nba-dashboard/internal/models"
-	"strings"
+	"nba-dashboard/internal/services"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
@@ -27,39 +28,42 @@ func HandleReturnToPublisher(db *gorm.DB) fiber.Handler {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Upload nicht gefunden"})
 		}
 
-		// Zusätzliche Prüfung: Nur wenn Status zu einer Advertiser-Bearbeitung passt
-		if upload.Status != "assigned" && upload.Status != "feedback" && upload.Status != "feedback_submitted" && upload.Status != "feedback_submitted_advertiser" && upload.Status != "sent_to_publisher_advertiser" {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Datei kann nur im passenden Workflow-Status zurückgeschickt werden"})
-		}
 		var user models.User
 		if err := db.Where("email = ?", upload.LastModifiedBy).First(&user).Error; err != nil {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Letzter Bearbeiter nicht gefunden"})
 		}
 
-		isAdvertiserManual := strings.HasPrefix(strings.ToLower(strings.TrimSpace(upload.Filename)), "manual_request_advertiser_")
-		if isAdvertiserManual {
+		nextStatus := ""
+		if services.IsAdvertiserManualRequest(upload) {
 			// Sonderfall:
 			// 1) Advertiser -> Admin -> Publisher
 			if user.Role == "advertiser" {
-				upload.Status = "sent_to_publisher_advertiser"
+				nextStatus = models.UploadStatusSentToPublisherAdvertiser
 			} else if user.Role == "publisher" {
 				// 2) Publisher -> Admin -> Advertiser
-				upload.Status = "returned_to_publisher"
+				nextStatus = models.UploadStatusReturnedToPublisher
 			} else {
 				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Ungültiger Bearbeiter für Advertiser-Manuellanfrage"})
 			}
 		} else {
 			switch {
 			case user.Role == "advertiser":
-				upload.Status = "returned_to_publisher"
-			case user.Role == "publisher" && upload.Status == "feedback_submitted":
+				nextStatus = models.UploadStatusReturnedToPublisher
+			case user.Role == "publisher" && upload.Status == models.UploadStatusFeedbackSubmitted:
 				// Admin leitet eine Publisher-Rückfrage an den Publisher zurück.
-				upload.Status = "returned_to_publisher"
+				nextStatus = models.UploadStatusReturnedToPublisher
 			default:
 				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Datei kann nur zurückgeschickt werden, wenn sie vom Advertiser bearbeitet wurde oder eine Publisher-Rückfrage vorliegt"})
 			}
 		}
-		if err := db.Save(&upload).Error; err != nil {
+
+		actor := WorkflowActorFromClaims(claims)
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			return services.TransitionUploadStatus(tx, &upload, nextStatus, actor, "return_to_publisher")
+		}); err != nil {
+			if errors.Is(err, services.ErrIllegalUploadTransition) {
+				return UploadTransitionConflict(c, err)
+			}
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Status konnte nicht aktualisiert werden"})
 		}
 
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"nba-dashboard/internal/models"
+	"nba-dashboard/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// HandleListUploadAccess listet alle Advertiser-Freigaben eines Uploads inkl. Bearbeitungsstand.
+func HandleListUploadAccess(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		role, _ := claims["role"].(string)
+		if role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can view access grants"})
+		}
+
+		var upload models.Upload
+		if err := db.First(&upload, c.Params("id")).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Upload not found"})
+		}
+
+		var accesses []models.UploadAccess
+		if err := db.Where("upload_id = ?", upload.ID).Order("created_at asc, id asc").Find(&accesses).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch access grants"})
+		}
+
+		advertiserIDs := make([]uint, 0, len(accesses))
+		for _, a := range accesses {
+			advertiserIDs = append(advertiserIDs, a.AdvertiserID)
+		}
+		advertisers := map[uint]models.User{}
+		if len(advertiserIDs) > 0 {
+			var users []models.User
+			if err := db.Where("id IN ?", advertiserIDs).Find(&users).Error; err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch advertisers"})
+			}
+			for _, u := range users {
+				advertisers[u.ID] = u
+			}
+		}
+
+		now := time.Now()
+		grants := make([]fiber.Map, 0, len(accesses))
+		for _, a := range accesses {
+			advertiser := advertisers[a.AdvertiserID]
+			grants = append(grants, fiber.Map{
+				"advertiserId":   a.AdvertiserID,
+				"email":          advertiser.Email,
+				"name":           advertiser.Name,
+				"company":        advertiser.Company,
+				"expiresAt":      a.ExpiresAt,
+				"active":         a.ExpiresAt == nil || a.ExpiresAt.After(now),
+				"status":         a.Status,
+				"lastActivityAt": a.LastActivityAt,
+				"grantedAt":      a.CreatedAt,
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"uploadId": upload.ID,
+			"grants":   grants,
+		})
+	}
+}
+
+// HandleRevokeUploadAccess entzieht genau einem Advertiser die Freigabe.
+// War das die letzte Freigabe eines zugewiesenen Uploads, fällt er auf "pending" zurück.
+func HandleRevokeUploadAccess(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		role, _ := claims["role"].(string)
+		if role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can revoke access"})
+		}
+
+		advertiserID, err := strconv.ParseUint(c.Params("advertiserId"), 10, 64)
+		if err != nil || advertiserID == 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid advertiser id"})
+		}
+
+		var upload models.Upload
+		if err := db.First(&upload, c.Params("id")).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Upload not found"})
+		}
+
+		requestID := strings.TrimSpace(c.GetRespHeader(fiber.HeaderXRequestID))
+		actor := WorkflowActorFromClaims(claims)
+		errAccessNotFound := errors.New("access grant not found")
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			var access models.UploadAccess
+			if err := tx.Where("upload_id = ? AND advertiser_id = ?", upload.ID, uint(advertiserID)).First(&access).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errAccessNotFound
+				}
+				return err
+			}
+			if err := tx.Delete(&access).Error; err != nil {
+				return err
+			}
+			if err := createAuditEvent(tx, actor.UserID, "UPLOAD_ACCESS_REVOKED", "upload", upload.ID, requestID, map[string]any{
+				"advertiser_id": access.AdvertiserID,
+				"expires_at":    access.ExpiresAt,
+				"status":        access.Status,
+			}, nil, nil); err != nil {
+				return err
+			}
+
+			var remaining int64
+			if err := tx.Model(&models.UploadAccess{}).Where("upload_id = ?", upload.ID).Count(&remaining).Error; err != nil {
+				return err
+			}
+			if remaining == 0 && upload.Status == models.UploadStatusAssigned {
+				return services.TransitionUploadStatus(tx, &upload, models.UploadStatusPending, actor, "access_revoked")
+			}
+			return nil
+		}); err != nil {
+			if errors.Is(err, errAccessNotFound) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Access grant not found"})
+			}
+			if errors.Is(err, services.ErrIllegalUploadTransition) {
+				return UploadTransitionConflict(c, err)
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to revoke access"})
+		}
+
+		return c.JSON(fiber.Map{
+			"message": "Access revoked successfully",
+			"status":  upload.Status,
+		})
+	}
+}
+
+// HandleListExpiringUploadAccess listet Freigaben, die in den nächsten N Tagen ablaufen.
+// Verlängert wird über POST /api/uploads/:id/access mit neuem expiresAt.
+func HandleListExpiringUploadAccess(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		role, _ := claims["role"].(string)
+		if role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can view expiring access grants"})
+		}
+
+		withinDays := 7
+		if raw := strings.TrimSpace(c.Query("withinDays")); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed <= 0 || parsed > 365 {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "withinDays must be between 1 and 365"})
+			}
+			withinDays = parsed
+		}
+
+		now := time.Now()
+		items, err := services.ListUpcomingUploadAccessExpiries(db, now, now.AddDate(0, 0, withinDays))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch expiring access grants"})
+		}
+
+		return c.JSON(fiber.Map{
+			"withinDays": withinDays,
+			"items":      items,
+			"processor":  services.GetUploadAccessExpiryMetrics(),
+		})
+	}
+}
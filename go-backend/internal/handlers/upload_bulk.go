@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"nba-dashboard/internal/models"
+	"nba-dashboard/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	bulkModeAtomic     = "atomic"
+	bulkModeBestEffort = "best_effort"
+	maxBulkUploadIDs   = 200
+)
+
+var (
+	errBulkRolledBack = errors.New("rolled back because another item failed")
+	errBulkSkipped    = errors.New("not processed because another item failed")
+)
+
+type bulkUploadOutcome struct {
+	ID     uint   `json:"id"`
+	OK     bool   `json:"ok"`
+	Status string `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Code   int    `json:"code,omitempty"`
+}
+
+// HandleBulkUploads führt eine Aktion (assign, approve, reject, complete, delete) für mehrere Uploads aus.
+// mode=atomic: alles oder nichts in einer Transaktion; mode=best_effort: jedes Element einzeln.
+// Pro Upload gelten dieselben Rechte- und Workflow-Prüfungen wie bei den Einzel-Endpunkten.
+func HandleBulkUploads(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := LoadCurrentUser(c, db)
+		if !ok {
+			return nil
+		}
+		claims, _ := c.Locals("user").(jwt.MapClaims)
+		actor := WorkflowActorFromClaims(claims)
+
+		var body struct {
+			Action      string                            `json:"action"`
+			IDs         []uint                            `json:"ids"`
+			Mode        string                            `json:"mode"`
+			Reason      string                            `json:"reason"`
+			Assignments []services.UploadAccessAssignment `json:"assignments"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+
+		action := strings.ToLower(strings.TrimSpace(body.Action))
+		switch action {
+		case services.UploadActionAssign, services.UploadActionApprove, services.UploadActionReject,
+			services.UploadActionComplete, services.UploadActionDelete:
+		default:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid action"})
+		}
+		mode := strings.ToLower(strings.TrimSpace(body.Mode))
+		if mode == "" {
+			mode = bulkModeBestEffort
+		}
+		if mode != bulkModeAtomic && mode != bulkModeBestEffort {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "mode must be atomic or best_effort"})
+		}
+		ids := uniqueUploadIDs(body.IDs)
+		if len(ids) == 0 || len(ids) > maxBulkUploadIDs {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ids must contain between 1 and 200 upload ids"})
+		}
+		if action == services.UploadActionAssign {
+			if err := services.ValidateUploadAccessAssignments(db, body.Assignments); err != nil {
+				if errors.Is(err, services.ErrInvalidAdvertiserSelection) {
+					return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid advertiser selection"})
+				}
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to validate assignments"})
+			}
+		}
+		reason := strings.TrimSpace(body.Reason)
+		if reason == "" {
+			reason = "bulk_" + action
+		}
+
+		apply := func(tx *gorm.DB, id uint) (models.Upload, error) {
+			var upload models.Upload
+			if err := tx.First(&upload, id).Error; err != nil {
+				return upload, err
+			}
+			if err := services.CheckUploadActionPermission(tx, user, upload, action); err != nil {
+				return upload, err
+			}
+			switch action {
+			case services.UploadActionAssign:
+				return upload, services.AssignUploadAccess(tx, &upload, body.Assignments, actor)
+			case services.UploadActionApprove:
+				return upload, services.UpdateUploadStatus(tx, &upload, models.UploadStatusApproved, actor, reason)
+			case services.UploadActionReject:
+				return upload, services.UpdateUploadStatus(tx, &upload, models.UploadStatusRejected, actor, reason)
+			case services.UploadActionComplete:
+				return upload, services.UpdateUploadStatus(tx, &upload, models.UploadStatusCompleted, actor, reason)
+			default:
+				return upload, services.TrashUpload(tx, upload, actor)
+			}
+		}
+
+		outcomes := make([]bulkUploadOutcome, len(ids))
+		committed := true
+
+		if mode == bulkModeAtomic {
+			failed := -1
+			err := db.Transaction(func(tx *gorm.DB) error {
+				for i, id := range ids {
+					upload, err := apply(tx, id)
+					outcomes[i] = bulkOutcome(id, upload, action, err)
+					if err != nil {
+						failed = i
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				committed = false
+				for i := range outcomes {
+					switch {
+					case failed < 0:
+						outcomes[i] = bulkOutcome(ids[i], models.Upload{}, action, err)
+					case i < failed:
+						outcomes[i] = bulkOutcome(ids[i], models.Upload{}, action, errBulkRolledBack)
+					case i > failed:
+						outcomes[i] = bulkOutcome(ids[i], models.Upload{}, action, errBulkSkipped)
+					}
+				}
+			}
+		} else {
+			for i, id := range ids {
+				var upload models.Upload
+				err := db.Transaction(func(tx *gorm.DB) error {
+					var err error
+					upload, err = apply(tx, id)
+					return err
+				})
+				outcomes[i] = bulkOutcome(id, upload, action, err)
+			}
+		}
+
+		succeeded := 0
+		for _, outcome := range outcomes {
+			if outcome.OK {
+				succeeded++
+			}
+		}
+		return c.JSON(fiber.Map{
+			"action":    action,
+			"mode":      mode,
+			"committed": committed,
+			"succeeded": succeeded,
+			"failed":    len(outcomes) - succeeded,
+			"results":   outcomes,
+		})
+	}
+}
+
+func bulkOutcome(id uint, upload models.Upload, action string, err error) bulkUploadOutcome {
+	if err == nil {
+		outcome := bulkUploadOutcome{ID: id, OK: true, Status: upload.Status}
+		if action == services.UploadActionDelete {
+			outcome.Status = "deleted"
+		}
+		return outcome
+	}
+
+	outcome := bulkUploadOutcome{ID: id, Error: err.Error(), Code: fiber.StatusInternalServerError}
+	var transitionErr *services.UploadTransitionError
+	switch {
+	case errors.Is(err, errBulkRolledBack), errors.Is(err, errBulkSkipped):
+		outcome.Code = fiber.StatusFailedDependency
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		outcome.Error = "Upload not found"
+		outcome.Code = fiber.StatusNotFound
+	case errors.Is(err, services.ErrUploadActionForbidden):
+		outcome.Code = fiber.StatusForbidden
+	case errors.As(err, &transitionErr):
+		outcome.Code = fiber.StatusConflict
+	default:
+		outcome.Error = "Failed to process upload"
+	}
+	return outcome
+}
+
+func uniqueUploadIDs(ids []uint) []uint {
+	seen := make(map[uint]struct{}, len(ids))
+	out := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id == 0 {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out
+}
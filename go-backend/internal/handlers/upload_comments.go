@@ -0,0 +1,366 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"nba-dashboard/internal/models"
+	"nba-dashboard/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+const maxUploadCommentLength = 5000
+
+type uploadCommentView struct {
+	models.UploadComment
+	Deleted  bool                `json:"deleted"`
+	Mentions []string            `json:"mentions"`
+	Replies  []uploadCommentView `json:"replies,omitempty"`
+}
+
+// HandleListUploadComments liefert alle Threads eines Uploads, optional gefiltert nach Zeile/Ordertoken.
+func HandleListUploadComments(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		_, upload, ok := loadCommentContext(c, db)
+		if !ok {
+			return nil
+		}
+
+		query := db.Unscoped().Where("upload_id = ?", upload.ID)
+		if token := strings.TrimSpace(c.Query("orderToken")); token != "" {
+			query = query.Where("order_token = ?", token)
+		}
+		if raw := strings.TrimSpace(c.Query("row")); raw != "" {
+			rowNo, err := strconv.Atoi(raw)
+			if err != nil || rowNo < 1 {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "row must be a positive number"})
+			}
+			query = query.Where("row_no = ?", rowNo)
+		}
+
+		var roots []models.UploadComment
+		if err := query.Where("parent_id IS NULL").Order("created_at asc, id asc").Find(&roots).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch comments"})
+		}
+		rootIDs := make([]uint, 0, len(roots))
+		for _, root := range roots {
+			rootIDs = append(rootIDs, root.ID)
+		}
+		var replies []models.UploadComment
+		if len(rootIDs) > 0 {
+			if err := db.Where("parent_id IN ?", rootIDs).Order("created_at asc, id asc").Find(&replies).Error; err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch comments"})
+			}
+		}
+
+		commentIDs := append([]uint{}, rootIDs...)
+		for _, reply := range replies {
+			commentIDs = append(commentIDs, reply.ID)
+		}
+		mentions, err := loadCommentMentions(db, commentIDs)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch mentions"})
+		}
+
+		repliesByRoot := map[uint][]uploadCommentView{}
+		for _, reply := range replies {
+			repliesByRoot[*reply.ParentID] = append(repliesByRoot[*reply.ParentID], newCommentView(reply, mentions))
+		}
+		threads := make([]uploadCommentView, 0, len(roots))
+		for _, root := range roots {
+			view := newCommentView(root, mentions)
+			view.Replies = repliesByRoot[root.ID]
+			// Gelöschte Thread-Anfänge bleiben nur als Platzhalter stehen, solange es Antworten gibt.
+			if view.Deleted && len(view.Replies) == 0 {
+				continue
+			}
+			threads = append(threads, view)
+		}
+
+		return c.JSON(fiber.Map{
+			"uploadId": upload.ID,
+			"threads":  threads,
+		})
+	}
+}
+
+// HandleCreateUploadComment legt einen neuen Thread oder eine Antwort (parentId) an.
+func HandleCreateUploadComment(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		author, upload, ok := loadCommentContext(c, db)
+		if !ok {
+			return nil
+		}
+
+		var body struct {
+			Body       string `json:"body"`
+			ParentID   *uint  `json:"parentId"`
+			RowNo      int    `json:"rowNo"`
+			OrderToken string `json:"orderToken"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		text := strings.TrimSpace(body.Body)
+		if text == "" || len(text) > maxUploadCommentLength {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "body must be between 1 and 5000 characters"})
+		}
+		if body.RowNo < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "rowNo must not be negative"})
+		}
+
+		comment := models.UploadComment{
+			UploadID:     upload.ID,
+			RowNo:        body.RowNo,
+			OrderToken:   strings.TrimSpace(body.OrderToken),
+			AuthorUserID: author.ID,
+			AuthorEmail:  author.Email,
+			AuthorRole:   author.Role,
+			Body:         text,
+		}
+		if body.ParentID != nil {
+			var parent models.UploadComment
+			if err := db.Where("id = ? AND upload_id = ?", *body.ParentID, upload.ID).First(&parent).Error; err != nil {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Parent comment not found"})
+			}
+			// Antworten hängen immer am Thread-Anfang und übernehmen dessen Verankerung.
+			rootID := parent.ID
+			if parent.ParentID != nil {
+				rootID = *parent.ParentID
+			}
+			comment.ParentID = &rootID
+			comment.RowNo = parent.RowNo
+			comment.OrderToken = parent.OrderToken
+		}
+
+		mentioned, invalid, err := services.ResolveUploadCommentMentions(db, upload, services.ParseCommentMentions(text))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to resolve mentions"})
+		}
+		if len(invalid) > 0 {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": "Mentioned users cannot see this upload", "mentions": invalid})
+		}
+
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&comment).Error; err != nil {
+				return err
+			}
+			return services.ReplaceUploadCommentMentions(tx, comment.ID, mentioned)
+		}); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save comment"})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(uploadCommentView{UploadComment: comment, Mentions: userEmails(mentioned)})
+	}
+}
+
+// HandleUpdateUploadComment ändert den Text eines eigenen Kommentars.
+func HandleUpdateUploadComment(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		author, upload, ok := loadCommentContext(c, db)
+		if !ok {
+			return nil
+		}
+
+		var comment models.UploadComment
+		if err := db.Where("id = ? AND upload_id = ?", c.Params("commentId"), upload.ID).First(&comment).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Comment not found"})
+		}
+		if comment.AuthorUserID != author.ID {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only the author can edit a comment"})
+		}
+
+		var body struct {
+			Body string `json:"body"`
+		}
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		text := strings.TrimSpace(body.Body)
+		if text == "" || len(text) > maxUploadCommentLength {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "body must be between 1 and 5000 characters"})
+		}
+
+		mentioned, invalid, err := services.ResolveUploadCommentMentions(db, upload, services.ParseCommentMentions(text))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to resolve mentions"})
+		}
+		if len(invalid) > 0 {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": "Mentioned users cannot see this upload", "mentions": invalid})
+		}
+
+		now := time.Now()
+		comment.Body = text
+		comment.EditedAt = &now
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Save(&comment).Error; err != nil {
+				return err
+			}
+			return services.ReplaceUploadCommentMentions(tx, comment.ID, mentioned)
+		}); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update comment"})
+		}
+
+		return c.JSON(uploadCommentView{UploadComment: comment, Mentions: userEmails(mentioned)})
+	}
+}
+
+// HandleDeleteUploadComment löscht einen Kommentar (Autor oder Admin).
+func HandleDeleteUploadComment(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		author, upload, ok := loadCommentContext(c, db)
+		if !ok {
+			return nil
+		}
+
+		var comment models.UploadComment
+		if err := db.Where("id = ? AND upload_id = ?", c.Params("commentId"), upload.ID).First(&comment).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Comment not found"})
+		}
+		if comment.AuthorUserID != author.ID && author.Role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only the author or an admin can delete a comment"})
+		}
+
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("comment_id = ?", comment.ID).Delete(&models.UploadCommentMention{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&comment).Error
+		}); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete comment"})
+		}
+		return c.JSON(fiber.Map{"message": "Comment deleted successfully"})
+	}
+}
+
+// HandleListMyCommentMentions listet Kommentare, in denen der aktuelle User erwähnt wurde
+// (nur für Uploads, die er weiterhin sehen darf).
+func HandleListMyCommentMentions(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := LoadCurrentUser(c, db)
+		if !ok {
+			return nil
+		}
+
+		var comments []models.UploadComment
+		if err := db.Joins("JOIN upload_comment_mentions AS m ON m.comment_id = upload_comments.id").
+			Where("m.user_id = ?", user.ID).
+			Order("upload_comments.created_at desc").
+			Limit(100).
+			Find(&comments).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch mentions"})
+		}
+
+		uploads := map[uint]*models.Upload{}
+		items := make([]fiber.Map, 0, len(comments))
+		for _, comment := range comments {
+			upload, seen := uploads[comment.UploadID]
+			if !seen {
+				var loaded models.Upload
+				if err := db.First(&loaded, comment.UploadID).Error; err == nil {
+					if canRead, err := services.CanUserReadUpload(db, user, loaded); err == nil && canRead {
+						upload = &loaded
+					}
+				}
+				uploads[comment.UploadID] = upload
+			}
+			if upload == nil {
+				continue
+			}
+			items = append(items, fiber.Map{
+				"comment":  comment,
+				"filename": upload.Filename,
+			})
+		}
+		return c.JSON(fiber.Map{"items": items})
+	}
+}
+
+// loadCommentContext lädt User und Upload und prüft die Sichtbarkeit.
+// Bei ok=false wurde die Fehlerantwort bereits geschrieben.
+func loadCommentContext(c *fiber.Ctx, db *gorm.DB) (models.User, models.Upload, bool) {
+	var upload models.Upload
+	user, ok := LoadCurrentUser(c, db)
+	if !ok {
+		return user, upload, false
+	}
+	if err := db.First(&upload, c.Params("id")).Error; err != nil {
+		_ = c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Upload not found"})
+		return user, upload, false
+	}
+	canRead, err := services.CanUserReadUpload(db, user, upload)
+	if err != nil || !canRead {
+		_ = c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed"})
+		return user, upload, false
+	}
+	return user, upload, true
+}
+
+// LoadCurrentUser lädt den angemeldeten User aus der DB; bei ok=false wurde die Fehlerantwort bereits geschrieben.
+func LoadCurrentUser(c *fiber.Ctx, db *gorm.DB) (models.User, bool) {
+	var user models.User
+	claims, ok := c.Locals("user").(jwt.MapClaims)
+	if !ok {
+		_ = c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		return user, false
+	}
+	email, _ := claims["email"].(string)
+	if err := db.Where("email = ?", email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			_ = c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "User not found"})
+		} else {
+			_ = c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load user"})
+		}
+		return user, false
+	}
+	return user, true
+}
+
+func loadCommentMentions(db *gorm.DB, commentIDs []uint) (map[uint][]string, error) {
+	out := map[uint][]string{}
+	if len(commentIDs) == 0 {
+		return out, nil
+	}
+	var rows []struct {
+		CommentID uint
+		Email     string
+	}
+	if err := db.Table("upload_comment_mentions AS m").
+		Select("m.comment_id AS comment_id, u.email AS email").
+		Joins("JOIN users AS u ON u.id = m.user_id").
+		Where("m.comment_id IN ?", commentIDs).
+		Order("m.id asc").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		out[row.CommentID] = append(out[row.CommentID], row.Email)
+	}
+	return out, nil
+}
+
+func newCommentView(comment models.UploadComment, mentions map[uint][]string) uploadCommentView {
+	view := uploadCommentView{UploadComment: comment, Mentions: mentions[comment.ID]}
+	if view.Mentions == nil {
+		view.Mentions = []string{}
+	}
+	if comment.DeletedAt.Valid {
+		view.Deleted = true
+		view.Body = ""
+		view.Mentions = []string{}
+	}
+	return view
+}
+
+func userEmails(users []models.User) []string {
+	out := make([]string, 0, len(users))
+	for _, user := range users {
+		out = append(out, user.Email)
+	}
+	return out
+}
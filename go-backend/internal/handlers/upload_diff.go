@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"nba-dashboard/internal/lib"
+	"nba-dashboard/internal/models"
+	"nba-dashboard/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// HandleGetUploadDiff vergleicht zwei Revisionen eines Uploads zellgenau.
+// Query: from/to = Revisions-IDs. Ohne "to" wird der aktuelle Stand verwendet,
+// ohne "from" die Revision direkt davor.
+func HandleGetUploadDiff(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := LoadCurrentUser(c, db)
+		if !ok {
+			return nil
+		}
+
+		var upload models.Upload
+		if err := db.First(&upload, c.Params("id")).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Upload not found"})
+		}
+		if canRead, err := services.CanUserReadUpload(db, user, upload); err != nil || !canRead {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed"})
+		}
+		if services.IsUploadQuarantined(upload) {
+			return UploadQuarantined(c, upload)
+		}
+
+		fromID, err := parseOptionalRevisionID(c.Query("from"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid from revision"})
+		}
+		toID, err := parseOptionalRevisionID(c.Query("to"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid to revision"})
+		}
+
+		var to models.UploadRevision
+		toQuery := db.Where("upload_id = ?", upload.ID)
+		if toID > 0 {
+			toQuery = toQuery.Where("id = ?", toID)
+		}
+		if err := toQuery.Order("revision_no desc").First(&to).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Target revision not found"})
+		}
+
+		var from models.UploadRevision
+		fromQuery := db.Where("upload_id = ?", upload.ID)
+		if fromID > 0 {
+			fromQuery = fromQuery.Where("id = ?", fromID)
+		} else {
+			fromQuery = fromQuery.Where("revision_no < ?", to.RevisionNo)
+		}
+		if err := fromQuery.Order("revision_no desc").First(&from).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Base revision not found"})
+		}
+
+		before, err := lib.ReadUploadAsTable(from.FilePath)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Failed to read base revision: " + err.Error()})
+		}
+		after, err := lib.ReadUploadAsTable(to.FilePath)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Failed to read target revision: " + err.Error()})
+		}
+
+		headerCtx, err := services.LoadUploadHeaderContext(db, upload, "")
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to resolve header mapping"})
+		}
+
+		return c.JSON(fiber.Map{
+			"uploadId": upload.ID,
+			"from":     from,
+			"to":       to,
+			"diff":     lib.DiffTables(before, after, headerCtx.TableKey()),
+		})
+	}
+}
+
+func parseOptionalRevisionID(raw string) (uint64, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(raw, 10, 64)
+}
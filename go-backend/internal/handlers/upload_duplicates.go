@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"nba-dashboard/internal/models"
+	"nba-dashboard/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// HandleGetUploadDuplicates liefert den Near-Duplicate-Report eines Uploads: andere Uploads mit der
+// größten Ordertoken-Überschneidung. Admins sehen alle Uploads, andere Rollen nur Uploads desselben Uploaders.
+func HandleGetUploadDuplicates(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := LoadCurrentUser(c, db)
+		if !ok {
+			return nil
+		}
+
+		var upload models.Upload
+		if err := db.First(&upload, c.Params("id")).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Upload not found"})
+		}
+		if canRead, err := services.CanUserReadUpload(db, user, upload); err != nil || !canRead {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed"})
+		}
+
+		limit := 10
+		if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed <= 0 || parsed > 100 {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "limit must be between 1 and 100"})
+			}
+			limit = parsed
+		}
+
+		scope := upload.UploadedBy
+		if user.Role == "admin" {
+			scope = ""
+		}
+		report, err := services.FindNearDuplicateUploads(db, upload, scope, limit)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to build duplicate report"})
+		}
+		return c.JSON(report)
+	}
+}
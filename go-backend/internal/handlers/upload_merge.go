@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"nba-dashboard/internal/models"
+	"nba-dashboard/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// HandleListMergedRows liefert für einen zusammengeführten Upload je Zeile den Quell-Upload und die Quellzeile.
+func HandleListMergedRows(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := LoadCurrentUser(c, db)
+		if !ok {
+			return nil
+		}
+
+		var upload models.Upload
+		if err := db.First(&upload, c.Params("id")).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Upload not found"})
+		}
+		if canRead, err := services.CanUserReadUpload(db, user, upload); err != nil || !canRead {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed"})
+		}
+
+		rows, err := services.ListMergedRows(db, upload.ID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load merged rows"})
+		}
+		return c.JSON(fiber.Map{"uploadId": upload.ID, "rows": rows})
+	}
+}
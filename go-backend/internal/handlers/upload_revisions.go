@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"nba-dashboard/internal/models"
+	"nba-dashboard/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// HandleListUploadRevisions listet alle gespeicherten Dateistände eines Uploads.
+func HandleListUploadRevisions(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := LoadCurrentUser(c, db)
+		if !ok {
+			return nil
+		}
+
+		var upload models.Upload
+		if err := db.First(&upload, c.Params("id")).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Upload not found"})
+		}
+		if canRead, err := services.CanUserReadUpload(db, user, upload); err != nil || !canRead {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed"})
+		}
+
+		revisions, err := services.ListUploadRevisions(db, upload.ID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch revisions"})
+		}
+
+		var headRevisionID *uint
+		if len(revisions) > 0 {
+			headRevisionID = &revisions[0].ID
+		}
+		return c.JSON(fiber.Map{
+			"uploadId":       upload.ID,
+			"headRevisionId": headRevisionID,
+			"revisions":      revisions,
+		})
+	}
+}
+
+// HandleDownloadUploadRevision liefert die Datei einer beliebigen Revision.
+func HandleDownloadUploadRevision(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := LoadCurrentUser(c, db)
+		if !ok {
+			return nil
+		}
+
+		var upload models.Upload
+		if err := db.First(&upload, c.Params("id")).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Upload not found"})
+		}
+		if canRead, err := services.CanUserReadUpload(db, user, upload); err != nil || !canRead {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Not allowed to download this file"})
+		}
+		if services.IsUploadQuarantined(upload) {
+			return UploadQuarantined(c, upload)
+		}
+
+		var revision models.UploadRevision
+		if err := db.Where("id = ? AND upload_id = ?", c.Params("revisionId"), upload.ID).First(&revision).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Revision not found"})
+		}
+		if _, err := os.Stat(revision.FilePath); err != nil {
+			return c.Status(fiber.StatusGone).JSON(fiber.Map{"error": "Revision file is no longer available"})
+		}
+
+		return c.Download(revision.FilePath, revision.Filename)
+	}
+}
+
+// HandleRestoreUploadRevision macht einen älteren Stand wieder zum aktuellen.
+// Dabei entsteht eine neue Revision, die Historie bleibt vollständig erhalten.
+func HandleRestoreUploadRevision(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		role, _ := claims["role"].(string)
+		userEmail, _ := claims["email"].(string)
+
+		var upload models.Upload
+		if err := db.First(&upload, c.Params("id")).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Upload not found"})
+		}
+		if role != "admin" && upload.UploadedBy != userEmail {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin or uploader can restore revisions"})
+		}
+		if err := services.EnsureUploadContentEditable(upload); err != nil {
+			return UploadContentLocked(c, err)
+		}
+
+		var revision models.UploadRevision
+		if err := db.Where("id = ? AND upload_id = ?", c.Params("revisionId"), upload.ID).First(&revision).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Revision not found"})
+		}
+		if _, err := os.Stat(revision.FilePath); err != nil {
+			return c.Status(fiber.StatusGone).JSON(fiber.Map{"error": "Revision file is no longer available"})
+		}
+
+		previous := upload
+		// Revisionsdateien werden nie überschrieben, daher kann der neue Kopf auf dieselbe Datei zeigen.
+		upload.FilePath = revision.FilePath
+		upload.Filename = revision.Filename
+		upload.ContentType = revision.ContentType
+		upload.FileSize = revision.FileSize
+		upload.LastModifiedBy = userEmail
+
+		actor := WorkflowActorFromClaims(claims)
+		var restored models.UploadRevision
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := services.LockEditableUpload(tx, upload.ID); err != nil {
+				return err
+			}
+			if err := services.EnsureBaselineUploadRevision(tx, previous); err != nil {
+				return err
+			}
+			var err error
+			restored, err = services.RecordUploadRevision(tx, upload, actor, "restored", &revision.ID)
+			if err != nil {
+				return err
+			}
+			if err := tx.Save(&upload).Error; err != nil {
+				return err
+			}
+			return services.CreateAuditEvent(tx, actor.UserID, "UPLOAD_REVISION_RESTORED", "upload", upload.ID, strings.TrimSpace(c.Get("X-Request-ID")),
+				map[string]any{"filename": previous.Filename, "file_size": previous.FileSize},
+				map[string]any{"filename": upload.Filename, "file_size": upload.FileSize},
+				map[string]any{"restored_from_revision_id": revision.ID, "revision_no": revision.RevisionNo},
+			)
+		}); err != nil {
+			if errors.Is(err, services.ErrUploadContentLocked) {
+				return UploadContentLocked(c, err)
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to restore revision"})
+		}
+
+		return c.JSON(fiber.Map{
+			"message":  "Revision restored successfully",
+			"revision": restored,
+		})
+	}
+}
+
+// UploadContentLocked beantwortet neue Dateistände für gesperrte Uploads (freigegeben, abgelehnt,
+// abgeschlossen, übernommen) mit 409 und dem aktuellen Status.
+func UploadContentLocked(c *fiber.Ctx, err error) error {
+	body := fiber.Map{"error": err.Error()}
+	var locked *services.UploadContentLockedError
+	if errors.As(err, &locked) {
+		body["status"] = locked.Status
+	}
+	return c.Status(fiber.StatusConflict).JSON(body)
+}
+
+// UploadRevisionConflict beantwortet veraltete If-Match-Stände mit 409 und dem aktuellen Stand,
+// damit das Frontend neu laden bzw. zusammenführen kann.
+func UploadRevisionConflict(c *fiber.Ctx, err *services.UploadRevisionConflictError) error {
+	etag := services.UploadRevisionETag(err.CurrentRevisionID)
+	c.Set(fiber.HeaderETag, etag)
+	return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+		"error":             "Upload was modified by someone else",
+		"currentRevisionId": err.CurrentRevisionID,
+		"etag":              etag,
+	})
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"nba-dashboard/internal/models"
+	"nba-dashboard/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// UploadQuarantined beantwortet Zugriffe auf Dateien in Quarantäne einheitlich mit 423.
+func UploadQuarantined(c *fiber.Ctx, upload models.Upload) error {
+	return c.Status(fiber.StatusLocked).JSON(fiber.Map{
+		"error":        "File is quarantined until the content scan passes",
+		"scanStatus":   upload.ScanStatus,
+		"scanFindings": upload.ScanFindings,
+	})
+}
+
+// HandleRescanUpload prüft eine Datei in Quarantäne erneut, z. B. nach einem Scannerausfall (nur Admin).
+func HandleRescanUpload(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		if role, _ := claims["role"].(string); role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can rescan uploads"})
+		}
+
+		var upload models.Upload
+		if err := db.First(&upload, c.Params("id")).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Upload not found"})
+		}
+		if !services.IsUploadQuarantined(upload) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Upload is not quarantined"})
+		}
+
+		if err := services.RescanUpload(db, &upload, WorkflowActorFromClaims(claims)); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to rescan upload"})
+		}
+		return c.JSON(fiber.Map{
+			"uploadId":     upload.ID,
+			"scanStatus":   upload.ScanStatus,
+			"scanFindings": upload.ScanFindings,
+			"scannedAt":    upload.ScannedAt,
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"nba-dashboard/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+type uploadSLAPolicyRequest struct {
+	Status        string `json:"status"`
+	AdvertiserID  uint   `json:"advertiserId"`
+	DurationHours int    `json:"durationHours"`
+	DurationDays  int    `json:"durationDays"`
+}
+
+// HandleListUploadSLAPolicies listet alle SLA-Regeln (nur Admin).
+func HandleListUploadSLAPolicies(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		if role, _ := claims["role"].(string); role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can view SLA policies"})
+		}
+
+		policies, err := services.ListUploadSLAPolicies(db)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch SLA policies"})
+		}
+		return c.JSON(fiber.Map{
+			"policies": policies,
+			"monitor":  services.GetUploadSLAMonitorMetrics(),
+		})
+	}
+}
+
+// HandleUpsertUploadSLAPolicy legt eine SLA-Regel für Status (+ optional Advertiser) an oder ändert sie.
+// Die Fristen offener Uploads in diesem Status werden sofort neu berechnet.
+func HandleUpsertUploadSLAPolicy(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		if role, _ := claims["role"].(string); role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can change SLA policies"})
+		}
+
+		var body uploadSLAPolicyRequest
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		hours := body.DurationHours
+		if hours == 0 && body.DurationDays > 0 {
+			hours = body.DurationDays * 24
+		}
+		status := strings.TrimSpace(body.Status)
+
+		policy, err := services.UpsertUploadSLAPolicy(db, status, body.AdvertiserID, hours)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidUploadSLAPolicy) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "status must be an open upload status, advertiserId an advertiser and duration between 1 hour and 365 days"})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save SLA policy"})
+		}
+		if err := services.RefreshUploadSLADueDates(db, status); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to recompute due dates"})
+		}
+		return c.JSON(policy)
+	}
+}
+
+// HandleDeleteUploadSLAPolicy entfernt eine SLA-Regel (nur Admin).
+func HandleDeleteUploadSLAPolicy(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		if role, _ := claims["role"].(string); role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can change SLA policies"})
+		}
+		policyID, err := strconv.ParseUint(c.Params("policyId"), 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid policy id"})
+		}
+
+		policy, err := services.DeleteUploadSLAPolicy(db, uint(policyID))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "SLA policy not found"})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete SLA policy"})
+		}
+		if err := services.RefreshUploadSLADueDates(db, policy.Status); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to recompute due dates"})
+		}
+		return c.JSON(fiber.Map{"message": "SLA policy deleted"})
+	}
+}
+
+// HandleListOverdueUploads listet offene Uploads mit überschrittener Frist (nur Admin, optional ?advertiserId=).
+func HandleListOverdueUploads(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		if role, _ := claims["role"].(string); role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can view overdue uploads"})
+		}
+
+		var advertiserID uint
+		if raw := strings.TrimSpace(c.Query("advertiserId")); raw != "" {
+			parsed, err := strconv.ParseUint(raw, 10, 64)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid advertiserId"})
+			}
+			advertiserID = uint(parsed)
+		}
+
+		items, err := services.ListOverdueUploads(db, time.Now(), advertiserID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch overdue uploads"})
+		}
+		return c.JSON(fiber.Map{
+			"items":   items,
+			"monitor": services.GetUploadSLAMonitorMetrics(),
+		})
+	}
+}
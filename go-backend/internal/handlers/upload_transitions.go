@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"nba-dashboard/internal/models"
+	"nba-dashboard/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// HandleGetUploadTransitions liefert die Statushistorie eines Uploads (wer hat wann was geändert).
+func HandleGetUploadTransitions(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		role, _ := claims["role"].(string)
+		if role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can view status history"})
+		}
+
+		var upload models.Upload
+		if err := db.Unscoped().First(&upload, c.Params("id")).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Upload not found"})
+		}
+
+		var transitions []models.UploadStatusTransition
+		if err := db.Where("upload_id = ?", upload.ID).Order("occurred_at asc, id asc").Find(&transitions).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch status history"})
+		}
+
+		return c.JSON(fiber.Map{
+			"uploadId":    upload.ID,
+			"status":      upload.Status,
+			"transitions": transitions,
+		})
+	}
+}
+
+// WorkflowActorFromClaims baut den Workflow-Akteur aus den JWT-Claims.
+func WorkflowActorFromClaims(claims jwt.MapClaims) services.WorkflowActor {
+	email, _ := claims["email"].(string)
+	role, _ := claims["role"].(string)
+	actor := services.WorkflowActor{
+		Email: strings.TrimSpace(email),
+		Role:  strings.ToLower(strings.TrimSpace(role)),
+	}
+	if sub, ok := claims["sub"].(float64); ok && sub > 0 {
+		userID := uint(sub)
+		actor.UserID = &userID
+	}
+	return actor
+}
+
+// UploadTransitionConflict beantwortet abgelehnte Statuswechsel einheitlich mit 409.
+func UploadTransitionConflict(c *fiber.Ctx, err error) error {
+	body := fiber.Map{"error": err.Error()}
+	var transitionErr *services.UploadTransitionError
+	if errors.As(err, &transitionErr) {
+		body["from"] = transitionErr.From
+		body["to"] = transitionErr.To
+	}
+	return c.Status(fiber.StatusConflict).JSON(body)
+}
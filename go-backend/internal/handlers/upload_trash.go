@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"nba-dashboard/internal/models"
+	"nba-dashboard/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+type trashedUploadItem struct {
+	models.Upload
+	DeletedAt time.Time `json:"deleted_at"`
+	DeletedBy string    `json:"deleted_by"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// HandleListTrashedUploads listet gelöschte Uploads im Papierkorb inkl. geplantem Purge-Zeitpunkt (nur Admin).
+func HandleListTrashedUploads(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		if role, _ := claims["role"].(string); role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can view the trash"})
+		}
+
+		uploads, err := services.ListTrashedUploads(db)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch trash"})
+		}
+
+		retention := services.UploadTrashRetention()
+		items := make([]trashedUploadItem, 0, len(uploads))
+		for _, upload := range uploads {
+			items = append(items, trashedUploadItem{
+				Upload:    upload,
+				DeletedAt: upload.DeletedAt.Time,
+				DeletedBy: upload.DeletedBy,
+				PurgeAt:   upload.DeletedAt.Time.Add(retention),
+			})
+		}
+
+		return c.JSON(fiber.Map{
+			"items":  items,
+			"purger": services.GetUploadTrashPurgeMetrics(),
+		})
+	}
+}
+
+// HandleRestoreTrashedUpload holt einen Upload aus dem Papierkorb zurück (nur Admin).
+func HandleRestoreTrashedUpload(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		if role, _ := claims["role"].(string); role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can restore uploads"})
+		}
+		uploadID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid upload id"})
+		}
+
+		var upload models.Upload
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			upload, err = services.RestoreUpload(tx, uint(uploadID), WorkflowActorFromClaims(claims))
+			return err
+		}); err != nil {
+			return trashError(c, err, "Failed to restore upload")
+		}
+
+		return c.JSON(fiber.Map{"message": "Upload restored", "upload": upload})
+	}
+}
+
+// HandlePurgeTrashedUpload löscht einen Upload aus dem Papierkorb sofort endgültig (nur Admin).
+func HandlePurgeTrashedUpload(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		if role, _ := claims["role"].(string); role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can purge uploads"})
+		}
+		uploadID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid upload id"})
+		}
+
+		var paths []string
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			paths, err = services.PurgeUpload(tx, uint(uploadID), WorkflowActorFromClaims(claims))
+			return err
+		}); err != nil {
+			return trashError(c, err, "Failed to purge upload")
+		}
+		services.RemoveUploadFiles(paths)
+
+		return c.JSON(fiber.Map{"message": "Upload purged"})
+	}
+}
+
+func trashError(c *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "File not found"})
+	case errors.Is(err, services.ErrUploadNotInTrash):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Upload is not in trash"})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fallback})
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
@@ -20,8 +21,6 @@ import (
 )
 
 func HandleValidateUpload(db *gorm.DB) fiber.Handler {
-	validationSvc := services.NewValidationService()
-
 	return func(c *fiber.Ctx) error {
 		claims, ok := c.Locals("user").(jwt.MapClaims)
 		if !ok {
@@ -40,167 +39,243 @@ func HandleValidateUpload(db *gorm.DB) fiber.Handler {
 		if err := db.First(&upload, id).Error; err != nil {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Upload not found"})
 		}
+		if services.IsUploadQuarantined(upload) {
+			return UploadQuarantined(c, upload)
+		}
 
-		raw, err := lib.ReadUploadAsTable(upload.FilePath)
+		forceRefresh := strings.TrimSpace(c.Query("forceRefresh"))
+		params := models.ValidationParams{
+			CampaignID:        strings.TrimSpace(c.Query("campaignId")),
+			ProjectID:         normalizeUintQuery(c.Query("projectId")),
+			PublisherID:       normalizeUintQuery(c.Query("publisherId")),
+			CommissionGroupID: normalizeUintQuery(c.Query("commissionGroupId")),
+			TriggerID:         normalizeUintQuery(c.Query("triggerId")),
+			ForceRefresh:      strings.EqualFold(forceRefresh, "true") || forceRefresh == "1",
+		}
+
+		actor := WorkflowActorFromClaims(claims)
+		result, headerMapping, err := runUploadValidation(c.Context(), db, upload, params, validationRunOrigin{ValidatedBy: actor.Email}, nil)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+			var runErr *services.ValidationRunError
+			if errors.As(err, &runErr) {
+				body := fiber.Map{"error": runErr.Message}
+				if runErr.Detail != "" {
+					body["detail"] = runErr.Detail
+				}
+				return c.Status(runErr.Status).JSON(body)
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Validation failed", "detail": err.Error()})
 		}
+		return c.JSON(fiber.Map{
+			"uploadId":      upload.ID,
+			"runId":         result.ID,
+			"ordersCount":   result.OrdersCount,
+			"rows":          result.ValidatedRows,
+			"headerMapping": headerMapping,
+		})
+	}
+}
+
+// validationRunOrigin hält fest, wer bzw. welcher Job einen Validierungslauf ausgelöst hat.
+type validationRunOrigin struct {
+	ValidatedBy string
+	JobID       *uint
+}
+
+// RunValidationJob führt einen eingereihten Validierungsjob aus (Runner für services.StartValidationJobWorker).
+func RunValidationJob(ctx context.Context, db *gorm.DB, job models.ValidationJob, progress *services.ValidationJobProgress) (models.ValidationResult, error) {
+	var upload models.Upload
+	if err := db.WithContext(ctx).First(&upload, job.UploadID).Error; err != nil {
+		return models.ValidationResult{}, &services.ValidationRunError{Status: fiber.StatusNotFound, Message: "Upload not found"}
+	}
+	if services.IsUploadQuarantined(upload) {
+		return models.ValidationResult{}, &services.ValidationRunError{Status: fiber.StatusLocked, Message: "Upload is quarantined", Detail: upload.ScanStatus}
+	}
+	jobID := job.ID
+	result, _, err := runUploadValidation(ctx, db, upload, job.Params, validationRunOrigin{ValidatedBy: job.RequestedBy, JobID: &jobID}, progress)
+	return result, err
+}
 
-		headerIdx := lib.FindHeaderRow(raw, services.Pflichtfelder)
-		rows := lib.TableToMaps(raw, headerIdx)
+// runUploadValidation liest den Upload, gleicht ihn (optional) mit den Kampagnen-Orders ab und speichert das Ergebnis
+// als neuen Lauf (frühere Läufe bleiben erhalten).
+// Phasen: loading -> syncing (Kandidaten, Kampagnen-Sync, Live-API) -> matching -> saving. Ein abgebrochener
+// ctx beendet den Lauf vor dem Speichern.
+func runUploadValidation(ctx context.Context, db *gorm.DB, upload models.Upload, params models.ValidationParams, origin validationRunOrigin, progress *services.ValidationJobProgress) (models.ValidationResult, services.HeaderMapping, error) {
+	var result models.ValidationResult
+	var headerMapping services.HeaderMapping
+	progress.SetPhase(models.ValidationPhaseLoading)
 
-		campaignId := strings.TrimSpace(c.Query("campaignId"))
-		fromDate := "2024-01-01"
-		toDate := "2027-05-05"
-		if dynamicFrom, dynamicTo, ok := deriveDateRangeFromRows(rows); ok {
-			fromDate = dynamicFrom
-			toDate = dynamicTo
+	raw, err := lib.ReadUploadAsTable(upload.FilePath)
+	if err != nil {
+		return result, headerMapping, &services.ValidationRunError{Status: fiber.StatusBadRequest, Message: err.Error()}
+	}
+
+	campaignId := strings.TrimSpace(params.CampaignID)
+	headerCtx, err := services.LoadUploadHeaderContext(db, upload, campaignId)
+	if err != nil {
+		return result, headerMapping, &services.ValidationRunError{Status: fiber.StatusInternalServerError, Message: "Failed to resolve claim schema"}
+	}
+	// Header über Schema + Mapping-Profil auf die Schema-Spalten abbilden.
+	headerMapping, rows := headerCtx.MapTable(raw)
+	progress.SetRows(0, len(rows))
+
+	fromDate := "2024-01-01"
+	toDate := "2027-05-05"
+	if dynamicFrom, dynamicTo, ok := deriveDateRangeFromRows(rows); ok {
+		fromDate = dynamicFrom
+		toDate = dynamicTo
+	}
+
+	var orders []services.ExternalOrder
+	// tokenPool: weitere Kampagnen-Orders nur für den normalisierten/unscharfen Ordertoken-Abgleich
+	var tokenPool []services.ExternalOrder
+	useDBCache := isDBValidationCacheEnabled()
+	forceRefresh := params.ForceRefresh
+	if forceRefresh {
+		useDBCache = false
+	}
+
+	if campaignId != "" {
+		progress.SetPhase(models.ValidationPhaseSyncing)
+	}
+
+	if campaignId != "" && useDBCache {
+		if err := upsertUploadOrderCandidates(db, upload.ID, campaignId, rows); err != nil {
+			log.Printf("⚠️ UploadOrderCandidates konnten nicht persistiert werden: %v", err)
 		}
 
-		var orders []services.ExternalOrder
-		useDBCache := isDBValidationCacheEnabled()
-		forceRefresh := strings.EqualFold(strings.TrimSpace(c.Query("forceRefresh")), "true") || strings.TrimSpace(c.Query("forceRefresh")) == "1"
-		if forceRefresh {
-			useDBCache = false
+		campaign, err := ensureCampaignByExternalID(db, campaignId)
+		if err != nil {
+			return result, headerMapping, &services.ValidationRunError{Status: fiber.StatusInternalServerError, Message: "Failed to resolve campaign", Detail: err.Error()}
 		}
 
-		if campaignId != "" && useDBCache {
-			if err := upsertUploadOrderCandidates(db, upload.ID, campaignId, rows); err != nil {
-				log.Printf("⚠️ UploadOrderCandidates konnten nicht persistiert werden: %v", err)
+		shouldSync := forceRefresh
+		if campaign.LastSyncedAt == nil {
+			shouldSync = true
+		} else {
+			syncInterval := time.Duration(campaign.SyncIntervalMins) * time.Minute
+			if syncInterval <= 0 {
+				syncInterval = 30 * time.Minute
 			}
-
-			campaign, err := ensureCampaignByExternalID(db, campaignId)
-			if err != nil {
-				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error":  "Failed to resolve campaign",
-					"detail": err.Error(),
-				})
+			if time.Since(*campaign.LastSyncedAt) > syncInterval {
+				shouldSync = true
 			}
+		}
 
-			shouldSync := forceRefresh
-			if campaign.LastSyncedAt == nil {
+		if !shouldSync {
+			var existingCount int64
+			if err := db.Model(&models.CampaignOrder{}).Where("campaign_id = ?", campaign.ID).Count(&existingCount).Error; err != nil {
+				log.Printf("⚠️ campaign_orders count failed: %v", err)
+			}
+			if existingCount == 0 {
 				shouldSync = true
-			} else {
-				syncInterval := time.Duration(campaign.SyncIntervalMins) * time.Minute
-				if syncInterval <= 0 {
-					syncInterval = 30 * time.Minute
-				}
-				if time.Since(*campaign.LastSyncedAt) > syncInterval {
-					shouldSync = true
-				}
 			}
+		}
 
-			if !shouldSync {
-				var existingCount int64
-				if err := db.Model(&models.CampaignOrder{}).Where("campaign_id = ?", campaign.ID).Count(&existingCount).Error; err != nil {
-					log.Printf("⚠️ campaign_orders count failed: %v", err)
-				}
-				if existingCount == 0 {
-					shouldSync = true
-				}
+		if shouldSync {
+			syncSvc := services.NewCampaignSyncService()
+			_, _, syncErr := syncSvc.SyncCampaign(ctx, db, &campaign, fromDate, toDate)
+			if syncErr != nil {
+				log.Printf("⚠️ Campaign-Sync fehlgeschlagen, fallback auf Live-API: %v", syncErr)
 			}
+		}
+		if err := ctx.Err(); err != nil {
+			return result, headerMapping, err
+		}
 
-			if shouldSync {
-				syncSvc := services.NewCampaignSyncService()
-				_, _, syncErr := syncSvc.SyncCampaign(c.Context(), db, &campaign, fromDate, toDate)
-				if syncErr != nil {
-					log.Printf("⚠️ Campaign-Sync fehlgeschlagen, fallback auf Live-API: %v", syncErr)
-				}
-			}
+		orders, err = loadOrdersForUploadFromDB(db, upload.ID, campaign.ID, fromDate, toDate)
+		if err != nil {
+			log.Printf("⚠️ DB-Load für campaign_orders fehlgeschlagen, fallback auf Live-API: %v", err)
+		} else {
+			log.Printf("✅ Orders aus DB-Cache geladen: %d", len(orders))
+		}
 
-			orders, err = loadOrdersForUploadFromDB(db, upload.ID, campaign.ID, fromDate, toDate)
+		if limit := validationTokenPoolLimit(); limit > 0 {
+			tokenPool, err = loadCampaignOrderTokenPool(db, campaign.ID, fromDate, toDate, limit)
 			if err != nil {
-				log.Printf("⚠️ DB-Load für campaign_orders fehlgeschlagen, fallback auf Live-API: %v", err)
-			} else {
-				log.Printf("✅ Orders aus DB-Cache geladen: %d", len(orders))
+				log.Printf("⚠️ Token-Pool für unscharfen Ordertoken-Abgleich nicht geladen: %v", err)
 			}
 		}
+	}
 
-		if campaignId != "" && len(orders) == 0 {
-			apiURL := ""
-			missingNetworkConfig := false
-			networkConfigDetail := ""
-			builtURL, buildErr := services.BuildOrdersAPIURL(campaignId, fromDate, toDate)
-			if buildErr != nil {
-				log.Printf("❌ Live-API URL konnte nicht gebaut werden: %v", buildErr)
-				missingNetworkConfig = true
-				networkConfigDetail = buildErr.Error()
+	if campaignId != "" && len(orders) == 0 {
+		apiURL := ""
+		missingNetworkConfig := false
+		networkConfigDetail := ""
+		builtURL, buildErr := services.BuildOrdersAPIURL(campaignId, fromDate, toDate)
+		if buildErr != nil {
+			log.Printf("❌ Live-API URL konnte nicht gebaut werden: %v", buildErr)
+			missingNetworkConfig = true
+			networkConfigDetail = buildErr.Error()
+		} else {
+			apiURL = builtURL
+		}
+		if apiURL != "" {
+			ordersSvc := services.NewOrdersService(apiURL)
+			orders, err = ordersSvc.GetOrders(ctx)
+			if err != nil {
+				log.Printf("❌ Live-API fallback failed: %v", err)
 			} else {
-				apiURL = builtURL
-			}
-			if apiURL != "" {
-				ordersSvc := services.NewOrdersService(apiURL)
-				orders, err = ordersSvc.GetOrders(c.Context())
-				if err != nil {
-					log.Printf("❌ Live-API fallback failed: %v", err)
-				} else {
-					log.Printf("✅ Orders per Live-API geladen (fallback): %d", len(orders))
-				}
-			}
-			if len(orders) == 0 && missingNetworkConfig {
-				return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-					"error":  "Network API is not configured",
-					"detail": networkConfigDetail,
-				})
+				log.Printf("✅ Orders per Live-API geladen (fallback): %d", len(orders))
 			}
 		}
-
-		if campaignId == "" {
-			log.Println("ℹ️ Keine campaignId übergeben – Validierung läuft ohne Order-Abgleich (nur Pflichtfelder/Fallbacks)")
+		if len(orders) == 0 && missingNetworkConfig {
+			return result, headerMapping, &services.ValidationRunError{Status: fiber.StatusServiceUnavailable, Message: "Network API is not configured", Detail: networkConfigDetail}
 		}
+	}
+	if err := ctx.Err(); err != nil {
+		return result, headerMapping, err
+	}
 
-		if orders == nil {
-			orders = []services.ExternalOrder{}
-		}
+	if campaignId == "" {
+		log.Println("ℹ️ Keine campaignId übergeben – Validierung läuft ohne Order-Abgleich (nur Pflichtfelder/Fallbacks)")
+	}
 
-		validationCtx := services.ValidationContext{
-			CampaignID:        strings.TrimSpace(campaignId),
-			ProjectID:         normalizeUintQuery(c.Query("projectId")),
-			PublisherID:       normalizeUintQuery(c.Query("publisherId")),
-			CommissionGroupID: normalizeUintQuery(c.Query("commissionGroupId")),
-			TriggerID:         normalizeUintQuery(c.Query("triggerId")),
-		}
-		validated := validationSvc.Validate(rows, orders, validationCtx)
+	if orders == nil {
+		orders = []services.ExternalOrder{}
+	}
 
-		if len(validated) > 0 {
-			firstRow := validated[0]
-			if statusCell, ok := firstRow.Cells["Status in der uppr Performance Platform"]; ok {
-				log.Printf("✅ Handler - Status in erster Zeile gefunden: '%s'", statusCell.Value)
-			} else {
-				keys := make([]string, 0, len(firstRow.Cells))
-				for k := range firstRow.Cells {
-					keys = append(keys, k)
-				}
-				log.Printf("❌ Handler - Status NICHT in erster Zeile! Keys: %v", keys)
+	progress.SetPhase(models.ValidationPhaseMatching)
+	validationCtx := services.ValidationContext{
+		CampaignID:        campaignId,
+		ProjectID:         params.ProjectID,
+		PublisherID:       params.PublisherID,
+		CommissionGroupID: params.CommissionGroupID,
+		TriggerID:         params.TriggerID,
+		Columns:           headerCtx.Schema.Columns,
+		Checks:            headerCtx.Schema.Checks,
+		TokenPool:         tokenPool,
+		Progress:          progress.SetRows,
+	}
+	validated := services.NewValidationService().Validate(rows, orders, validationCtx)
+
+	if len(validated) > 0 {
+		firstRow := validated[0]
+		if statusCell, ok := firstRow.Cells["Status in der uppr Performance Platform"]; ok {
+			log.Printf("✅ Handler - Status in erster Zeile gefunden: '%s'", statusCell.Value)
+		} else {
+			keys := make([]string, 0, len(firstRow.Cells))
+			for k := range firstRow.Cells {
+				keys = append(keys, k)
 			}
+			log.Printf("❌ Handler - Status NICHT in erster Zeile! Keys: %v", keys)
 		}
-		// ✅ Speichere Validierungsergebnisse in der Datenbank
-		validationResult := models.ValidationResult{
-			UploadID: upload.ID,
-		}
-		if err := db.Where(models.ValidationResult{UploadID: upload.ID}).FirstOrCreate(&validationResult).Error; err != nil {
-			log.Printf("❌ Fehler beim Initialisieren der Validierungsergebnisse: %v", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":  "Failed to persist validation result",
-				"detail": err.Error(),
-			})
-		}
-		validationResult.OrdersCount = len(orders)
-		validationResult.ValidatedRows = validated
-		if err := db.Save(&validationResult).Error; err != nil {
-			log.Printf("❌ Fehler beim Speichern der Validierungsergebnisse: %v", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":  "Failed to persist validation result",
-				"detail": err.Error(),
-			})
-		}
-		log.Printf("✅ Validierungsergebnisse gespeichert für UploadID=%d", upload.ID)
-		return c.JSON(fiber.Map{
-			"uploadId":    upload.ID,
-			"ordersCount": len(orders),
-			"rows":        validated,
-		})
 	}
+	if err := ctx.Err(); err != nil {
+		return result, headerMapping, err
+	}
+
+	// ✅ Speichere den Lauf als neues Validierungsergebnis (Historie statt Überschreiben)
+	progress.SetPhase(models.ValidationPhaseSaving)
+	result = services.NewValidationRun(upload.ID, params, len(orders), validated)
+	result.ValidatedBy = origin.ValidatedBy
+	result.JobID = origin.JobID
+	if err := services.SaveValidationRun(db, &result); err != nil {
+		log.Printf("❌ Fehler beim Speichern der Validierungsergebnisse: %v", err)
+		return result, headerMapping, &services.ValidationRunError{Status: fiber.StatusInternalServerError, Message: "Failed to persist validation result", Detail: err.Error()}
+	}
+	log.Printf("✅ Validierungslauf %d gespeichert für UploadID=%d", result.ID, upload.ID)
+	return result, headerMapping, nil
 }
 
 func deriveDateRangeFromRows(rows []map[string]string) (fromDate string, toDate string, ok bool) {
@@ -308,18 +383,7 @@ func upsertUploadOrderCandidates(db *gorm.DB, uploadID uint, campaignID string,
 	now := time.Now()
 	candidates := make([]models.UploadOrderCandidate, 0, len(rows))
 	for i, row := range rows {
-		orderToken := strings.TrimSpace(row["Ordertoken/OrderID"])
-		if orderToken == "" {
-			orderToken = strings.TrimSpace(row["Ordertoken/Order ID"])
-		}
-		if orderToken == "" {
-			for key, value := range row {
-				if strings.Contains(strings.ToLower(key), "order") && strings.TrimSpace(value) != "" {
-					orderToken = strings.TrimSpace(value)
-					break
-				}
-			}
-		}
+		orderToken, _ := services.RowOrderToken(row)
 
 		rawRow := make(map[string]any, len(row))
 		for k, v := range row {
@@ -408,7 +472,36 @@ func loadOrdersForUploadFromDB(db *gorm.DB, uploadID uint, campaignDBID uint, fr
 	if err := query.Find(&records).Error; err != nil {
 		return nil, err
 	}
+	return campaignOrdersToExternal(records), nil
+}
+
+// loadCampaignOrderTokenPool lädt die Orders der Kampagne im Zeitfenster des Uploads (neueste zuerst, höchstens
+// limit) für den normalisierten/unscharfen Ordertoken-Abgleich.
+func loadCampaignOrderTokenPool(db *gorm.DB, campaignDBID uint, fromDate string, toDate string, limit int) ([]services.ExternalOrder, error) {
+	query := db.Model(&models.CampaignOrder{}).Where("campaign_id = ? AND order_token <> ''", campaignDBID)
+	fromTime, fromErr := time.Parse("2006-01-02", strings.TrimSpace(fromDate))
+	toTime, toErr := time.Parse("2006-01-02", strings.TrimSpace(toDate))
+	if fromErr == nil && toErr == nil {
+		query = query.Where("event_timestamp >= ? AND event_timestamp <= ?", fromTime, toTime.Add(24*time.Hour))
+	}
+
+	var records []models.CampaignOrder
+	if err := query.Order("id DESC").Limit(limit).Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return campaignOrdersToExternal(records), nil
+}
+
+// validationTokenPoolLimit liest VALIDATION_TOKEN_POOL_LIMIT (Default 20000, 0 schaltet den Pool ab).
+func validationTokenPoolLimit() int {
+	value, err := strconv.Atoi(strings.TrimSpace(os.Getenv("VALIDATION_TOKEN_POOL_LIMIT")))
+	if err != nil || value < 0 {
+		return 20000
+	}
+	return value
+}
 
+func campaignOrdersToExternal(records []models.CampaignOrder) []services.ExternalOrder {
 	out := make([]services.ExternalOrder, 0, len(records))
 	for _, rec := range records {
 		timestamp := ""
@@ -431,7 +524,7 @@ func loadOrdersForUploadFromDB(db *gorm.DB, uploadID uint, campaignDBID uint, fr
 		order.CampaignID = payloadMapString(rec.Payload, "campaign_id")
 		out = append(out, order)
 	}
-	return out, nil
+	return out
 }
 
 func payloadMapString(payload map[string]any, key string) string {
@@ -449,7 +542,7 @@ func payloadMapString(payload map[string]any, key string) string {
 	return value
 }
 
-// HandleGetValidation lädt gespeicherte Validierungsergebnisse für einen Upload
+// HandleGetValidation lädt den neuesten gespeicherten Validierungslauf für einen Upload
 func HandleGetValidation(db *gorm.DB) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		claims, ok := c.Locals("user").(jwt.MapClaims)
@@ -474,7 +567,7 @@ func HandleGetValidation(db *gorm.DB) fiber.Handler {
 		log.Printf("✅ GetValidation - Upload gefunden: ID=%d, Status=%s, UploadedBy=%s", upload.ID, upload.Status, upload.UploadedBy)
 
 		var validationResult models.ValidationResult
-		if err := db.Where("upload_id = ?", id).First(&validationResult).Error; err != nil {
+		if err := db.Where("upload_id = ?", id).Order("id DESC").First(&validationResult).Error; err != nil {
 			// Keine Validierung gefunden - für das Frontend als normaler Empty-State behandeln.
 			log.Printf("ℹ️ GetValidation - Keine Validierung für UploadID=%s gefunden (empty state)", id)
 			return c.JSON(fiber.Map{
@@ -500,6 +593,8 @@ func HandleGetValidation(db *gorm.DB) fiber.Handler {
 
 		return c.JSON(fiber.Map{
 			"uploadId":      validationResult.UploadID,
+			"runId":         validationResult.ID,
+			"params":        validationResult.Params,
 			"ordersCount":   validationResult.OrdersCount,
 			"rows":          validationResult.ValidatedRows,
 			"validatedAt":   validationResult.ValidatedAt,
@@ -508,7 +603,7 @@ func HandleGetValidation(db *gorm.DB) fiber.Handler {
 	}
 }
 
-// HandleGetAllValidations lädt alle Validierungsergebnisse für mehrere Uploads auf einmal
+// HandleGetAllValidations lädt den jeweils neuesten Validierungslauf aller Uploads auf einmal
 func HandleGetAllValidations(db *gorm.DB) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		claims, ok := c.Locals("user").(jwt.MapClaims)
@@ -522,7 +617,8 @@ func HandleGetAllValidations(db *gorm.DB) fiber.Handler {
 		}
 
 		var validationResults []models.ValidationResult
-		if err := db.Find(&validationResults).Error; err != nil {
+		latestRuns := db.Model(&models.ValidationResult{}).Select("MAX(id)").Group("upload_id")
+		if err := db.Where("id IN (?)", latestRuns).Find(&validationResults).Error; err != nil {
 			log.Printf("❌ Fehler beim Laden aller Validierungsergebnisse: %v", err)
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "Failed to fetch validations",
@@ -534,6 +630,7 @@ func HandleGetAllValidations(db *gorm.DB) fiber.Handler {
 		for _, vr := range validationResults {
 			resultMap[vr.UploadID] = fiber.Map{
 				"uploadId":    vr.UploadID,
+				"runId":       vr.ID,
 				"ordersCount": vr.OrdersCount,
 				"rows":        vr.ValidatedRows,
 				"validatedAt": vr.ValidatedAt,
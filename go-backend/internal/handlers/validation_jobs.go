@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"nba-dashboard/internal/models"
+	"nba-dashboard/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+type validationJobRequest struct {
+	CampaignID        string `json:"campaignId"`
+	ProjectID         string `json:"projectId"`
+	PublisherID       string `json:"publisherId"`
+	CommissionGroupID string `json:"commissionGroupId"`
+	TriggerID         string `json:"triggerId"`
+	ForceRefresh      bool   `json:"forceRefresh"`
+}
+
+// HandleEnqueueValidation reiht die Validierung eines Uploads als Hintergrundjob ein und antwortet mit 202 und der Job-ID.
+// Parameter wie bei GET /validate, als JSON-Body oder Query. Läuft schon ein Job für den Upload, kommt dieser zurück.
+func HandleEnqueueValidation(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		if role, _ := claims["role"].(string); role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can validate"})
+		}
+		if !services.ValidationJobsEnabled() {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "Validation jobs are disabled, use GET /validate"})
+		}
+
+		var upload models.Upload
+		if err := db.First(&upload, c.Params("id")).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Upload not found"})
+		}
+		if services.IsUploadQuarantined(upload) {
+			return UploadQuarantined(c, upload)
+		}
+
+		body := validationJobRequest{
+			CampaignID:        c.Query("campaignId"),
+			ProjectID:         c.Query("projectId"),
+			PublisherID:       c.Query("publisherId"),
+			CommissionGroupID: c.Query("commissionGroupId"),
+			TriggerID:         c.Query("triggerId"),
+			ForceRefresh:      c.QueryBool("forceRefresh"),
+		}
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&body); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+			}
+		}
+		params := models.ValidationParams{
+			CampaignID:        strings.TrimSpace(body.CampaignID),
+			ProjectID:         normalizeUintQuery(body.ProjectID),
+			PublisherID:       normalizeUintQuery(body.PublisherID),
+			CommissionGroupID: normalizeUintQuery(body.CommissionGroupID),
+			TriggerID:         normalizeUintQuery(body.TriggerID),
+			ForceRefresh:      body.ForceRefresh,
+		}
+
+		actor := WorkflowActorFromClaims(claims)
+		job, created, err := services.EnqueueValidationJob(db, upload.ID, params, actor.Email)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to enqueue validation"})
+		}
+		if !created {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "A validation job is already active for this upload", "jobId": job.ID, "job": job})
+		}
+		c.Set(fiber.HeaderLocation, "/api/validation-jobs/"+strconv.FormatUint(uint64(job.ID), 10))
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"jobId": job.ID, "job": job})
+	}
+}
+
+// HandleGetValidationJob liefert Status, Phase, Zeilenfortschritt und Fehler eines Validierungsjobs (nur Admin).
+func HandleGetValidationJob(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		if role, _ := claims["role"].(string); role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can view validation jobs"})
+		}
+		jobID, err := strconv.ParseUint(c.Params("jobId"), 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid job id"})
+		}
+
+		var job models.ValidationJob
+		if err := db.First(&job, jobID).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Validation job not found"})
+		}
+		return c.JSON(job)
+	}
+}
+
+// HandleListUploadValidationJobs listet die letzten Validierungsjobs eines Uploads (nur Admin).
+func HandleListUploadValidationJobs(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		if role, _ := claims["role"].(string); role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can view validation jobs"})
+		}
+		uploadID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid upload id"})
+		}
+
+		jobs, err := services.ListUploadValidationJobs(db, uint(uploadID), 20)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch validation jobs"})
+		}
+		return c.JSON(fiber.Map{"items": jobs})
+	}
+}
+
+// HandleCancelValidationJob bricht einen eingereihten oder laufenden Validierungsjob ab (nur Admin).
+func HandleCancelValidationJob(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		if role, _ := claims["role"].(string); role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can cancel validation jobs"})
+		}
+		jobID, err := strconv.ParseUint(c.Params("jobId"), 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid job id"})
+		}
+
+		job, err := services.CancelValidationJob(db, uint(jobID))
+		if err != nil {
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Validation job not found"})
+			case errors.Is(err, services.ErrValidationJobFinished):
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Validation job already finished", "job": job})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to cancel validation job"})
+		}
+		return c.JSON(job)
+	}
+}
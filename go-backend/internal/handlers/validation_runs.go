@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"nba-dashboard/internal/models"
+	"nba-dashboard/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// HandleListValidationRuns listet alle Validierungsläufe eines Uploads mit Parametern und Zählern,
+// ohne Zeilen, neueste zuerst (nur Admin).
+func HandleListValidationRuns(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		if role, _ := claims["role"].(string); role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can view validations"})
+		}
+		uploadID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid upload id"})
+		}
+
+		runs, err := services.ListValidationRuns(db, uint(uploadID))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch validation runs"})
+		}
+		return c.JSON(fiber.Map{"items": runs})
+	}
+}
+
+// HandleGetValidationRun liefert einen einzelnen Validierungslauf inkl. Zeilen (nur Admin).
+func HandleGetValidationRun(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		if role, _ := claims["role"].(string); role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can view validations"})
+		}
+		uploadID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid upload id"})
+		}
+		runID, err := strconv.ParseUint(c.Params("runId"), 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid run id"})
+		}
+
+		run, err := services.GetValidationRun(db, uint(uploadID), uint(runID))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Validation run not found"})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch validation run"})
+		}
+		return c.JSON(run)
+	}
+}
+
+// HandleDiffValidationRuns vergleicht zwei Läufe eines Uploads (?from=&to=, Standard: vorheriger gegen neuesten)
+// und liefert die Zeilen, deren Status oder Commission sich geändert hat bzw. die hinzukamen oder fehlen (nur Admin).
+func HandleDiffValidationRuns(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(jwt.MapClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid user claims"})
+		}
+		if role, _ := claims["role"].(string); role != "admin" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only admin can view validations"})
+		}
+		uploadID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid upload id"})
+		}
+
+		var from, to models.ValidationResult
+		fromParam, toParam := c.Query("from"), c.Query("to")
+		switch {
+		case fromParam == "" && toParam == "":
+			from, to, err = services.LatestValidationRunPair(db, uint(uploadID))
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "At least two validation runs are required"})
+			}
+		case fromParam != "" && toParam != "":
+			fromID, fromErr := strconv.ParseUint(fromParam, 10, 64)
+			toID, toErr := strconv.ParseUint(toParam, 10, 64)
+			if fromErr != nil || toErr != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid run id"})
+			}
+			if from, err = services.GetValidationRun(db, uint(uploadID), uint(fromID)); err == nil {
+				to, err = services.GetValidationRun(db, uint(uploadID), uint(toID))
+			}
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Validation run not found"})
+			}
+		default:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "from and to must be given together"})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch validation runs"})
+		}
+
+		return c.JSON(fiber.Map{
+			"uploadId":   uploadID,
+			"fromParams": from.Params,
+			"toParams":   to.Params,
+			"diff":       services.DiffValidationRuns(from, to),
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package lib
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Spalten mit dem Ordertoken, über den Zeilen adressiert und zugeordnet werden (wie bei der Duplikatprüfung).
+var orderTokenColumns = []string{
+	"Ordertoken/OrderID",
+	"Ordertoken/Order ID",
+}
+
+// Header-Zeilen stehen oben; nur so viele Zeilen werden nach der Schlüsselspalte durchsucht.
+const keyHeaderScanRows = 50
+
+// TableKey bestimmt, über welche Spalte Zeilen zugeordnet werden. Column löst die Spalte aus einem Header
+// auf ("" wenn keine passt, z. B. über Schema und Header-Mapping), Normalize vereinheitlicht die Werte vor
+// dem Vergleich. Ohne Column gelten nur die Standardspalten für den Ordertoken. HeaderRow wählt die
+// Kopfzeile (z. B. wie das Header-Mapping); ohne HeaderRow gilt die erste Zeile mit Schlüsselspalte.
+type TableKey struct {
+	Column    func(header []string) string
+	Normalize func(value string) string
+	HeaderRow func(data [][]string) int
+}
+
+func (k TableKey) column(header []string) string {
+	if k.Column != nil {
+		return k.Column(header)
+	}
+	for _, key := range orderTokenColumns {
+		if containsColumn(header, key) {
+			return key
+		}
+	}
+	return ""
+}
+
+func (k TableKey) normalize(value string) string {
+	value = strings.TrimSpace(value)
+	if k.Normalize != nil && value != "" {
+		return k.Normalize(value)
+	}
+	return value
+}
+
+// headerRow liefert die Kopfzeile über HeaderRow oder die erste Zeile, in der sich die Schlüsselspalte
+// auflösen lässt; sonst FindHeaderRow.
+func (k TableKey) headerRow(data [][]string) int {
+	if k.HeaderRow != nil {
+		return k.HeaderRow(data)
+	}
+	for i := 0; i < len(data) && i < keyHeaderScanRows; i++ {
+		if k.column(headerColumns(data, i)) != "" {
+			return i
+		}
+	}
+	return FindHeaderRow(data, orderTokenColumns)
+}
+
+type CellChange struct {
+	Column string `json:"column"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+type DiffRow struct {
+	Key    string            `json:"key"`
+	RowNo  int               `json:"rowNo"`
+	Values map[string]string `json:"values"`
+}
+
+type ModifiedRow struct {
+	Key         string       `json:"key"`
+	BeforeRowNo int          `json:"beforeRowNo"`
+	AfterRowNo  int          `json:"afterRowNo"`
+	Changes     []CellChange `json:"changes"`
+}
+
+type TableDiffSummary struct {
+	Added     int `json:"added"`
+	Removed   int `json:"removed"`
+	Modified  int `json:"modified"`
+	Unchanged int `json:"unchanged"`
+}
+
+// TableDiff ist das Ergebnis von DiffTables. KeyColumn ist leer, wenn nach Zeilennummer verglichen wurde.
+type TableDiff struct {
+	KeyColumn      string           `json:"keyColumn"`
+	Columns        []string         `json:"columns"`
+	AddedColumns   []string         `json:"addedColumns"`
+	RemovedColumns []string         `json:"removedColumns"`
+	Added          []DiffRow        `json:"added"`
+	Removed        []DiffRow        `json:"removed"`
+	Modified       []ModifiedRow    `json:"modified"`
+	Summary        TableDiffSummary `json:"summary"`
+}
+
+type keyedRow struct {
+	key    string
+	rowNo  int
+	values map[string]string
+}
+
+// DiffTables vergleicht zwei Tabellenstände zellgenau. Zeilen werden über die Schlüsselspalte (Ordertoken)
+// zugeordnet; lässt sie sich in einem der Stände nicht auflösen, wird nach Zeilennummer verglichen.
+func DiffTables(before [][]string, after [][]string, key TableKey) TableDiff {
+	beforeHeaderIdx := key.headerRow(before)
+	afterHeaderIdx := key.headerRow(after)
+	beforeHeader := headerColumns(before, beforeHeaderIdx)
+	afterHeader := headerColumns(after, afterHeaderIdx)
+
+	diff := TableDiff{
+		Columns:        unionColumns(beforeHeader, afterHeader),
+		AddedColumns:   missingColumns(afterHeader, beforeHeader),
+		RemovedColumns: missingColumns(beforeHeader, afterHeader),
+		Added:          []DiffRow{},
+		Removed:        []DiffRow{},
+		Modified:       []ModifiedRow{},
+	}
+	beforeKey, afterKey := key.column(beforeHeader), key.column(afterHeader)
+	if beforeKey == "" || afterKey == "" {
+		beforeKey, afterKey = "", ""
+	}
+	diff.KeyColumn = afterKey
+
+	beforeRows := keyRows(TableToMaps(before, beforeHeaderIdx), beforeKey, key)
+	afterRows := keyRows(TableToMaps(after, afterHeaderIdx), afterKey, key)
+
+	beforeByKey := make(map[string]keyedRow, len(beforeRows))
+	for _, row := range beforeRows {
+		beforeByKey[row.key] = row
+	}
+	matched := make(map[string]bool, len(afterRows))
+
+	for _, row := range afterRows {
+		previous, ok := beforeByKey[row.key]
+		if !ok {
+			diff.Added = append(diff.Added, DiffRow{Key: row.key, RowNo: row.rowNo, Values: row.values})
+			continue
+		}
+		matched[row.key] = true
+
+		changes := []CellChange{}
+		for _, col := range diff.Columns {
+			if previous.values[col] != row.values[col] {
+				changes = append(changes, CellChange{Column: col, Before: previous.values[col], After: row.values[col]})
+			}
+		}
+		if len(changes) == 0 {
+			diff.Summary.Unchanged++
+			continue
+		}
+		diff.Modified = append(diff.Modified, ModifiedRow{
+			Key:         row.key,
+			BeforeRowNo: previous.rowNo,
+			AfterRowNo:  row.rowNo,
+			Changes:     changes,
+		})
+	}
+	for _, row := range beforeRows {
+		if !matched[row.key] {
+			diff.Removed = append(diff.Removed, DiffRow{Key: row.key, RowNo: row.rowNo, Values: row.values})
+		}
+	}
+
+	diff.Summary.Added = len(diff.Added)
+	diff.Summary.Removed = len(diff.Removed)
+	diff.Summary.Modified = len(diff.Modified)
+	return diff
+}
+
+func headerColumns(data [][]string, headerIdx int) []string {
+	if headerIdx >= len(data) {
+		return nil
+	}
+	cols := make([]string, 0, len(data[headerIdx]))
+	for _, col := range data[headerIdx] {
+		if col = strings.TrimSpace(col); col != "" {
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
+
+// keyRows vergibt je Zeile einen Schlüssel: normalisierter Ordertoken (bei Wiederholung mit "#n"),
+// ohne Token bzw. ohne Schlüsselspalte die Zeilennummer.
+func keyRows(rows []map[string]string, keyColumn string, key TableKey) []keyedRow {
+	out := make([]keyedRow, 0, len(rows))
+	seen := map[string]int{}
+	for i, values := range rows {
+		rowNo := i + 1
+		rowKey := ""
+		if keyColumn != "" {
+			rowKey = key.normalize(values[keyColumn])
+		}
+		if rowKey == "" {
+			rowKey = fmt.Sprintf("row:%d", rowNo)
+		} else {
+			seen[rowKey]++
+			if seen[rowKey] > 1 {
+				rowKey = fmt.Sprintf("%s#%d", rowKey, seen[rowKey])
+			}
+		}
+		out = append(out, keyedRow{key: rowKey, rowNo: rowNo, values: values})
+	}
+	return out
+}
+
+func unionColumns(before []string, after []string) []string {
+	out := make([]string, 0, len(after)+len(before))
+	seen := map[string]bool{}
+	for _, cols := range [][]string{after, before} {
+		for _, col := range cols {
+			if !seen[col] {
+				seen[col] = true
+				out = append(out, col)
+			}
+		}
+	}
+	return out
+}
+
+func missingColumns(cols []string, in []string) []string {
+	out := []string{}
+	for _, col := range cols {
+		if !containsColumn(in, col) {
+			out = append(out, col)
+		}
+	}
+	return out
+}
+
+func containsColumn(cols []string, col string) bool {
+	for _, c := range cols {
+		if c == col {
+			return true
+		}
+	}
+	return false
+}
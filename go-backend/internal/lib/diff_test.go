@@ -0,0 +1,158 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+)
+
+func trimLeadingZeros(value string) string {
+	if trimmed := strings.TrimLeft(value, "0"); trimmed != "" {
+		return trimmed
+	}
+	return value
+}
+
+func TestDiffTables(t *testing.T) {
+	tests := []struct {
+		name      string
+		before    [][]string
+		after     [][]string
+		key       TableKey
+		keyColumn string
+		summary   TableDiffSummary
+		changed   []string
+	}{
+		{
+			name: "zeilen über ordertoken zugeordnet",
+			before: [][]string{
+				{"Ordertoken/OrderID", "Betrag"},
+				{"A1", "10"},
+				{"A2", "20"},
+			},
+			after: [][]string{
+				{"Ordertoken/OrderID", "Betrag"},
+				{"A2", "25"},
+				{"A3", "30"},
+			},
+			keyColumn: "Ordertoken/OrderID",
+			summary:   TableDiffSummary{Added: 1, Removed: 1, Modified: 1},
+			changed:   []string{"Betrag"},
+		},
+		{
+			name: "normalisierter token bleibt dieselbe zeile",
+			before: [][]string{
+				{"Ordertoken/OrderID", "Betrag"},
+				{"0012345", "10"},
+			},
+			after: [][]string{
+				{"Ordertoken/OrderID", "Betrag"},
+				{"12345", "10"},
+			},
+			key:       TableKey{Normalize: trimLeadingZeros},
+			keyColumn: "Ordertoken/OrderID",
+			summary:   TableDiffSummary{Modified: 1},
+			changed:   []string{"Ordertoken/OrderID"},
+		},
+		{
+			name: "ohne normalisierung neuer token",
+			before: [][]string{
+				{"Ordertoken/OrderID", "Betrag"},
+				{"0012345", "10"},
+			},
+			after: [][]string{
+				{"Ordertoken/OrderID", "Betrag"},
+				{"12345", "10"},
+			},
+			keyColumn: "Ordertoken/OrderID",
+			summary:   TableDiffSummary{Added: 1, Removed: 1},
+		},
+		{
+			name: "schlüsselspalte über resolver",
+			before: [][]string{
+				{"Bericht März"},
+				{"Order ID", "Betrag"},
+				{"A1", "10"},
+				{"A2", "20"},
+			},
+			after: [][]string{
+				{"Order ID", "Betrag"},
+				{"A2", "20"},
+				{"A1", "11"},
+			},
+			key: TableKey{Column: func(header []string) string {
+				if containsColumn(header, "Order ID") {
+					return "Order ID"
+				}
+				return ""
+			}},
+			keyColumn: "Order ID",
+			summary:   TableDiffSummary{Modified: 1, Unchanged: 1},
+			changed:   []string{"Betrag"},
+		},
+		{
+			name: "ohne schlüsselspalte nach zeilennummer",
+			before: [][]string{
+				{"Name", "Betrag"},
+				{"Anna", "10"},
+				{"Ben", "20"},
+			},
+			after: [][]string{
+				{"Name", "Betrag"},
+				{"Ben", "20"},
+			},
+			summary: TableDiffSummary{Removed: 1, Modified: 1},
+			changed: []string{"Name", "Betrag"},
+		},
+		{
+			name: "schlüssel nur auf einer seite",
+			before: [][]string{
+				{"Name", "Betrag"},
+				{"Anna", "10"},
+			},
+			after: [][]string{
+				{"Ordertoken/OrderID", "Name", "Betrag"},
+				{"A1", "Anna", "10"},
+			},
+			summary: TableDiffSummary{Modified: 1},
+			changed: []string{"Ordertoken/OrderID"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := DiffTables(tt.before, tt.after, tt.key)
+			if diff.KeyColumn != tt.keyColumn {
+				t.Errorf("KeyColumn = %q, want %q", diff.KeyColumn, tt.keyColumn)
+			}
+			if diff.Summary != tt.summary {
+				t.Errorf("Summary = %+v, want %+v", diff.Summary, tt.summary)
+			}
+			var changed []string
+			for _, row := range diff.Modified {
+				for _, change := range row.Changes {
+					changed = append(changed, change.Column)
+				}
+			}
+			if strings.Join(changed, ",") != strings.Join(tt.changed, ",") {
+				t.Errorf("changed columns = %v, want %v", changed, tt.changed)
+			}
+		})
+	}
+}
+
+func TestDiffTablesRepeatedTokens(t *testing.T) {
+	before := [][]string{
+		{"Ordertoken/OrderID", "Betrag"},
+		{"A1", "10"},
+		{"A1", "20"},
+	}
+	after := [][]string{
+		{"Ordertoken/OrderID", "Betrag"},
+		{"A1", "10"},
+		{"A1", "21"},
+	}
+	diff := DiffTables(before, after, TableKey{})
+	if len(diff.Modified) != 1 || diff.Modified[0].Key != "A1#2" {
+		t.Fatalf("Modified = %+v, want one change for A1#2", diff.Modified)
+	}
+}
@@ -0,0 +1,140 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNoOrderTokenColumn meldet, dass sich im Header der ersten Quelle keine Ordertoken-Spalte auflösen lässt.
+var ErrNoOrderTokenColumn = errors.New("no order token column found")
+
+// MergeSource ist eine Eingangstabelle für MergeTables; ID ist die Upload-ID der Quelle.
+type MergeSource struct {
+	ID   uint
+	Data [][]string
+}
+
+// MergedRowOrigin hält fest, aus welcher Quelle und Datenzeile (1-basiert) eine zusammengeführte Zeile stammt.
+type MergedRowOrigin struct {
+	RowNo       int    `json:"rowNo"`
+	SourceID    uint   `json:"sourceUploadId"`
+	SourceRowNo int    `json:"sourceRowNo"`
+	OrderToken  string `json:"orderToken,omitempty"`
+}
+
+// MergeSkippedRow ist eine Zeile, deren Ordertoken bereits aus einer früheren Quelle übernommen wurde.
+type MergeSkippedRow struct {
+	SourceID    uint   `json:"sourceUploadId"`
+	SourceRowNo int    `json:"sourceRowNo"`
+	OrderToken  string `json:"orderToken"`
+	KeptRowNo   int    `json:"keptRowNo"`
+}
+
+// TableMerge ist das Ergebnis von MergeTables. Data beginnt mit dem Header der ersten Quelle.
+type TableMerge struct {
+	KeyColumn string            `json:"keyColumn"`
+	Columns   []string          `json:"columns"`
+	Data      [][]string        `json:"-"`
+	Origins   []MergedRowOrigin `json:"origins"`
+	Skipped   []MergeSkippedRow `json:"skipped"`
+}
+
+// HeaderMismatchError meldet eine Quelle, deren Spalten nicht zur ersten Quelle passen.
+type HeaderMismatchError struct {
+	SourceID uint     `json:"sourceUploadId"`
+	Missing  []string `json:"missing"`
+	Extra    []string `json:"extra"`
+}
+
+func (e *HeaderMismatchError) Error() string {
+	return fmt.Sprintf("upload %d has an incompatible header (missing: %s; extra: %s)",
+		e.SourceID, strings.Join(e.Missing, ", "), strings.Join(e.Extra, ", "))
+}
+
+// MergeTables führt Tabellen mit gleichen Spalten zusammen. Die Ordertoken-Spalte wird über key im Header
+// der ersten Quelle aufgelöst (ohne sie: ErrNoOrderTokenColumn), die Header-Zeile jeder weiteren Quelle mit
+// FindHeaderRow anhand des ersten Headers gesucht; Spalten werden nach Namen ausgerichtet (Reihenfolge
+// beliebig). Zeilen mit bereits übernommenem (normalisiertem) Ordertoken werden übersprungen; es gilt das
+// erste Vorkommen in der Reihenfolge der Quellen. Zeilen ohne Ordertoken werden immer übernommen.
+func MergeTables(sources []MergeSource, key TableKey) (TableMerge, error) {
+	var merged TableMerge
+	if len(sources) == 0 || len(sources[0].Data) == 0 {
+		return merged, fmt.Errorf("first source is empty")
+	}
+
+	firstHeaderIdx := key.headerRow(sources[0].Data)
+	merged.Columns = headerColumns(sources[0].Data, firstHeaderIdx)
+	if len(merged.Columns) == 0 {
+		return merged, fmt.Errorf("upload %d has no header", sources[0].ID)
+	}
+	merged.KeyColumn = key.column(merged.Columns)
+	if merged.KeyColumn == "" {
+		return merged, ErrNoOrderTokenColumn
+	}
+	merged.Data = [][]string{append([]string(nil), merged.Columns...)}
+	merged.Origins = []MergedRowOrigin{}
+	merged.Skipped = []MergeSkippedRow{}
+
+	keptByToken := map[string]int{}
+	for i, source := range sources {
+		headerIdx := firstHeaderIdx
+		if i > 0 {
+			headerIdx = FindHeaderRow(source.Data, merged.Columns)
+		}
+		if headerIdx >= len(source.Data) {
+			return merged, &HeaderMismatchError{SourceID: source.ID, Missing: merged.Columns, Extra: []string{}}
+		}
+		cols := headerColumns(source.Data, headerIdx)
+		missing := missingColumns(merged.Columns, cols)
+		extra := missingColumns(cols, merged.Columns)
+		if len(missing) > 0 || len(extra) > 0 {
+			return merged, &HeaderMismatchError{SourceID: source.ID, Missing: missing, Extra: extra}
+		}
+
+		for n, values := range TableToMaps(source.Data, headerIdx) {
+			if mapIsEmpty(values) {
+				continue
+			}
+			token := values[merged.KeyColumn]
+			normalized := key.normalize(token)
+			if normalized != "" {
+				if kept, ok := keptByToken[normalized]; ok {
+					merged.Skipped = append(merged.Skipped, MergeSkippedRow{
+						SourceID:    source.ID,
+						SourceRowNo: n + 1,
+						OrderToken:  token,
+						KeptRowNo:   kept,
+					})
+					continue
+				}
+			}
+
+			row := make([]string, len(merged.Columns))
+			for j, col := range merged.Columns {
+				row[j] = values[col]
+			}
+			merged.Data = append(merged.Data, row)
+			rowNo := len(merged.Data) - 1
+			if normalized != "" {
+				keptByToken[normalized] = rowNo
+			}
+			merged.Origins = append(merged.Origins, MergedRowOrigin{
+				RowNo:       rowNo,
+				SourceID:    source.ID,
+				SourceRowNo: n + 1,
+				OrderToken:  token,
+			})
+		}
+	}
+	return merged, nil
+}
+
+func mapIsEmpty(values map[string]string) bool {
+	for _, v := range values {
+		if v != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+package lib
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func orderIDKey(header []string) string {
+	if containsColumn(header, "Order ID") {
+		return "Order ID"
+	}
+	return ""
+}
+
+func TestMergeTables(t *testing.T) {
+	tests := []struct {
+		name      string
+		sources   []MergeSource
+		key       TableKey
+		keyColumn string
+		want      [][]string
+		skipped   []MergeSkippedRow
+	}{
+		{
+			name: "erstes vorkommen gewinnt",
+			sources: []MergeSource{
+				{ID: 1, Data: [][]string{{"Ordertoken/OrderID", "Betrag"}, {"A1", "10"}, {"A2", "20"}}},
+				{ID: 2, Data: [][]string{{"Ordertoken/OrderID", "Betrag"}, {"A2", "99"}, {"A3", "30"}}},
+			},
+			keyColumn: "Ordertoken/OrderID",
+			want:      [][]string{{"Ordertoken/OrderID", "Betrag"}, {"A1", "10"}, {"A2", "20"}, {"A3", "30"}},
+			skipped:   []MergeSkippedRow{{SourceID: 2, SourceRowNo: 1, OrderToken: "A2", KeptRowNo: 2}},
+		},
+		{
+			name: "dedup auf normalisiertem token",
+			sources: []MergeSource{
+				{ID: 1, Data: [][]string{{"Ordertoken/OrderID", "Betrag"}, {"0012345", "10"}}},
+				{ID: 2, Data: [][]string{{"Ordertoken/OrderID", "Betrag"}, {"12345", "11"}}},
+			},
+			key:       TableKey{Normalize: trimLeadingZeros},
+			keyColumn: "Ordertoken/OrderID",
+			want:      [][]string{{"Ordertoken/OrderID", "Betrag"}, {"0012345", "10"}},
+			skipped:   []MergeSkippedRow{{SourceID: 2, SourceRowNo: 1, OrderToken: "12345", KeptRowNo: 1}},
+		},
+		{
+			name: "schlüsselspalte über mapping statt orderwert",
+			sources: []MergeSource{
+				{ID: 1, Data: [][]string{{"Orderwert", "Order ID"}, {"10", "A1"}}},
+				{ID: 2, Data: [][]string{{"Order ID", "Orderwert"}, {"A2", "10"}, {"A1", "12"}}},
+			},
+			key:       TableKey{Column: orderIDKey},
+			keyColumn: "Order ID",
+			want:      [][]string{{"Orderwert", "Order ID"}, {"10", "A1"}, {"10", "A2"}},
+			skipped:   []MergeSkippedRow{{SourceID: 2, SourceRowNo: 2, OrderToken: "A1", KeptRowNo: 1}},
+		},
+		{
+			name: "zeilen ohne token bleiben erhalten",
+			sources: []MergeSource{
+				{ID: 1, Data: [][]string{{"Ordertoken/OrderID", "Betrag"}, {"", "10"}, {"", "", ""}}},
+				{ID: 2, Data: [][]string{{"Ordertoken/OrderID", "Betrag"}, {"", "10"}}},
+			},
+			keyColumn: "Ordertoken/OrderID",
+			want:      [][]string{{"Ordertoken/OrderID", "Betrag"}, {"", "10"}, {"", "10"}},
+			skipped:   []MergeSkippedRow{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := MergeTables(tt.sources, tt.key)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if merged.KeyColumn != tt.keyColumn {
+				t.Errorf("KeyColumn = %q, want %q", merged.KeyColumn, tt.keyColumn)
+			}
+			if !reflect.DeepEqual(merged.Data, tt.want) {
+				t.Errorf("Data = %v, want %v", merged.Data, tt.want)
+			}
+			if !reflect.DeepEqual(merged.Skipped, tt.skipped) {
+				t.Errorf("Skipped = %+v, want %+v", merged.Skipped, tt.skipped)
+			}
+			if len(merged.Origins) != len(merged.Data)-1 {
+				t.Errorf("Origins = %d, want one per data row (%d)", len(merged.Origins), len(merged.Data)-1)
+			}
+		})
+	}
+}
+
+func TestMergeTablesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		sources []MergeSource
+		key     TableKey
+		check   func(error) bool
+	}{
+		{
+			name: "ohne ordertoken-spalte",
+			sources: []MergeSource{
+				{ID: 1, Data: [][]string{{"Order ID", "Orderwert"}, {"A1", "10"}}},
+			},
+			check: func(err error) bool { return errors.Is(err, ErrNoOrderTokenColumn) },
+		},
+		{
+			name: "abweichender header",
+			sources: []MergeSource{
+				{ID: 1, Data: [][]string{{"Ordertoken/OrderID", "Betrag"}, {"A1", "10"}}},
+				{ID: 2, Data: [][]string{{"Ordertoken/OrderID", "Provision"}, {"A2", "1"}}},
+			},
+			check: func(err error) bool {
+				var mismatch *HeaderMismatchError
+				return errors.As(err, &mismatch) && mismatch.SourceID == 2 &&
+					reflect.DeepEqual(mismatch.Missing, []string{"Betrag"}) &&
+					reflect.DeepEqual(mismatch.Extra, []string{"Provision"})
+			},
+		},
+		{
+			name:    "leere erste quelle",
+			sources: []MergeSource{{ID: 1}},
+			check:   func(err error) bool { return err != nil },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := MergeTables(tt.sources, tt.key); !tt.check(err) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Operationen für ApplyTableOps.
+const (
+	TableOpSetCell   = "set_cell"
+	TableOpInsertRow = "insert_row"
+	TableOpDeleteRow = "delete_row"
+	TableOpMoveRow   = "move_row"
+)
+
+// TableOp ist eine einzelne Änderung an einer Tabelle. Zeilen werden über Row (1-basiert,
+// erste Datenzeile unter dem Header) oder über OrderToken adressiert, Spalten über den Headernamen.
+type TableOp struct {
+	Op         string            `json:"op"`
+	Row        int               `json:"row,omitempty"`
+	OrderToken string            `json:"orderToken,omitempty"`
+	Column     string            `json:"column,omitempty"`
+	Value      string            `json:"value,omitempty"`
+	Cells      map[string]string `json:"cells,omitempty"`
+	To         int               `json:"to,omitempty"`
+}
+
+// TableOpError beschreibt, welche Operation warum nicht angewendet werden konnte.
+type TableOpError struct {
+	Index int
+	Op    string
+	Err   string
+}
+
+func (e *TableOpError) Error() string {
+	return fmt.Sprintf("operation %d (%s): %s", e.Index, e.Op, e.Err)
+}
+
+// TablePatch ist das Ergebnis von ApplyTableOps. ChangedRows sind die Datenzeilen (1-basiert, im neuen
+// Stand), die geändert, eingefügt oder verschoben wurden; alle anderen stehen unverändert an ihrer Stelle.
+// RowCount ist die neue Zahl der Datenzeilen, Zeilen dahinter sind durch Löschen entfallen.
+type TablePatch struct {
+	Data        [][]string
+	HeaderRow   int
+	ChangedRows []int
+	RowCount    int
+}
+
+// patchedRow merkt sich zu jeder Datenzeile ihre ursprüngliche Nummer (0 bei neuen Zeilen) und ob sie
+// geändert wurde.
+type patchedRow struct {
+	origin int
+	dirty  bool
+}
+
+// ApplyTableOps wendet alle Operationen der Reihe nach auf eine Kopie der Tabelle an. Kopfzeile,
+// Ordertoken-Spalte und Vergleich der Tokens kommen aus key (wie bei Diff und Header-Mapping).
+// Schlägt eine Operation fehl, wird nichts zurückgegeben (alles oder nichts).
+func ApplyTableOps(data [][]string, ops []TableOp, key TableKey) (TablePatch, error) {
+	if len(data) == 0 {
+		return TablePatch{}, errors.New("table is empty")
+	}
+
+	out := make([][]string, len(data))
+	for i, row := range data {
+		out[i] = append([]string(nil), row...)
+	}
+
+	headerIdx := key.headerRow(out)
+	header := out[headerIdx]
+	rows := make([]patchedRow, len(out)-headerIdx-1)
+	for i := range rows {
+		rows[i].origin = i + 1
+	}
+
+	for i, op := range ops {
+		opErr := func(format string, args ...any) error {
+			return &TableOpError{Index: i, Op: op.Op, Err: fmt.Sprintf(format, args...)}
+		}
+		rowCount := len(out) - headerIdx - 1
+
+		switch op.Op {
+		case TableOpSetCell:
+			idx, err := resolveTableRow(out, headerIdx, op, key)
+			if err != nil {
+				return TablePatch{}, opErr("%v", err)
+			}
+			col := columnIndex(header, op.Column)
+			if col < 0 {
+				return TablePatch{}, opErr("unknown column %q", op.Column)
+			}
+			out[idx] = padRow(out[idx], len(header))
+			out[idx][col] = op.Value
+			rows[idx-headerIdx-1].dirty = true
+
+		case TableOpInsertRow:
+			pos := rowCount + 1
+			if op.Row != 0 {
+				if op.Row < 1 || op.Row > rowCount+1 {
+					return TablePatch{}, opErr("row %d out of range", op.Row)
+				}
+				pos = op.Row
+			}
+			row := make([]string, len(header))
+			for name, value := range op.Cells {
+				col := columnIndex(header, name)
+				if col < 0 {
+					return TablePatch{}, opErr("unknown column %q", name)
+				}
+				row[col] = value
+			}
+			at := headerIdx + pos
+			out = slices.Insert(out, at, row)
+			rows = slices.Insert(rows, pos-1, patchedRow{dirty: true})
+
+		case TableOpDeleteRow:
+			idx, err := resolveTableRow(out, headerIdx, op, key)
+			if err != nil {
+				return TablePatch{}, opErr("%v", err)
+			}
+			out = slices.Delete(out, idx, idx+1)
+			rows = slices.Delete(rows, idx-headerIdx-1, idx-headerIdx)
+
+		case TableOpMoveRow:
+			idx, err := resolveTableRow(out, headerIdx, op, key)
+			if err != nil {
+				return TablePatch{}, opErr("%v", err)
+			}
+			if op.To < 1 || op.To > rowCount {
+				return TablePatch{}, opErr("target row %d out of range", op.To)
+			}
+			row, state := out[idx], rows[idx-headerIdx-1]
+			out = slices.Delete(out, idx, idx+1)
+			rows = slices.Delete(rows, idx-headerIdx-1, idx-headerIdx)
+			out = slices.Insert(out, headerIdx+op.To, row)
+			rows = slices.Insert(rows, op.To-1, state)
+
+		default:
+			return TablePatch{}, opErr("unsupported operation")
+		}
+	}
+
+	patch := TablePatch{Data: out, HeaderRow: headerIdx, ChangedRows: []int{}, RowCount: len(rows)}
+	for i, row := range rows {
+		if row.dirty || row.origin != i+1 {
+			patch.ChangedRows = append(patch.ChangedRows, i+1)
+		}
+	}
+	return patch, nil
+}
+
+// resolveTableRow liefert den absoluten Index der adressierten Datenzeile.
+func resolveTableRow(data [][]string, headerIdx int, op TableOp, key TableKey) (int, error) {
+	rowCount := len(data) - headerIdx - 1
+	token := key.normalize(op.OrderToken)
+	if token == "" {
+		if op.Row < 1 || op.Row > rowCount {
+			return 0, fmt.Errorf("row %d out of range", op.Row)
+		}
+		return headerIdx + op.Row, nil
+	}
+
+	header := data[headerIdx]
+	keyColumn := key.column(headerColumns(data, headerIdx))
+	if keyColumn == "" {
+		return 0, errors.New("table has no order token column")
+	}
+	col := columnIndex(header, keyColumn)
+
+	found := -1
+	for i := headerIdx + 1; i < len(data); i++ {
+		if col < len(data[i]) && key.normalize(data[i][col]) == token {
+			if found >= 0 {
+				return 0, fmt.Errorf("order token %q is not unique", strings.TrimSpace(op.OrderToken))
+			}
+			found = i
+		}
+	}
+	if found < 0 {
+		return 0, fmt.Errorf("order token %q not found", strings.TrimSpace(op.OrderToken))
+	}
+	return found, nil
+}
+
+func columnIndex(header []string, name string) int {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return -1
+	}
+	for i, col := range header {
+		if strings.TrimSpace(col) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func padRow(row []string, length int) []string {
+	for len(row) < length {
+		row = append(row, "")
+	}
+	return row
+}
@@ -0,0 +1,169 @@
+package lib
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyTableOps(t *testing.T) {
+	base := [][]string{
+		{"Ordertoken/OrderID", "Betrag"},
+		{"A1", "10"},
+		{"A2", "20"},
+		{"A3", "30"},
+	}
+
+	tests := []struct {
+		name    string
+		ops     []TableOp
+		want    [][]string
+		changed []int
+		wantErr int
+	}{
+		{
+			name:    "zelle über zeilennummer setzen",
+			ops:     []TableOp{{Op: TableOpSetCell, Row: 2, Column: "Betrag", Value: "22"}},
+			want:    [][]string{{"Ordertoken/OrderID", "Betrag"}, {"A1", "10"}, {"A2", "22"}, {"A3", "30"}},
+			changed: []int{2},
+		},
+		{
+			name:    "zelle über ordertoken setzen",
+			ops:     []TableOp{{Op: TableOpSetCell, OrderToken: " A3 ", Column: "Betrag", Value: "33"}},
+			want:    [][]string{{"Ordertoken/OrderID", "Betrag"}, {"A1", "10"}, {"A2", "20"}, {"A3", "33"}},
+			changed: []int{3},
+		},
+		{
+			name: "zeile einfügen, löschen und verschieben",
+			ops: []TableOp{
+				{Op: TableOpInsertRow, Row: 1, Cells: map[string]string{"Ordertoken/OrderID": "A0", "Betrag": "0"}},
+				{Op: TableOpDeleteRow, OrderToken: "A2"},
+				{Op: TableOpMoveRow, Row: 3, To: 1},
+			},
+			want:    [][]string{{"Ordertoken/OrderID", "Betrag"}, {"A3", "30"}, {"A0", "0"}, {"A1", "10"}},
+			changed: []int{1, 2, 3},
+		},
+		{
+			name:    "zeile am ende anfügen",
+			ops:     []TableOp{{Op: TableOpInsertRow, Cells: map[string]string{"Ordertoken/OrderID": "A4"}}},
+			want:    [][]string{{"Ordertoken/OrderID", "Betrag"}, {"A1", "10"}, {"A2", "20"}, {"A3", "30"}, {"A4", ""}},
+			changed: []int{4},
+		},
+		{
+			name:    "letzte zeile löschen",
+			ops:     []TableOp{{Op: TableOpDeleteRow, Row: 3}},
+			want:    [][]string{{"Ordertoken/OrderID", "Betrag"}, {"A1", "10"}, {"A2", "20"}},
+			changed: []int{},
+		},
+		{
+			name:    "erste zeile löschen verschiebt die übrigen",
+			ops:     []TableOp{{Op: TableOpDeleteRow, OrderToken: "A1"}},
+			want:    [][]string{{"Ordertoken/OrderID", "Betrag"}, {"A2", "20"}, {"A3", "30"}},
+			changed: []int{1, 2},
+		},
+		{
+			name:    "unbekannte spalte",
+			ops:     []TableOp{{Op: TableOpSetCell, Row: 1, Column: "Provision", Value: "1"}},
+			wantErr: 0,
+		},
+		{
+			name: "fehler bricht alles ab",
+			ops: []TableOp{
+				{Op: TableOpSetCell, Row: 1, Column: "Betrag", Value: "11"},
+				{Op: TableOpDeleteRow, Row: 4},
+			},
+			wantErr: 1,
+		},
+		{
+			name:    "unbekannter ordertoken",
+			ops:     []TableOp{{Op: TableOpDeleteRow, OrderToken: "B1"}},
+			wantErr: 0,
+		},
+		{
+			name:    "unbekannte operation",
+			ops:     []TableOp{{Op: "rename_column"}},
+			wantErr: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyTableOps(base, tt.ops, TableKey{})
+			if tt.want == nil {
+				var opErr *TableOpError
+				if !errors.As(err, &opErr) || opErr.Index != tt.wantErr {
+					t.Fatalf("err = %v, want TableOpError at index %d", err, tt.wantErr)
+				}
+				if got.Data != nil {
+					t.Errorf("got = %v, want nil on error", got.Data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got.Data, tt.want) {
+				t.Errorf("got = %v, want %v", got.Data, tt.want)
+			}
+			if !reflect.DeepEqual(got.ChangedRows, tt.changed) || got.RowCount != len(tt.want)-1 {
+				t.Errorf("changed = %v (rows %d), want %v (rows %d)", got.ChangedRows, got.RowCount, tt.changed, len(tt.want)-1)
+			}
+			if base[2][1] != "20" || len(base) != 4 {
+				t.Errorf("input table was modified: %v", base)
+			}
+		})
+	}
+}
+
+func TestApplyTableOpsDuplicateToken(t *testing.T) {
+	data := [][]string{
+		{"Ordertoken/OrderID", "Betrag"},
+		{"A1", "10"},
+		{"A1", "20"},
+	}
+	if _, err := ApplyTableOps(data, []TableOp{{Op: TableOpDeleteRow, OrderToken: "A1"}}, TableKey{}); err == nil {
+		t.Fatal("expected error for non-unique order token")
+	}
+}
+
+func TestApplyTableOpsWithKey(t *testing.T) {
+	// Titelzeile über dem Header, Ordertoken unter einem Alias; die Kopfzeile bestimmt der Key wie beim Header-Mapping.
+	data := [][]string{
+		{"Nachbuchungen März", ""},
+		{"Bestellnummer", "Betrag"},
+		{"ab-1", "10"},
+		{"AB-2", "20"},
+	}
+	key := TableKey{
+		Column: func(header []string) string {
+			if containsColumn(header, "Bestellnummer") {
+				return "Bestellnummer"
+			}
+			return ""
+		},
+		Normalize: strings.ToUpper,
+		HeaderRow: func([][]string) int { return 1 },
+	}
+
+	got, err := ApplyTableOps(data, []TableOp{
+		{Op: TableOpSetCell, OrderToken: "AB-1", Column: "Betrag", Value: "11"},
+		{Op: TableOpDeleteRow, OrderToken: "ab-2"},
+	}, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := [][]string{{"Nachbuchungen März", ""}, {"Bestellnummer", "Betrag"}, {"ab-1", "11"}}
+	if !reflect.DeepEqual(got.Data, want) {
+		t.Errorf("got = %v, want %v", got.Data, want)
+	}
+	if got.HeaderRow != 1 || got.RowCount != 1 || !reflect.DeepEqual(got.ChangedRows, []int{1}) {
+		t.Errorf("header %d, rows %d, changed %v; want 1, 1, [1]", got.HeaderRow, got.RowCount, got.ChangedRows)
+	}
+
+	// Zeilennummern zählen ab der Kopfzeile des Keys, nicht ab der Titelzeile.
+	got, err = ApplyTableOps(data, []TableOp{{Op: TableOpSetCell, Row: 2, Column: "Betrag", Value: "22"}}, key)
+	if err != nil || got.Data[3][1] != "22" {
+		t.Fatalf("row 2 = %v (err %v), want AB-2 updated", got.Data, err)
+	}
+}
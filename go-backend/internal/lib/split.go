@@ -0,0 +1,61 @@
+package lib
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TableGroup ist ein Teil einer Tabelle mit gleichem Wert in der Split-Spalte.
+// Data enthält die Zeilen bis einschließlich Header unverändert, danach die Datenzeilen der Gruppe.
+type TableGroup struct {
+	Value string
+	Data  [][]string
+	Rows  int
+}
+
+// SplitTableByColumn gruppiert die Datenzeilen nach dem Wert einer Spalte (Reihenfolge des ersten Auftretens).
+// Leere Zeilen werden übersprungen; Zeilen ohne Wert landen in einer Gruppe mit Value "".
+func SplitTableByColumn(data [][]string, column string) ([]TableGroup, error) {
+	headerIdx := FindHeaderRow(data, []string{column})
+	if headerIdx >= len(data) {
+		return nil, fmt.Errorf("column %q not found", column)
+	}
+	col := columnIndex(data[headerIdx], column)
+	if col < 0 {
+		return nil, fmt.Errorf("column %q not found", column)
+	}
+
+	groups := []TableGroup{}
+	byValue := map[string]int{}
+	for _, row := range data[headerIdx+1:] {
+		if isEmptyRow(row) {
+			continue
+		}
+		value := ""
+		if col < len(row) {
+			value = strings.TrimSpace(row[col])
+		}
+		idx, ok := byValue[value]
+		if !ok {
+			prefix := make([][]string, 0, headerIdx+1)
+			for _, head := range data[:headerIdx+1] {
+				prefix = append(prefix, append([]string(nil), head...))
+			}
+			groups = append(groups, TableGroup{Value: value, Data: prefix})
+			idx = len(groups) - 1
+			byValue[value] = idx
+		}
+		groups[idx].Data = append(groups[idx].Data, append([]string(nil), row...))
+		groups[idx].Rows++
+	}
+	return groups, nil
+}
+
+func isEmptyRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package lib
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitTableByColumn(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    [][]string
+		column  string
+		want    []TableGroup
+		wantErr bool
+	}{
+		{
+			name: "gruppen in reihenfolge des ersten auftretens",
+			data: [][]string{
+				{"Advertiser", "Ordertoken/OrderID"},
+				{"B", "1"},
+				{"A", "2"},
+				{" B ", "3"},
+			},
+			column: "Advertiser",
+			want: []TableGroup{
+				{Value: "B", Rows: 2, Data: [][]string{{"Advertiser", "Ordertoken/OrderID"}, {"B", "1"}, {" B ", "3"}}},
+				{Value: "A", Rows: 1, Data: [][]string{{"Advertiser", "Ordertoken/OrderID"}, {"A", "2"}}},
+			},
+		},
+		{
+			name: "titelzeile bleibt in jeder gruppe, leere zeilen entfallen",
+			data: [][]string{
+				{"Nachbuchung März"},
+				{"Advertiser", "Ordertoken/OrderID"},
+				{"", ""},
+				{"A", "1"},
+				{"", "2"},
+			},
+			column: "Advertiser",
+			want: []TableGroup{
+				{Value: "A", Rows: 1, Data: [][]string{{"Nachbuchung März"}, {"Advertiser", "Ordertoken/OrderID"}, {"A", "1"}}},
+				{Value: "", Rows: 1, Data: [][]string{{"Nachbuchung März"}, {"Advertiser", "Ordertoken/OrderID"}, {"", "2"}}},
+			},
+		},
+		{
+			name: "kurze zeile ohne split-wert",
+			data: [][]string{
+				{"Ordertoken/OrderID", "Advertiser"},
+				{"1"},
+			},
+			column: "Advertiser",
+			want: []TableGroup{
+				{Value: "", Rows: 1, Data: [][]string{{"Ordertoken/OrderID", "Advertiser"}, {"1"}}},
+			},
+		},
+		{
+			name:    "unbekannte spalte",
+			data:    [][]string{{"Ordertoken/OrderID"}, {"1"}},
+			column:  "Advertiser",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups, err := SplitTableByColumn(tt.data, tt.column)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", groups)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(groups, tt.want) {
+				t.Errorf("groups = %+v, want %+v", groups, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitTableByColumnCopiesRows(t *testing.T) {
+	data := [][]string{{"Advertiser"}, {"A"}}
+	groups, err := SplitTableByColumn(data, "Advertiser")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	groups[0].Data[0][0] = "changed"
+	groups[0].Data[1][0] = "changed"
+	if data[0][0] != "Advertiser" || data[1][0] != "A" {
+		t.Errorf("input table was modified: %v", data)
+	}
+}
@@ -0,0 +1,29 @@
+package models
+
+// Spaltentypen einer Nachbuchungsdatei (Claim).
+const (
+	ClaimColumnTypeText     = "text"
+	ClaimColumnTypeEmail    = "email"
+	ClaimColumnTypeDateTime = "datetime"
+	ClaimColumnTypeDate     = "date"
+	ClaimColumnTypeDecimal  = "decimal"
+	ClaimColumnTypeEnum     = "enum"
+)
+
+// Rollen einer Spalte für die Endkunden-Prüfungen, unabhängig vom Header-Text.
+const (
+	ClaimColumnRoleCustomerName    = "customer_name"
+	ClaimColumnRoleCustomerAddress = "customer_address"
+)
+
+// ClaimColumn beschreibt eine Spalte einer Nachbuchungsdatei. Name ist der exakte Header-Text;
+// AllowedValues gilt nur für den Typ "enum". Role kennzeichnet Endkundenname bzw. -adresse.
+type ClaimColumn struct {
+	Name          string   `json:"name"`
+	Required      bool     `json:"required"`
+	Type          string   `json:"type"`
+	AllowedValues []string `json:"allowedValues,omitempty"`
+	Description   string   `json:"description,omitempty"`
+	Example       string   `json:"example,omitempty"`
+	Role          string   `json:"role,omitempty"`
+}
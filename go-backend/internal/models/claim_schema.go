@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// ClaimSchema überschreibt die Standardspalten einer Nachbuchungsdatei für einen Advertiser und/oder eine
+// Kampagne. AdvertiserID 0 und leere CampaignExternalID bedeuten "alle"; beide leer ist der globale Standard.
+// Checks nil bedeutet: alle Endkunden-Prüfungen aktiv.
+type ClaimSchema struct {
+	ID                 uint                `gorm:"primaryKey" json:"id"`
+	Name               string              `gorm:"not null;default:''" json:"name"`
+	AdvertiserID       uint                `gorm:"not null;default:0;uniqueIndex:idx_claim_schema_scope" json:"advertiser_id"`
+	CampaignExternalID string              `gorm:"not null;default:'';uniqueIndex:idx_claim_schema_scope" json:"campaign_external_id"`
+	Columns            []ClaimColumn       `gorm:"type:jsonb;serializer:json" json:"columns"`
+	Checks             *CustomerDataChecks `gorm:"type:jsonb;serializer:json" json:"checks"`
+	UpdatedBy          string              `gorm:"not null;default:''" json:"updated_by"`
+	CreatedAt          time.Time           `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt          time.Time           `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// CustomerDataChecks schaltet die Prüfungen der Endkunden-Spalten (Name, Adresse, E-Mail) je Schema.
+type CustomerDataChecks struct {
+	EmailSyntax     bool `json:"emailSyntax"`
+	DisposableEmail bool `json:"disposableEmail"`
+	PostalCode      bool `json:"postalCode"`
+	Address         bool `json:"address"`
+	Name            bool `json:"name"`
+	Duplicates      bool `json:"duplicates"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// HeaderMappingProfile ordnet Spaltenüberschriften aus Dateien eines Advertisers und/oder Publishers den
+// Spalten des Claim-Schemas zu (Quell-Header -> Schema-Spalte). AdvertiserID bzw. PublisherID 0 bedeuten "alle".
+type HeaderMappingProfile struct {
+	ID           uint              `gorm:"primaryKey" json:"id"`
+	Name         string            `gorm:"not null;default:''" json:"name"`
+	AdvertiserID uint              `gorm:"not null;default:0;uniqueIndex:idx_header_mapping_scope" json:"advertiser_id"`
+	PublisherID  uint              `gorm:"not null;default:0;uniqueIndex:idx_header_mapping_scope" json:"publisher_id"`
+	Mappings     map[string]string `gorm:"type:jsonb;serializer:json" json:"mappings"`
+	UpdatedBy    string            `gorm:"not null;default:''" json:"updated_by"`
+	CreatedAt    time.Time         `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time         `gorm:"autoUpdateTime" json:"updated_at"`
+}
@@ -6,18 +6,71 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	UploadStatusPending                     = "pending"
+	UploadStatusAssigned                    = "assigned"
+	UploadStatusFeedback                    = "feedback"
+	UploadStatusFeedbackSubmitted           = "feedback_submitted"
+	UploadStatusFeedbackSubmittedAdvertiser = "feedback_submitted_advertiser"
+	UploadStatusReturnedToPublisher         = "returned_to_publisher"
+	UploadStatusSentToPublisherAdvertiser   = "sent_to_publisher_advertiser"
+	UploadStatusApproved                    = "approved"
+	UploadStatusRejected                    = "rejected"
+	UploadStatusCompleted                   = "completed"
+	UploadStatusAccessExpired               = "access_expired"
+	UploadStatusSuperseded                  = "superseded"
+)
+
+// Ergebnis der Inhaltsprüfung; leer bei Uploads aus der Zeit vor dem Scanner.
+const (
+	UploadScanStatusPending  = "pending"
+	UploadScanStatusClean    = "clean"
+	UploadScanStatusRejected = "rejected"
+	UploadScanStatusError    = "error"
+)
+
+// UploadScanFinding ist ein Befund eines Scanners (z. B. Makro, Formel-Injection, Virus).
+type UploadScanFinding struct {
+	Scanner string `json:"scanner"`
+	Message string `json:"message"`
+}
+
+// Herkunft eines Uploads; wird beim Anlegen gesetzt und bestimmt die Workflow-Sonderfälle.
+const (
+	UploadKindPublisherFile           = "publisher_file"
+	UploadKindPublisherManualRequest  = "publisher_manual_request"
+	UploadKindAdvertiserManualRequest = "advertiser_manual_request"
+	UploadKindAdminUpload             = "admin_upload"
+)
+
+// Upload ist eine hochgeladene Datei bzw. Manuellanfrage.
+// SLADueAt ist die berechnete Frist für den aktuellen Status (nil ohne passende SLA-Regel),
+// DuplicateOfUploadID verweist auf einen inhaltsgleichen Upload desselben Uploaders (UPLOAD_DUPLICATE_POLICY=flag).
+// SupersededByUploadID verweist bei zusammengeführten Quellen (Status "superseded") auf die neue Sammeldatei.
+// ParentUploadID verweist bei Teil-Uploads aus POST /api/uploads/:id/split auf die Ausgangsdatei.
+// Solange ScanStatus nicht "clean" (oder leer) ist, liegt die Datei in Quarantäne und wird nicht ausgeliefert.
 type Upload struct {
-	ID              uint           `gorm:"primaryKey" json:"id"`
-	Filename        string         `gorm:"not null" json:"filename"`
-	UploadDate      time.Time      `gorm:"autoCreateTime" json:"upload_date"`
-	FileSize        int64          `gorm:"not null" json:"file_size"`
-	ContentType     string         `gorm:"not null" json:"content_type"`
-	UploadedBy      string         `gorm:"not null" json:"uploaded_by"`
-	Status          string         `gorm:"default:'pending'" json:"status"`
-	FilePath        string         `gorm:"not null" json:"-"`
-	LastModifiedBy  string         `gorm:"not null;default:''" json:"last_modified_by"`
-	FeedbackMessage string         `gorm:"type:text;default:''" json:"feedback_message"`
-	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
-	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                   uint                `gorm:"primaryKey" json:"id"`
+	Filename             string              `gorm:"not null" json:"filename"`
+	UploadDate           time.Time           `gorm:"autoCreateTime" json:"upload_date"`
+	FileSize             int64               `gorm:"not null" json:"file_size"`
+	ContentType          string              `gorm:"not null" json:"content_type"`
+	UploadedBy           string              `gorm:"not null" json:"uploaded_by"`
+	Status               string              `gorm:"default:'pending'" json:"status"`
+	Kind                 string              `gorm:"not null;default:'';index" json:"kind"`
+	FilePath             string              `gorm:"not null" json:"-"`
+	SHA256               string              `gorm:"not null;default:'';index" json:"sha256"`
+	DuplicateOfUploadID  *uint               `gorm:"index" json:"duplicate_of_upload_id,omitempty"`
+	ParentUploadID       *uint               `gorm:"index" json:"parent_upload_id,omitempty"`
+	SupersededByUploadID *uint               `gorm:"index" json:"superseded_by_upload_id,omitempty"`
+	LastModifiedBy       string              `gorm:"not null;default:''" json:"last_modified_by"`
+	FeedbackMessage      string              `gorm:"type:text;default:''" json:"feedback_message"`
+	SLADueAt             *time.Time          `gorm:"index" json:"sla_due_at"`
+	ScanStatus           string              `gorm:"not null;default:'';index" json:"scan_status"`
+	ScanFindings         []UploadScanFinding `gorm:"type:jsonb;serializer:json" json:"scan_findings,omitempty"`
+	ScannedAt            *time.Time          `json:"scanned_at,omitempty"`
+	CreatedAt            time.Time           `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt            time.Time           `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt            gorm.DeletedAt      `gorm:"index" json:"-"`
+	DeletedBy            string              `gorm:"not null;default:''" json:"-"`
 }
@@ -2,10 +2,22 @@ package models
 
 import "time"
 
+// Bearbeitungsstand eines einzelnen Advertisers an einem geteilten Upload.
+const (
+	UploadAccessStatusAssigned  = "assigned"
+	UploadAccessStatusSubmitted = "submitted"
+	UploadAccessStatusCompleted = "completed"
+)
+
 type UploadAccess struct {
-	ID           uint `gorm:"primaryKey"`
-	UploadID     uint `gorm:"not null;index:idx_upload_access_upload_id;uniqueIndex:idx_upload_access_pair"`
-	AdvertiserID uint `gorm:"not null;index:idx_upload_access_advertiser_id;uniqueIndex:idx_upload_access_pair"`
-	ExpiresAt    *time.Time
-	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	ID             uint `gorm:"primaryKey"`
+	UploadID       uint `gorm:"not null;index:idx_upload_access_upload_id;uniqueIndex:idx_upload_access_pair"`
+	AdvertiserID   uint `gorm:"not null;index:idx_upload_access_advertiser_id;uniqueIndex:idx_upload_access_pair"`
+	ExpiresAt      *time.Time
+	Status         string `gorm:"not null;default:'assigned'"`
+	LastActivityAt *time.Time
+	// ExpiredAt wird vom Expiry-Processor gesetzt, sobald die abgelaufene Freigabe verarbeitet wurde.
+	ExpiredAt *time.Time `gorm:"index"`
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime"`
 }
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UploadComment ist ein Kommentar an einem Upload, optional an einer Zeile bzw. einem Ordertoken.
+// Antworten verweisen über ParentID auf den ersten Kommentar des Threads.
+type UploadComment struct {
+	ID           uint           `gorm:"primaryKey" json:"id"`
+	UploadID     uint           `gorm:"not null;index:idx_upload_comment_upload,priority:1" json:"upload_id"`
+	ParentID     *uint          `gorm:"index" json:"parent_id"`
+	RowNo        int            `gorm:"not null;default:0" json:"row_no,omitempty"`
+	OrderToken   string         `gorm:"not null;default:'';index:idx_upload_comment_upload,priority:2" json:"order_token,omitempty"`
+	AuthorUserID uint           `gorm:"not null;index" json:"author_user_id"`
+	AuthorEmail  string         `gorm:"not null" json:"author_email"`
+	AuthorRole   string         `gorm:"not null" json:"author_role"`
+	Body         string         `gorm:"type:text;not null" json:"body"`
+	EditedAt     *time.Time     `json:"edited_at"`
+	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// UploadCommentMention hält fest, welche User in einem Kommentar per @E-Mail erwähnt wurden.
+type UploadCommentMention struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CommentID uint      `gorm:"not null;uniqueIndex:idx_upload_comment_mention,priority:1" json:"comment_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_upload_comment_mention,priority:2;index" json:"user_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// UploadMergedRow ist die Herkunft einer Zeile eines zusammengeführten Uploads (POST /api/uploads/merge):
+// Datenzeile RowNo des Ziel-Uploads stammt aus Datenzeile SourceRowNo des Quell-Uploads (jeweils 1-basiert).
+type UploadMergedRow struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	MergedUploadID uint      `gorm:"not null;uniqueIndex:idx_upload_merged_row,priority:1" json:"merged_upload_id"`
+	RowNo          int       `gorm:"not null;uniqueIndex:idx_upload_merged_row,priority:2" json:"row_no"`
+	SourceUploadID uint      `gorm:"not null;index" json:"source_upload_id"`
+	SourceRowNo    int       `gorm:"not null" json:"source_row_no"`
+	OrderToken     string    `gorm:"not null;default:''" json:"ordertoken"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
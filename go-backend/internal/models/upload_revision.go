@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// UploadRevision ist ein unveränderlicher Stand der Upload-Datei. Jeder Schreibvorgang
+// (Bearbeiten, Ersetzen, Anhang, Wiederherstellen) legt eine neue Revision an.
+type UploadRevision struct {
+	ID                     uint      `gorm:"primaryKey" json:"id"`
+	UploadID               uint      `gorm:"not null;uniqueIndex:idx_upload_revision_no,priority:1" json:"upload_id"`
+	RevisionNo             int       `gorm:"not null;uniqueIndex:idx_upload_revision_no,priority:2" json:"revision_no"`
+	FilePath               string    `gorm:"not null" json:"-"`
+	Filename               string    `gorm:"not null" json:"filename"`
+	ContentType            string    `gorm:"not null;default:''" json:"content_type"`
+	FileSize               int64     `gorm:"not null" json:"file_size"`
+	SHA256                 string    `gorm:"not null;default:''" json:"sha256"`
+	AuthorUserID           *uint     `gorm:"index" json:"author_user_id"`
+	AuthorEmail            string    `gorm:"not null;default:''" json:"author_email"`
+	AuthorRole             string    `gorm:"not null;default:''" json:"author_role"`
+	Reason                 string    `gorm:"not null;default:''" json:"reason"`
+	RestoredFromRevisionID *uint     `json:"restored_from_revision_id"`
+	CreatedAt              time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+const (
+	UploadSessionStatusOpen       = "open"
+	UploadSessionStatusFinalizing = "finalizing"
+	UploadSessionStatusFinalized  = "finalized"
+	UploadSessionStatusAborted    = "aborted"
+)
+
+// UploadSession ist ein fortsetzbarer Upload in Teilstücken (tus-artig): Die Datei wächst unter
+// TempPath bis Offset == Size und wird beim Abschluss zu einem regulären Upload.
+type UploadSession struct {
+	ID          string     `gorm:"primaryKey;size:64" json:"id"`
+	OwnerUserID *uint      `gorm:"index" json:"owner_user_id"`
+	OwnerEmail  string     `gorm:"not null;index" json:"owner_email"`
+	Filename    string     `gorm:"not null" json:"filename"`
+	ContentType string     `gorm:"not null;default:''" json:"content_type"`
+	Size        int64      `gorm:"not null" json:"size"`
+	Offset      int64      `gorm:"not null;default:0" json:"offset"`
+	TempPath    string     `gorm:"not null" json:"-"`
+	Status      string     `gorm:"not null;default:'open';index" json:"status"`
+	UploadID    *uint      `json:"upload_id"`
+	ExpiresAt   time.Time  `gorm:"not null;index" json:"expires_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// UploadSLAPolicy legt fest, wie lange ein Upload in einem Status liegen darf.
+// AdvertiserID 0 ist der Standard für alle Advertiser, sonst gilt die Regel nur für Uploads dieses Advertisers.
+type UploadSLAPolicy struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	Status        string    `gorm:"not null;uniqueIndex:idx_upload_sla_policy_scope" json:"status"`
+	AdvertiserID  uint      `gorm:"not null;default:0;uniqueIndex:idx_upload_sla_policy_scope" json:"advertiser_id"`
+	DurationHours int       `gorm:"not null" json:"duration_hours"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// UploadSLAEscalation protokolliert eine SLA-Überschreitung; pro Statusaufenthalt höchstens einmal.
+type UploadSLAEscalation struct {
+	ID                 uint      `gorm:"primaryKey" json:"id"`
+	UploadID           uint      `gorm:"not null;index;uniqueIndex:idx_upload_sla_escalation_visit" json:"upload_id"`
+	Status             string    `gorm:"not null;uniqueIndex:idx_upload_sla_escalation_visit" json:"status"`
+	StatusTransitionID uint      `gorm:"not null;default:0;uniqueIndex:idx_upload_sla_escalation_visit" json:"status_transition_id"`
+	DueAt              time.Time `gorm:"not null" json:"due_at"`
+	DetectedAt         time.Time `gorm:"not null" json:"detected_at"`
+	CreatedAt          time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// UploadStatusTransition protokolliert jeden Statuswechsel eines Uploads (wer, wann, warum).
+type UploadStatusTransition struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	UploadID    uint      `gorm:"not null;index:idx_upload_status_transition_time,priority:1" json:"upload_id"`
+	FromStatus  string    `gorm:"not null;default:''" json:"from_status"`
+	ToStatus    string    `gorm:"not null" json:"to_status"`
+	ActorUserID *uint     `gorm:"index" json:"actor_user_id"`
+	ActorEmail  string    `gorm:"not null;default:''" json:"actor_email"`
+	ActorRole   string    `gorm:"not null;default:''" json:"actor_role"`
+	Reason      string    `gorm:"type:text;default:''" json:"reason"`
+	OccurredAt  time.Time `gorm:"autoCreateTime;index:idx_upload_status_transition_time,priority:2" json:"occurred_at"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
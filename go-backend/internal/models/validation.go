@@ -8,10 +8,23 @@ const (
 	CellEmpty   CellStatus = "empty"
 )
 
+// ValidatedCell ist eine geprüfte Zelle. Value bleibt immer der Wert aus der Datei; wurde der Ordertoken einer
+// Order zugeordnet, stehen der Netzwerk-Token in MatchedValue und die Trefferart in Match.
 type ValidatedCell struct {
-	Value  string     `json:"value"`
-	Status CellStatus `json:"status"`
-	Note   string     `json:"note,omitempty"`
+	Value        string            `json:"value"`
+	Status       CellStatus        `json:"status"`
+	Note         string            `json:"note,omitempty"`
+	MatchedValue string            `json:"matchedValue,omitempty"`
+	Match        string            `json:"match,omitempty"`
+	Suggestions  []ValueSuggestion `json:"suggestions,omitempty"`
+}
+
+// ValueSuggestion ist ein Korrekturvorschlag für einen ungültigen Zellwert ("Meinten Sie …?"); Confidence liegt
+// zwischen 0 und 1, Reason nennt die Art des Treffers (z. B. "prefix", "edit_distance").
+type ValueSuggestion struct {
+	Value      string  `json:"value"`
+	Confidence float64 `json:"confidence"`
+	Reason     string  `json:"reason"`
 }
 
 type ValidatedRow struct {
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Zustand eines Validierungsjobs.
+const (
+	ValidationJobQueued    = "queued"
+	ValidationJobRunning   = "running"
+	ValidationJobSucceeded = "succeeded"
+	ValidationJobFailed    = "failed"
+	ValidationJobCancelled = "cancelled"
+)
+
+// Phase eines laufenden Validierungsjobs.
+const (
+	ValidationPhaseQueued   = "queued"
+	ValidationPhaseLoading  = "loading"
+	ValidationPhaseSyncing  = "syncing"
+	ValidationPhaseMatching = "matching"
+	ValidationPhaseSaving   = "saving"
+	ValidationPhaseDone     = "done"
+)
+
+// ValidationJob ist eine im Hintergrund laufende Validierung eines Uploads. Der Zustand liegt in der DB,
+// damit Jobs einen Neustart überleben: laufende Jobs ohne aktuellen HeartbeatAt werden erneut eingereiht.
+type ValidationJob struct {
+	ID              uint             `gorm:"primaryKey" json:"id"`
+	UploadID        uint             `gorm:"not null;index" json:"upload_id"`
+	ResultID        *uint            `json:"result_id"`
+	Params          ValidationParams `gorm:"type:jsonb;serializer:json" json:"params"`
+	Status          string           `gorm:"not null;default:'queued';index" json:"status"`
+	Phase           string           `gorm:"not null;default:'queued'" json:"phase"`
+	RowsTotal       int              `gorm:"not null;default:0" json:"rows_total"`
+	RowsDone        int              `gorm:"not null;default:0" json:"rows_done"`
+	OrdersCount     int              `gorm:"not null;default:0" json:"orders_count"`
+	Error           string           `gorm:"type:text;not null;default:''" json:"error"`
+	ErrorDetail     string           `gorm:"type:text;not null;default:''" json:"error_detail"`
+	CancelRequested bool             `gorm:"not null;default:false" json:"cancel_requested"`
+	Attempts        int              `gorm:"not null;default:0" json:"attempts"`
+	RequestedBy     string           `gorm:"not null;default:''" json:"requested_by"`
+	HeartbeatAt     *time.Time       `json:"heartbeat_at"`
+	StartedAt       *time.Time       `json:"started_at"`
+	FinishedAt      *time.Time       `json:"finished_at"`
+	CreatedAt       time.Time        `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time        `gorm:"autoUpdateTime" json:"updated_at"`
+}
@@ -6,14 +6,31 @@ import (
 	"gorm.io/gorm"
 )
 
-// ValidationResult speichert die Validierungsergebnisse für einen Upload
+// ValidationParams sind die Parameter eines Validierungslaufs (Query von GET /validate bzw. Body von POST /validate).
+type ValidationParams struct {
+	CampaignID        string `json:"campaignId"`
+	ProjectID         string `json:"projectId,omitempty"`
+	PublisherID       string `json:"publisherId,omitempty"`
+	CommissionGroupID string `json:"commissionGroupId,omitempty"`
+	TriggerID         string `json:"triggerId,omitempty"`
+	ForceRefresh      bool   `json:"forceRefresh,omitempty"`
+}
+
+// ValidationResult ist ein Validierungslauf für einen Upload. Jeder Lauf bleibt mit seinen Parametern
+// erhalten; der neueste gilt als aktuelles Ergebnis. OrdersCount ist die Größe des Order-Snapshots.
 type ValidationResult struct {
-	ID            uint           `gorm:"primaryKey" json:"id"`
-	UploadID      uint           `gorm:"not null;uniqueIndex" json:"upload_id"`
-	OrdersCount   int            `gorm:"not null" json:"orders_count"`
-	ValidatedRows []ValidatedRow `gorm:"type:jsonb;serializer:json" json:"rows"`
-	ValidatedAt   time.Time      `gorm:"autoCreateTime" json:"validated_at"`
-	CreatedAt     time.Time      `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt     time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
-	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+	ID            uint             `gorm:"primaryKey" json:"id"`
+	UploadID      uint             `gorm:"not null;index:idx_validation_results_upload_run" json:"upload_id"`
+	JobID         *uint            `gorm:"index" json:"job_id,omitempty"`
+	Params        ValidationParams `gorm:"type:jsonb;serializer:json" json:"params"`
+	OrdersCount   int              `gorm:"not null" json:"orders_count"`
+	RowsCount     int              `gorm:"not null;default:0" json:"rows_count"`
+	InvalidCount  int              `gorm:"not null;default:0" json:"invalid_count"`
+	EmptyCount    int              `gorm:"not null;default:0" json:"empty_count"`
+	ValidatedBy   string           `gorm:"not null;default:''" json:"validated_by"`
+	ValidatedRows []ValidatedRow   `gorm:"type:jsonb;serializer:json" json:"rows"`
+	ValidatedAt   time.Time        `gorm:"autoCreateTime" json:"validated_at"`
+	CreatedAt     time.Time        `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time        `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt     gorm.DeletedAt   `gorm:"index" json:"-"`
 }
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"strconv"
+
+	"nba-dashboard/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// CreateAuditEvent schreibt einen Audit-Eintrag innerhalb der übergebenen Transaktion.
+func CreateAuditEvent(tx *gorm.DB, actorUserID *uint, action string, entityType string, entityID uint, requestID string, before map[string]any, after map[string]any, metadata map[string]any) error {
+	event := models.AuditEvent{
+		ActorUserID: actorUserID,
+		Action:      action,
+		EntityType:  entityType,
+		EntityID:    strconv.FormatUint(uint64(entityID), 10),
+		RequestID:   requestID,
+		BeforeData:  before,
+		AfterData:   after,
+		Metadata:    metadata,
+	}
+	return tx.Create(&event).Error
+}
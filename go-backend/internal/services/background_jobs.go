@@ -0,0 +1,35 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// backgroundJobs zählt die periodischen Hintergrundjobs (Freigabe-Ablauf, SLA, Papierkorb, Sessions),
+// damit der Shutdown ihren laufenden Durchlauf abwarten kann.
+var backgroundJobs sync.WaitGroup
+
+// goBackgroundJob startet run als Hintergrundjob; run muss mit dem übergebenen App-Kontext enden.
+func goBackgroundJob(run func()) {
+	backgroundJobs.Add(1)
+	go func() {
+		defer backgroundJobs.Done()
+		run()
+	}()
+}
+
+// WaitBackgroundJobs wartet nach dem Ende des App-Kontexts, bis alle Hintergrundjobs ihren laufenden
+// Durchlauf beendet haben, höchstens bis ctx abläuft.
+func WaitBackgroundJobs(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		backgroundJobs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Printf("⚠️ background jobs did not stop in time")
+	}
+}
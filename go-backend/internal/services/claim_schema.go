@@ -0,0 +1,269 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"nba-dashboard/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidClaimSchema meldet ein Schema mit leeren/doppelten Spalten, unbekanntem Typ oder ungültigem Geltungsbereich.
+var ErrInvalidClaimSchema = errors.New("invalid claim schema")
+
+// ErrManualRequestRowWidth meldet Zeilen einer Manuellanfrage, deren Breite nicht zum Schema passt.
+var ErrManualRequestRowWidth = errors.New("manual request row does not match the claim schema")
+
+const maxClaimSchemaColumns = 100
+
+// DefaultClaimColumns ist die einzige Definition der Spalten einer Nachbuchungsdatei. Daraus entstehen
+// die Pflichtfelder der Validierung, der Header von Manuellanfragen und die Vorlagen (/api/templates).
+// Die Reihenfolge entspricht der Spaltenreihenfolge in Vorlagen und Manuellanfragen.
+var DefaultClaimColumns = []models.ClaimColumn{
+	{Name: "Publisher ID", Required: true, Type: models.ClaimColumnTypeText, Description: "ID des Publishers im Netzwerk", Example: "12345"},
+	{Name: "Vollständiger Name des Endkunden", Required: true, Type: models.ClaimColumnTypeText, Description: "Vor- und Nachname", Example: "Max Mustermann", Role: models.ClaimColumnRoleCustomerName},
+	{Name: "Adresse des Endkunden", Required: true, Type: models.ClaimColumnTypeText, Description: "Straße, Hausnummer, PLZ und Ort", Example: "Musterstraße 1, 10115 Berlin", Role: models.ClaimColumnRoleCustomerAddress},
+	{Name: "E-Mailadresse des Endkunden", Required: true, Type: models.ClaimColumnTypeEmail, Example: "max.mustermann@example.com"},
+	{Name: "Sonstige Daten/Dokumente des Endkunden (Optional)", Type: models.ClaimColumnTypeText, Description: "z. B. Kundennummer oder Link zum Beleg"},
+	{Name: "Höhe der Provision (Optional)", Type: models.ClaimColumnTypeDecimal, Description: "Erwartete Provision in Euro", Example: "25,00"},
+	{Name: "Grund der Anfrage", Required: true, Type: models.ClaimColumnTypeText, Description: "Warum die Bestellung nachgebucht werden soll", Example: "Tracking-Ausfall"},
+	{Name: "Timestamp", Required: true, Type: models.ClaimColumnTypeDateTime, Description: "Zeitpunkt der Bestellung (TT.MM.JJJJ hh:mm)", Example: "01.03.2026 14:30"},
+	{Name: "SubID", Required: true, Type: models.ClaimColumnTypeText, Description: "SubID aus dem Tracking-Link", Example: "sub-abc-001"},
+	{Name: "Ordertoken/OrderID", Required: true, Type: models.ClaimColumnTypeText, Description: "Bestellnummer bzw. Ordertoken beim Advertiser", Example: "BEISPIEL-100001"},
+	{Name: "Ordertoken/Order ID", Type: models.ClaimColumnTypeText, Description: "Alternative Schreibweise, nur für ältere Dateien"},
+	{Name: "Abgeschlossener Tarif", Type: models.ClaimColumnTypeText, Description: "Bei Energie/Telko: gebuchter Tarif"},
+	{Name: "Belieferungsbeginn (Optional)", Type: models.ClaimColumnTypeDate, Description: "Bei Energie: Lieferbeginn (TT.MM.JJJJ)"},
+	{Name: "Feedback", Type: models.ClaimColumnTypeText, Description: "Wird vom Advertiser ausgefüllt"},
+	{Name: "Status in der uppr Performance Platform", Type: models.ClaimColumnTypeText, Description: "Wird vom Netzwerk ausgefüllt"},
+	{Name: "Sonstiges Feedback", Type: models.ClaimColumnTypeText, Description: "Wird vom Advertiser ausgefüllt"},
+}
+
+// ClaimColumnNames liefert die Header-Texte eines Schemas in Spaltenreihenfolge.
+func ClaimColumnNames(columns []models.ClaimColumn) []string {
+	names := make([]string, 0, len(columns))
+	for _, col := range columns {
+		names = append(names, col.Name)
+	}
+	return names
+}
+
+// RequiredClaimColumnNames liefert die Header-Texte der Pflichtspalten eines Schemas.
+func RequiredClaimColumnNames(columns []models.ClaimColumn) []string {
+	names := make([]string, 0, len(columns))
+	for _, col := range columns {
+		if col.Required {
+			names = append(names, col.Name)
+		}
+	}
+	return names
+}
+
+// ResolvedClaimSchema ist das für einen Advertiser/eine Kampagne gültige Schema.
+// SchemaID ist nil, wenn die eingebauten Standardspalten gelten.
+type ResolvedClaimSchema struct {
+	SchemaID           *uint                     `json:"schemaId"`
+	Name               string                    `json:"name"`
+	AdvertiserID       uint                      `json:"advertiserId"`
+	CampaignExternalID string                    `json:"campaignId"`
+	Columns            []models.ClaimColumn      `json:"columns"`
+	Checks             models.CustomerDataChecks `json:"checks"`
+}
+
+// ColumnNames liefert die Header-Texte des Schemas (z. B. für lib.FindHeaderRow).
+func (s ResolvedClaimSchema) ColumnNames() []string {
+	return ClaimColumnNames(s.Columns)
+}
+
+// ManualRequestRows prüft die Zeilen einer Manuellanfrage gegen das Schema: jede Zeile muss genau so viele
+// Zellen haben wie das Schema Spalten, sonst stünden Werte unter falschen Headern. Zellen werden getrimmt,
+// leere Zeilen entfallen.
+func (s ResolvedClaimSchema) ManualRequestRows(rows [][]string) ([][]string, error) {
+	out := make([][]string, 0, len(rows))
+	for i, row := range rows {
+		if len(row) != len(s.Columns) {
+			return nil, fmt.Errorf("%w: row %d has %d columns, schema %q has %d", ErrManualRequestRowWidth, i+1, len(row), s.Name, len(s.Columns))
+		}
+		normalized := make([]string, len(row))
+		hasContent := false
+		for j, cell := range row {
+			normalized[j] = strings.TrimSpace(cell)
+			hasContent = hasContent || normalized[j] != ""
+		}
+		if hasContent {
+			out = append(out, normalized)
+		}
+	}
+	return out, nil
+}
+
+// ResolveClaimSchema wählt das speziellste Schema: Kampagne+Advertiser, Kampagne, Advertiser, globaler
+// Standard aus der DB, sonst DefaultClaimColumns. Bei mehreren Advertisern gewinnt die kleinste ID.
+func ResolveClaimSchema(db *gorm.DB, advertiserIDs []uint, campaignID string) (ResolvedClaimSchema, error) {
+	campaignID = strings.TrimSpace(campaignID)
+	resolved := ResolvedClaimSchema{Name: "Standard", Columns: DefaultClaimColumns, Checks: DefaultCustomerDataChecks}
+
+	var schemas []models.ClaimSchema
+	if err := db.Where("advertiser_id IN ? AND campaign_external_id IN ?", append(slices.Clone(advertiserIDs), 0), []string{campaignID, ""}).
+		Order("advertiser_id ASC, id ASC").
+		Find(&schemas).Error; err != nil {
+		return resolved, err
+	}
+
+	best, bestScore := -1, -1
+	for i, schema := range schemas {
+		score := 0
+		if campaignID != "" && schema.CampaignExternalID == campaignID {
+			score += 2
+		}
+		if schema.AdvertiserID > 0 {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 || len(schemas[best].Columns) == 0 {
+		return resolved, nil
+	}
+	schema := schemas[best]
+	checks := DefaultCustomerDataChecks
+	if schema.Checks != nil {
+		checks = *schema.Checks
+	}
+	return ResolvedClaimSchema{
+		SchemaID:           &schema.ID,
+		Name:               schema.Name,
+		AdvertiserID:       schema.AdvertiserID,
+		CampaignExternalID: schema.CampaignExternalID,
+		Columns:            schema.Columns,
+		Checks:             checks,
+	}, nil
+}
+
+// ResolveUploadClaimSchema löst das Schema für die Advertiser eines Uploads (Freigaben bzw. Ersteller
+// einer Advertiser-Manuellanfrage) und optional eine Kampagne auf.
+func ResolveUploadClaimSchema(db *gorm.DB, upload models.Upload, campaignID string) (ResolvedClaimSchema, error) {
+	advertiserIDs, err := UploadAdvertiserIDs(db, upload, time.Now())
+	if err != nil {
+		return ResolvedClaimSchema{Columns: DefaultClaimColumns, Checks: DefaultCustomerDataChecks}, err
+	}
+	return ResolveClaimSchema(db, advertiserIDs, campaignID)
+}
+
+// ListClaimSchemas liefert alle gespeicherten Schemas, globale zuerst.
+func ListClaimSchemas(db *gorm.DB) ([]models.ClaimSchema, error) {
+	schemas := []models.ClaimSchema{}
+	err := db.Order("advertiser_id ASC, campaign_external_id ASC").Find(&schemas).Error
+	return schemas, err
+}
+
+// NormalizeClaimColumns prüft die Spalten eines Schemas und bereinigt Namen, Typen und erlaubte Werte.
+func NormalizeClaimColumns(columns []models.ClaimColumn) ([]models.ClaimColumn, error) {
+	if len(columns) == 0 || len(columns) > maxClaimSchemaColumns {
+		return nil, ErrInvalidClaimSchema
+	}
+	out := make([]models.ClaimColumn, 0, len(columns))
+	seen := map[string]bool{}
+	seenRoles := map[string]bool{}
+	for _, col := range columns {
+		col.Name = strings.TrimSpace(col.Name)
+		col.Type = strings.ToLower(strings.TrimSpace(col.Type))
+		if col.Type == "" {
+			col.Type = models.ClaimColumnTypeText
+		}
+		if col.Name == "" || seen[col.Name] {
+			return nil, ErrInvalidClaimSchema
+		}
+		seen[col.Name] = true
+		switch col.Type {
+		case models.ClaimColumnTypeText, models.ClaimColumnTypeEmail, models.ClaimColumnTypeDateTime,
+			models.ClaimColumnTypeDate, models.ClaimColumnTypeDecimal:
+			col.AllowedValues = nil
+		case models.ClaimColumnTypeEnum:
+			values := make([]string, 0, len(col.AllowedValues))
+			for _, v := range col.AllowedValues {
+				if v = strings.TrimSpace(v); v != "" {
+					values = append(values, v)
+				}
+			}
+			if len(values) == 0 {
+				return nil, ErrInvalidClaimSchema
+			}
+			col.AllowedValues = values
+		default:
+			return nil, ErrInvalidClaimSchema
+		}
+		// Jede Rolle darf nur eine Spalte tragen.
+		col.Role = strings.ToLower(strings.TrimSpace(col.Role))
+		switch col.Role {
+		case "":
+		case models.ClaimColumnRoleCustomerName, models.ClaimColumnRoleCustomerAddress:
+			if seenRoles[col.Role] {
+				return nil, ErrInvalidClaimSchema
+			}
+			seenRoles[col.Role] = true
+		default:
+			return nil, ErrInvalidClaimSchema
+		}
+		col.Description = strings.TrimSpace(col.Description)
+		col.Example = strings.TrimSpace(col.Example)
+		out = append(out, col)
+	}
+	return out, nil
+}
+
+// UpsertClaimSchema legt das Schema für Advertiser+Kampagne an oder ersetzt dessen Spalten.
+func UpsertClaimSchema(tx *gorm.DB, schema models.ClaimSchema) (models.ClaimSchema, error) {
+	columns, err := NormalizeClaimColumns(schema.Columns)
+	if err != nil {
+		return schema, err
+	}
+	schema.Columns = columns
+	schema.Name = strings.TrimSpace(schema.Name)
+	schema.CampaignExternalID = strings.TrimSpace(schema.CampaignExternalID)
+	if schema.AdvertiserID > 0 {
+		if err := ValidateUploadAccessAssignments(tx, []UploadAccessAssignment{{AdvertiserID: schema.AdvertiserID}}); err != nil {
+			if errors.Is(err, ErrInvalidAdvertiserSelection) {
+				return schema, ErrInvalidClaimSchema
+			}
+			return schema, err
+		}
+	}
+	if schema.CampaignExternalID != "" {
+		var count int64
+		if err := tx.Model(&models.Campaign{}).Where("external_campaign_id = ?", schema.CampaignExternalID).Count(&count).Error; err != nil {
+			return schema, err
+		}
+		if count == 0 {
+			return schema, ErrInvalidClaimSchema
+		}
+	}
+
+	schema.ID = 0
+	err = tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "advertiser_id"}, {Name: "campaign_external_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "columns", "checks", "updated_by", "updated_at"}),
+	}).Create(&schema).Error
+	if err != nil {
+		return schema, err
+	}
+	err = tx.Where("advertiser_id = ? AND campaign_external_id = ?", schema.AdvertiserID, schema.CampaignExternalID).First(&schema).Error
+	return schema, err
+}
+
+// DeleteClaimSchema entfernt ein Schema; danach greift wieder das nächstallgemeinere.
+func DeleteClaimSchema(tx *gorm.DB, schemaID uint) error {
+	result := tx.Delete(&models.ClaimSchema{}, schemaID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
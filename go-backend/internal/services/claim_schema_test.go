@@ -0,0 +1,115 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"nba-dashboard/internal/models"
+)
+
+func TestManualRequestRows(t *testing.T) {
+	schema := ResolvedClaimSchema{Name: "Energie", Columns: []models.ClaimColumn{
+		{Name: "Publisher ID"},
+		{Name: "Ordertoken/OrderID"},
+		{Name: "Tarif"},
+	}}
+
+	tests := []struct {
+		name    string
+		rows    [][]string
+		want    [][]string
+		wantErr bool
+	}{
+		{
+			name: "zellen werden getrimmt, leere zeilen entfallen",
+			rows: [][]string{{" 12345 ", "BEISPIEL-1", "Öko"}, {"", " ", ""}, {"12345", "BEISPIEL-2", ""}},
+			want: [][]string{{"12345", "BEISPIEL-1", "Öko"}, {"12345", "BEISPIEL-2", ""}},
+		},
+		{
+			name:    "zu kurze zeile",
+			rows:    [][]string{{"12345", "BEISPIEL-1", "Öko"}, {"12345", "BEISPIEL-2"}},
+			wantErr: true,
+		},
+		{
+			name:    "zu lange zeile (alte 16-spalten-reihenfolge)",
+			rows:    [][]string{make([]string, len(DefaultClaimColumns))},
+			wantErr: true,
+		},
+		{
+			name:    "leere zeile mit falscher breite",
+			rows:    [][]string{{""}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := schema.ManualRequestRows(tt.rows)
+			if tt.wantErr {
+				if !errors.Is(err, ErrManualRequestRowWidth) {
+					t.Fatalf("err = %v, want ErrManualRequestRowWidth", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rows = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeClaimColumnsRoles(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []models.ClaimColumn
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "rollen werden normalisiert",
+			columns: []models.ClaimColumn{
+				{Name: "Kunde", Type: models.ClaimColumnTypeText, Role: " Customer_Name "},
+				{Name: "Anschrift", Type: models.ClaimColumnTypeText, Role: models.ClaimColumnRoleCustomerAddress},
+				{Name: "Tarif", Type: models.ClaimColumnTypeText},
+			},
+			want: []string{models.ClaimColumnRoleCustomerName, models.ClaimColumnRoleCustomerAddress, ""},
+		},
+		{
+			name: "rolle doppelt vergeben",
+			columns: []models.ClaimColumn{
+				{Name: "Kunde", Type: models.ClaimColumnTypeText, Role: models.ClaimColumnRoleCustomerName},
+				{Name: "Ansprechpartner", Type: models.ClaimColumnTypeText, Role: models.ClaimColumnRoleCustomerName},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "unbekannte rolle",
+			columns: []models.ClaimColumn{{Name: "Kunde", Type: models.ClaimColumnTypeText, Role: "customer_email"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeClaimColumns(tt.columns)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidClaimSchema) {
+					t.Fatalf("err = %v, want ErrInvalidClaimSchema", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			roles := make([]string, 0, len(got))
+			for _, col := range got {
+				roles = append(roles, col.Role)
+			}
+			if !reflect.DeepEqual(roles, tt.want) {
+				t.Errorf("roles = %q, want %q", roles, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"time"
+
+	"nba-dashboard/internal/models"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	claimTemplateSheet         = "Vorlage"
+	claimTemplateExamplesSheet = "Beispiele"
+	claimTemplateNotesSheet    = "Hinweise"
+	// Zeilen der Vorlage, für die Formate und Datenüberprüfung gesetzt werden.
+	claimTemplateRows = 1000
+)
+
+// ClaimExampleRow liefert die Beispielzeile eines Schemas (leere Zelle ohne Beispielwert).
+func ClaimExampleRow(columns []models.ClaimColumn) []string {
+	row := make([]string, len(columns))
+	for i, col := range columns {
+		row[i] = col.Example
+		if row[i] == "" && col.Type == models.ClaimColumnTypeEnum && len(col.AllowedValues) > 0 {
+			row[i] = col.AllowedValues[0]
+		}
+	}
+	return row
+}
+
+// BuildClaimTemplateCSV erzeugt eine CSV-Vorlage (Semikolon wie beim Speichern) mit Header und Beispielzeile.
+func BuildClaimTemplateCSV(columns []models.ClaimColumn) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Comma = ';'
+	if err := writer.WriteAll([][]string{ClaimColumnNames(columns), ClaimExampleRow(columns)}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// BuildClaimTemplateXLSX erzeugt eine Excel-Vorlage: erstes Blatt mit Header (Pflichtspalten hervorgehoben
+// und kommentiert), Zahlen-/Datumsformaten und Datenüberprüfung; dazu ein Blatt mit Beispielzeile und eines
+// mit Hinweisen je Spalte. Hochgeladen wird nur das erste Blatt.
+func BuildClaimTemplateXLSX(columns []models.ClaimColumn, title string) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName("Sheet1", claimTemplateSheet); err != nil {
+		return nil, err
+	}
+	if _, err := f.NewSheet(claimTemplateExamplesSheet); err != nil {
+		return nil, err
+	}
+	if _, err := f.NewSheet(claimTemplateNotesSheet); err != nil {
+		return nil, err
+	}
+
+	styles, err := newClaimTemplateStyles(f)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, col := range columns {
+		colName, err := excelize.ColumnNumberToName(i + 1)
+		if err != nil {
+			return nil, err
+		}
+		header := colName + "1"
+		headerStyle := styles.optionalHeader
+		if col.Required {
+			headerStyle = styles.requiredHeader
+		}
+		for _, sheet := range []string{claimTemplateSheet, claimTemplateExamplesSheet} {
+			if err := f.SetCellValue(sheet, header, col.Name); err != nil {
+				return nil, err
+			}
+			if err := f.SetCellStyle(sheet, header, header, headerStyle); err != nil {
+				return nil, err
+			}
+			if err := f.SetColWidth(sheet, colName, colName, claimColumnWidth(col)); err != nil {
+				return nil, err
+			}
+		}
+		if col.Required {
+			if err := f.AddComment(claimTemplateSheet, excelize.Comment{
+				Cell:   header,
+				Author: "NBA-Dashboard",
+				Text:   strings.TrimSpace("Pflichtfeld. " + col.Description),
+			}); err != nil {
+				return nil, err
+			}
+		}
+
+		dataRange := fmt.Sprintf("%s2:%s%d", colName, colName, claimTemplateRows+1)
+		if style, ok := styles.byType[col.Type]; ok {
+			if err := f.SetCellStyle(claimTemplateSheet, colName+"2", fmt.Sprintf("%s%d", colName, claimTemplateRows+1), style); err != nil {
+				return nil, err
+			}
+		}
+		if dv := claimColumnValidation(col, colName, dataRange); dv != nil {
+			if err := f.AddDataValidation(claimTemplateSheet, dv); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := setClaimExampleCell(f, col, colName+"2", styles); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := f.SetPanes(claimTemplateSheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return nil, err
+	}
+	if err := writeClaimTemplateNotes(f, columns, title, styles); err != nil {
+		return nil, err
+	}
+	f.SetActiveSheet(0)
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type claimTemplateStyles struct {
+	requiredHeader int
+	optionalHeader int
+	byType         map[string]int
+}
+
+func newClaimTemplateStyles(f *excelize.File) (claimTemplateStyles, error) {
+	var styles claimTemplateStyles
+	var err error
+	styles.requiredHeader, err = f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FCD5B4"}},
+	})
+	if err != nil {
+		return styles, err
+	}
+	styles.optionalHeader, err = f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"E7E6E6"}},
+	})
+	if err != nil {
+		return styles, err
+	}
+	formats := map[string]string{
+		models.ClaimColumnTypeDateTime: "dd.mm.yyyy hh:mm",
+		models.ClaimColumnTypeDate:     "dd.mm.yyyy",
+		models.ClaimColumnTypeDecimal:  "#,##0.00",
+	}
+	styles.byType = map[string]int{}
+	for colType, format := range formats {
+		numFmt := format
+		style, err := f.NewStyle(&excelize.Style{CustomNumFmt: &numFmt})
+		if err != nil {
+			return styles, err
+		}
+		styles.byType[colType] = style
+	}
+	return styles, nil
+}
+
+// claimColumnValidation baut die Excel-Datenüberprüfung für eine Spalte (nil, wenn es keine gibt).
+func claimColumnValidation(col models.ClaimColumn, colName string, dataRange string) *excelize.DataValidation {
+	dv := excelize.NewDataValidation(true)
+	dv.Sqref = dataRange
+	switch col.Type {
+	case models.ClaimColumnTypeEmail:
+		dv.Type = "custom"
+		dv.Formula1 = fmt.Sprintf(`ISNUMBER(SEARCH("@",%s2))`, colName)
+		dv.SetError(excelize.DataValidationErrorStyleStop, "Ungültige E-Mail", "Bitte eine E-Mail-Adresse eingeben.")
+	case models.ClaimColumnTypeDate, models.ClaimColumnTypeDateTime:
+		// Excel-Datumswerte vom 01.01.2000 bis 31.12.2099.
+		if err := dv.SetRange(36526, 73415, excelize.DataValidationTypeDate, excelize.DataValidationOperatorBetween); err != nil {
+			return nil
+		}
+		dv.SetError(excelize.DataValidationErrorStyleStop, "Ungültiges Datum", "Bitte ein Datum im Format TT.MM.JJJJ eingeben.")
+	case models.ClaimColumnTypeDecimal:
+		if err := dv.SetRange(0, 1000000, excelize.DataValidationTypeDecimal, excelize.DataValidationOperatorBetween); err != nil {
+			return nil
+		}
+		dv.SetError(excelize.DataValidationErrorStyleStop, "Ungültiger Betrag", "Bitte einen Betrag ohne Währungszeichen eingeben.")
+	case models.ClaimColumnTypeEnum:
+		if len(col.AllowedValues) == 0 || dv.SetDropList(col.AllowedValues) != nil {
+			return nil
+		}
+		dv.SetError(excelize.DataValidationErrorStyleStop, "Ungültiger Wert", "Bitte einen Wert aus der Liste wählen.")
+	default:
+		if col.Description == "" {
+			return nil
+		}
+		dv.Type = "none"
+	}
+	if col.Description != "" {
+		dv.SetInput(col.Name, col.Description)
+	}
+	return dv
+}
+
+func setClaimExampleCell(f *excelize.File, col models.ClaimColumn, cell string, styles claimTemplateStyles) error {
+	value := ClaimExampleRow([]models.ClaimColumn{col})[0]
+	if value == "" {
+		return nil
+	}
+	var typed any = value
+	switch col.Type {
+	case models.ClaimColumnTypeDateTime:
+		if t, err := time.Parse("02.01.2006 15:04", value); err == nil {
+			typed = t
+		}
+	case models.ClaimColumnTypeDate:
+		if t, err := time.Parse("02.01.2006", value); err == nil {
+			typed = t
+		}
+	}
+	if err := f.SetCellValue(claimTemplateExamplesSheet, cell, typed); err != nil {
+		return err
+	}
+	if style, ok := styles.byType[col.Type]; ok {
+		return f.SetCellStyle(claimTemplateExamplesSheet, cell, cell, style)
+	}
+	return nil
+}
+
+func writeClaimTemplateNotes(f *excelize.File, columns []models.ClaimColumn, title string, styles claimTemplateStyles) error {
+	rows := [][]any{
+		{title},
+		{"Bitte nur das Blatt \"" + claimTemplateSheet + "\" ausfüllen und die Spaltenüberschriften nicht ändern. Orange markierte Spalten sind Pflichtfelder."},
+		{},
+		{"Spalte", "Pflicht", "Typ", "Erlaubte Werte", "Beschreibung", "Beispiel"},
+	}
+	for _, col := range columns {
+		required := "nein"
+		if col.Required {
+			required = "ja"
+		}
+		rows = append(rows, []any{col.Name, required, col.Type, strings.Join(col.AllowedValues, ", "), col.Description, col.Example})
+	}
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+		if err := f.SetSheetRow(claimTemplateNotesSheet, cell, &row); err != nil {
+			return err
+		}
+	}
+	if err := f.SetCellStyle(claimTemplateNotesSheet, "A4", "F4", styles.optionalHeader); err != nil {
+		return err
+	}
+	if err := f.SetColWidth(claimTemplateNotesSheet, "A", "A", 48); err != nil {
+		return err
+	}
+	return f.SetColWidth(claimTemplateNotesSheet, "E", "E", 60)
+}
+
+func claimColumnWidth(col models.ClaimColumn) float64 {
+	width := float64(len([]rune(col.Name))) + 4
+	if width < 14 {
+		return 14
+	}
+	if width > 50 {
+		return 50
+	}
+	return width
+}
@@ -0,0 +1,377 @@
+package services
+
+import (
+	"fmt"
+	"net/mail"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"nba-dashboard/internal/models"
+)
+
+// Endkunden-Spalten des Standardschemas. Name und Adresse werden über die Spaltenrolle erkannt, E-Mail über
+// den Spaltentyp; diese Namen gelten nur für Schemas ohne Rolle bzw. ohne E-Mail-Spalte.
+const (
+	CustomerNameColumn    = "Vollständiger Name des Endkunden"
+	CustomerAddressColumn = "Adresse des Endkunden"
+	CustomerEmailColumn   = "E-Mailadresse des Endkunden"
+)
+
+// DefaultCustomerDataChecks gilt für den eingebauten Standard und für Schemas ohne eigene Einstellung.
+var DefaultCustomerDataChecks = models.CustomerDataChecks{
+	EmailSyntax:     true,
+	DisposableEmail: true,
+	PostalCode:      true,
+	Address:         true,
+	Name:            true,
+	Duplicates:      true,
+}
+
+// disposableEmailDomains sind bekannte Wegwerf-Anbieter; DISPOSABLE_EMAIL_DOMAINS (kommagetrennt) ergänzt die Liste.
+var disposableEmailDomains = []string{
+	"10minutemail.com", "byom.de", "discard.email", "dispostable.com", "einrot.com", "emailondeck.com",
+	"fakeinbox.com", "getnada.com", "guerrillamail.com", "guerrillamail.de", "maildrop.cc", "mailinator.com",
+	"mailnesia.com", "muellmail.com", "mytemp.email", "sharklasers.com", "spambog.com", "spambog.de",
+	"temp-mail.org", "tempmail.com", "tempr.email", "throwawaymail.com", "trash-mail.com", "trashmail.com",
+	"trashmail.de", "wegwerfemail.de", "yopmail.com",
+}
+
+// Reservierte bzw. typische Beispiel-Domains, die in echten Kundendaten nicht vorkommen.
+var placeholderEmailDomains = []string{"example.com", "example.org", "example.net", "example.de", "test.com", "test.de"}
+
+// Platzhalter, die statt eines echten Endkundennamens eingetragen werden (Vergleich ohne Groß-/Kleinschreibung).
+var placeholderNames = map[string]bool{
+	"test": true, "test test": true, "tester": true, "testkunde": true, "test kunde": true,
+	"max mustermann": true, "erika mustermann": true, "mustermann": true, "john doe": true, "jane doe": true,
+	"vorname nachname": true, "name": true, "kunde": true, "beispiel": true, "dummy": true,
+	"unbekannt": true, "keine angabe": true, "k.a.": true, "n/a": true, "na": true, "none": true, "null": true,
+	"asdf": true, "xxx": true, "-": true,
+}
+
+var (
+	// PLZ gefolgt vom Ort; 4–6 Ziffern, damit auch falsche Längen als PLZ erkannt werden.
+	postalCityPattern = regexp.MustCompile(`(?:^|[\s,])(\d{4,6})\s+(\p{L}[\p{L}\p{M} .\-/()]*)`)
+	// Straße mit Hausnummer, z. B. "Musterstraße 1", "Am Markt 12a", "Hauptstr. 3-5".
+	streetNumberPattern = regexp.MustCompile(`\p{L}{2,}[\p{L}\p{M} .'\-]*\s*\d+\s*[a-zA-Z]?`)
+	germanPostalPattern = regexp.MustCompile(`^\d{5}$`)
+)
+
+// customerDataChecker prüft die Endkunden-Spalten einer Datei. Doppelte Endkunden werden vorab über alle
+// Zeilen ermittelt (gleiche E-Mail bzw. gleicher Name+Adresse).
+type customerDataChecker struct {
+	checks           models.CustomerDataChecks
+	nameColumn       string
+	addressColumn    string
+	emailColumns     []string
+	postalColumns    []string
+	disposable       map[string]bool
+	duplicatesByLine map[int][]int
+}
+
+func newCustomerDataChecker(checks models.CustomerDataChecks, columns []models.ClaimColumn, rows []map[string]string) *customerDataChecker {
+	c := &customerDataChecker{checks: checks}
+	for _, column := range columns {
+		switch column.Role {
+		case models.ClaimColumnRoleCustomerName:
+			c.nameColumn = column.Name
+		case models.ClaimColumnRoleCustomerAddress:
+			c.addressColumn = column.Name
+		}
+		if column.Type == models.ClaimColumnTypeEmail {
+			c.emailColumns = append(c.emailColumns, column.Name)
+		}
+		normalized := NormalizeHeaderName(column.Name)
+		if strings.Contains(normalized, "plz") || strings.Contains(normalized, "postleitzahl") {
+			c.postalColumns = append(c.postalColumns, column.Name)
+		}
+	}
+	if c.nameColumn == "" {
+		c.nameColumn = CustomerNameColumn
+	}
+	if c.addressColumn == "" {
+		c.addressColumn = CustomerAddressColumn
+	}
+	if len(c.emailColumns) == 0 {
+		c.emailColumns = []string{CustomerEmailColumn}
+	}
+	if checks.DisposableEmail {
+		c.disposable = disposableDomainSet()
+	}
+	if checks.Duplicates {
+		c.duplicatesByLine = c.findDuplicates(rows)
+	}
+	return c
+}
+
+func disposableDomainSet() map[string]bool {
+	set := make(map[string]bool, len(disposableEmailDomains))
+	for _, domain := range disposableEmailDomains {
+		set[domain] = true
+	}
+	for _, domain := range strings.Split(os.Getenv("DISPOSABLE_EMAIL_DOMAINS"), ",") {
+		if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+			set[domain] = true
+		}
+	}
+	return set
+}
+
+// findDuplicates ordnet jeder Zeile die anderen Zeilen mit demselben Endkunden zu.
+func (c *customerDataChecker) findDuplicates(rows []map[string]string) map[int][]int {
+	byKey := map[string][]int{}
+	for i, r := range rows {
+		keys := []string{}
+		for _, col := range c.emailColumns {
+			if email := strings.ToLower(strings.TrimSpace(r[col])); email != "" {
+				keys = append(keys, "email:"+email)
+			}
+		}
+		name := customerKeyPart(r[c.nameColumn])
+		address := customerKeyPart(r[c.addressColumn])
+		if name != "" && address != "" {
+			keys = append(keys, "name:"+name+"|"+address)
+		}
+		for _, key := range keys {
+			byKey[key] = append(byKey[key], i)
+		}
+	}
+
+	related := map[int]map[int]bool{}
+	for _, lines := range byKey {
+		if len(lines) < 2 {
+			continue
+		}
+		for _, line := range lines {
+			if related[line] == nil {
+				related[line] = map[int]bool{}
+			}
+			for _, other := range lines {
+				if other != line {
+					related[line][other] = true
+				}
+			}
+		}
+	}
+
+	out := make(map[int][]int, len(related))
+	for line, others := range related {
+		for other := range others {
+			out[line] = append(out[line], other)
+		}
+		sort.Ints(out[line])
+	}
+	return out
+}
+
+// customerKeyPart vergleicht Namen/Adressen ohne Groß-/Kleinschreibung, Leer- und Satzzeichen.
+func customerKeyPart(val string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(val) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// apply prüft die Endkunden-Spalten der Zeile i und ergänzt die Zellen um Notes. Fachliche Fehler markieren
+// die Zelle als invalid; doppelte Endkunden sind nur ein Hinweis (Status bleibt).
+func (c *customerDataChecker) apply(i int, r map[string]string, cells map[string]models.ValidatedCell) {
+	for _, col := range c.emailColumns {
+		val := strings.TrimSpace(r[col])
+		if val == "" {
+			continue
+		}
+		if c.checks.EmailSyntax {
+			if note := emailSyntaxError(val); note != "" {
+				flagCustomerCell(cells, col, val, note, true)
+				continue
+			}
+		}
+		if c.checks.DisposableEmail {
+			if note := c.emailDomainError(val); note != "" {
+				flagCustomerCell(cells, col, val, note, true)
+			}
+		}
+	}
+
+	if val := strings.TrimSpace(r[c.nameColumn]); val != "" && c.checks.Name {
+		if note := customerNameError(val); note != "" {
+			flagCustomerCell(cells, c.nameColumn, val, note, true)
+		}
+	}
+
+	if val := strings.TrimSpace(r[c.addressColumn]); val != "" {
+		for _, note := range c.addressErrors(val) {
+			flagCustomerCell(cells, c.addressColumn, val, note, true)
+		}
+	}
+	if c.checks.PostalCode {
+		for _, col := range c.postalColumns {
+			if val := strings.TrimSpace(r[col]); val != "" {
+				if note := germanPostalCodeError(val); note != "" {
+					flagCustomerCell(cells, col, val, note, true)
+				}
+			}
+		}
+	}
+
+	if others := c.duplicatesByLine[i]; len(others) > 0 {
+		lines := make([]string, 0, len(others))
+		for _, other := range others {
+			lines = append(lines, strconv.Itoa(other+1))
+		}
+		note := fmt.Sprintf("Endkunde mehrfach in der Datei (auch Zeile %s)", strings.Join(lines, ", "))
+		col := c.nameColumn
+		if strings.TrimSpace(r[col]) == "" {
+			col = c.emailColumns[0]
+		}
+		flagCustomerCell(cells, col, strings.TrimSpace(r[col]), note, false)
+	}
+}
+
+// flagCustomerCell hängt eine Note an die Zelle an; invalid setzt zusätzlich den Status.
+func flagCustomerCell(cells map[string]models.ValidatedCell, col string, val string, note string, invalid bool) {
+	cell, ok := cells[col]
+	if !ok {
+		cell = models.ValidatedCell{Value: val, Status: models.CellOK}
+	}
+	if invalid {
+		cell.Status = models.CellInvalid
+	}
+	switch {
+	case cell.Note == "":
+		cell.Note = note
+	case !strings.Contains(cell.Note, note):
+		cell.Note += "; " + note
+	}
+	cells[col] = cell
+}
+
+// emailSyntaxError prüft eine einzelne Adresse nach RFC 5322 (ohne Anzeigenamen) und eine Domain mit TLD.
+func emailSyntaxError(val string) string {
+	addr, err := mail.ParseAddress(val)
+	if err != nil || addr.Name != "" || addr.Address != val {
+		return "E-Mail-Adresse ungültig"
+	}
+	domain := val[strings.LastIndex(val, "@")+1:]
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return "E-Mail-Domain ungültig"
+	}
+	for _, label := range labels {
+		if label == "" || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return "E-Mail-Domain ungültig"
+		}
+	}
+	tld := labels[len(labels)-1]
+	if len(tld) < 2 || strings.IndexFunc(tld, func(r rune) bool { return !unicode.IsLetter(r) }) >= 0 {
+		return "E-Mail-Domain ungültig"
+	}
+	return ""
+}
+
+// emailDomainError meldet Wegwerf- und Beispiel-Domains (auch Subdomains davon).
+func (c *customerDataChecker) emailDomainError(val string) string {
+	at := strings.LastIndex(val, "@")
+	if at < 0 {
+		return ""
+	}
+	domain := strings.ToLower(val[at+1:])
+	for candidate := domain; candidate != ""; {
+		if c.disposable[candidate] {
+			return "Wegwerf-E-Mail-Domain"
+		}
+		for _, placeholder := range placeholderEmailDomains {
+			if candidate == placeholder {
+				return "Platzhalter-E-Mail-Domain"
+			}
+		}
+		dot := strings.Index(candidate, ".")
+		if dot < 0 {
+			break
+		}
+		candidate = candidate[dot+1:]
+	}
+	return ""
+}
+
+// customerNameError erkennt Platzhalter (nur als ganzer Name, damit echte Namen wie "Dummy" oder "Test"
+// als Nachname nicht anschlagen) und Namen ohne Buchstaben bzw. mit Ziffern.
+func customerNameError(val string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(val)), " ")
+	if placeholderNames[normalized] {
+		return "Platzhalter statt Endkundenname"
+	}
+	if strings.IndexFunc(val, unicode.IsDigit) >= 0 {
+		return "Name enthält Ziffern"
+	}
+	if strings.IndexFunc(val, unicode.IsLetter) < 0 {
+		return "Name ungültig"
+	}
+	if isRepeatedRune(customerKeyPart(normalized)) {
+		return "Platzhalter statt Endkundenname"
+	}
+	return ""
+}
+
+func isRepeatedRune(val string) bool {
+	runes := []rune(val)
+	if len(runes) < 2 {
+		return false
+	}
+	for _, r := range runes[1:] {
+		if r != runes[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// addressErrors prüft eine einzeilige deutsche Anschrift ("Straße Nr, PLZ Ort") je nach aktivierten Prüfungen.
+func (c *customerDataChecker) addressErrors(val string) []string {
+	notes := []string{}
+	match := postalCityPattern.FindStringSubmatchIndex(val)
+
+	if c.checks.PostalCode {
+		if match == nil {
+			notes = append(notes, "PLZ fehlt")
+		} else if note := germanPostalCodeError(val[match[2]:match[3]]); note != "" {
+			notes = append(notes, note)
+		}
+	}
+
+	if c.checks.Address {
+		street := val
+		if match != nil {
+			street = val[:match[0]] + val[match[1]:]
+		}
+		lower := strings.ToLower(val)
+		switch {
+		case strings.Contains(lower, "musterstr"), placeholderNames[strings.TrimSpace(lower)]:
+			notes = append(notes, "Platzhalter statt Adresse")
+		case !streetNumberPattern.MatchString(street):
+			notes = append(notes, "Straße mit Hausnummer fehlt")
+		}
+		if match == nil && !c.checks.PostalCode {
+			notes = append(notes, "PLZ und Ort fehlen")
+		}
+	}
+	return notes
+}
+
+// germanPostalCodeError prüft eine fünfstellige deutsche PLZ (01001–99998).
+func germanPostalCodeError(val string) string {
+	if !germanPostalPattern.MatchString(val) {
+		return "PLZ ungültig (5 Ziffern erwartet)"
+	}
+	code, _ := strconv.Atoi(val)
+	if code < 1001 || code > 99998 {
+		return "PLZ ungültig"
+	}
+	return ""
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"nba-dashboard/internal/models"
+)
+
+func TestEmailSyntaxError(t *testing.T) {
+	tests := []struct {
+		val  string
+		want string
+	}{
+		{"anna@beispiel.de", ""},
+		{"anna.schmidt+shop@mail.beispiel.de", ""},
+		{"Anna <anna@beispiel.de>", "E-Mail-Adresse ungültig"},
+		{"anna beispiel.de", "E-Mail-Adresse ungültig"},
+		{"anna@beispiel", "E-Mail-Domain ungültig"},
+		{"anna@-beispiel.de", "E-Mail-Domain ungültig"},
+		{"anna@beispiel.d3", "E-Mail-Domain ungültig"},
+	}
+	for _, tt := range tests {
+		if got := emailSyntaxError(tt.val); got != tt.want {
+			t.Errorf("emailSyntaxError(%q) = %q, want %q", tt.val, got, tt.want)
+		}
+	}
+}
+
+func TestEmailDomainError(t *testing.T) {
+	c := &customerDataChecker{disposable: map[string]bool{"mailinator.com": true}}
+	tests := []struct {
+		val  string
+		want string
+	}{
+		{"anna@gmail.com", ""},
+		{"anna@mailinator.com", "Wegwerf-E-Mail-Domain"},
+		{"anna@eu.MAILINATOR.com", "Wegwerf-E-Mail-Domain"},
+		{"anna@example.com", "Platzhalter-E-Mail-Domain"},
+		{"anna@shop.test.de", "Platzhalter-E-Mail-Domain"},
+		{"kein-at", ""},
+	}
+	for _, tt := range tests {
+		if got := c.emailDomainError(tt.val); got != tt.want {
+			t.Errorf("emailDomainError(%q) = %q, want %q", tt.val, got, tt.want)
+		}
+	}
+}
+
+func TestCustomerNameError(t *testing.T) {
+	tests := []struct {
+		val  string
+		want string
+	}{
+		{"Anna Schmidt", ""},
+		{"Cher", ""},
+		{"Jens Dummy", ""},
+		{"Anna Test", ""},
+		{"Test", "Platzhalter statt Endkundenname"},
+		{"Max  Mustermann", "Platzhalter statt Endkundenname"},
+		{"N/A", "Platzhalter statt Endkundenname"},
+		{"aaaa", "Platzhalter statt Endkundenname"},
+		{"Anna Schmidt 2", "Name enthält Ziffern"},
+		{"---", "Name ungültig"},
+	}
+	for _, tt := range tests {
+		if got := customerNameError(tt.val); got != tt.want {
+			t.Errorf("customerNameError(%q) = %q, want %q", tt.val, got, tt.want)
+		}
+	}
+}
+
+func TestAddressErrors(t *testing.T) {
+	all := models.CustomerDataChecks{PostalCode: true, Address: true}
+	tests := []struct {
+		name   string
+		checks models.CustomerDataChecks
+		val    string
+		want   []string
+	}{
+		{"vollständig", all, "Hauptstraße 5, 10115 Berlin", []string{}},
+		{"hausnummer mit zusatz", all, "Am Markt 12a, 01067 Dresden", []string{}},
+		{"plz fehlt", all, "Hauptstraße 5", []string{"PLZ fehlt"}},
+		{"plz zu kurz", all, "Hauptstraße 5, 1011 Berlin", []string{"PLZ ungültig (5 Ziffern erwartet)"}},
+		{"ohne hausnummer", all, "Hauptstraße, 10115 Berlin", []string{"Straße mit Hausnummer fehlt"}},
+		{"platzhalter", all, "Musterstraße 1, 10115 Berlin", []string{"Platzhalter statt Adresse"}},
+		{"nur adressprüfung", models.CustomerDataChecks{Address: true}, "Hauptstraße 5", []string{"PLZ und Ort fehlen"}},
+		{"nur plz-prüfung", models.CustomerDataChecks{PostalCode: true}, "Hauptstraße, 00000 Berlin", []string{"PLZ ungültig"}},
+		{"alles aus", models.CustomerDataChecks{}, "irgendwas", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &customerDataChecker{checks: tt.checks}
+			if got := c.addressErrors(tt.val); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("addressErrors(%q) = %v, want %v", tt.val, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGermanPostalCodeError(t *testing.T) {
+	tests := []struct {
+		val  string
+		want string
+	}{
+		{"10115", ""},
+		{"01067", ""},
+		{"00999", "PLZ ungültig"},
+		{"99999", "PLZ ungültig"},
+		{"1011", "PLZ ungültig (5 Ziffern erwartet)"},
+		{"1011A", "PLZ ungültig (5 Ziffern erwartet)"},
+	}
+	for _, tt := range tests {
+		if got := germanPostalCodeError(tt.val); got != tt.want {
+			t.Errorf("germanPostalCodeError(%q) = %q, want %q", tt.val, got, tt.want)
+		}
+	}
+}
+
+func TestCustomerDataCheckerApply(t *testing.T) {
+	rows := []map[string]string{
+		{CustomerNameColumn: "Anna Schmidt", CustomerAddressColumn: "Hauptstraße 5, 10115 Berlin", CustomerEmailColumn: "anna@beispiel.de"},
+		{CustomerNameColumn: "Ben Meier", CustomerAddressColumn: "Am Markt 1, 01067 Dresden", CustomerEmailColumn: "ben@beispiel.de"},
+		{CustomerNameColumn: "A. Schmidt", CustomerAddressColumn: "Ringstraße 2, 80331 München", CustomerEmailColumn: "ANNA@beispiel.de"},
+		{CustomerNameColumn: "ben meier", CustomerAddressColumn: "Am Markt 1, 01067 Dresden", CustomerEmailColumn: "b.meier@beispiel.de"},
+		{CustomerNameColumn: "Test", CustomerAddressColumn: "Hauptstraße 9, 10115 Berlin", CustomerEmailColumn: "x@mailinator.com"},
+	}
+	columns := []models.ClaimColumn{{Name: CustomerEmailColumn, Type: models.ClaimColumnTypeEmail}}
+
+	tests := []struct {
+		name   string
+		checks models.CustomerDataChecks
+		want   map[int]map[string]models.ValidatedCell
+	}{
+		{
+			name:   "alle prüfungen",
+			checks: DefaultCustomerDataChecks,
+			want: map[int]map[string]models.ValidatedCell{
+				0: {CustomerNameColumn: {Value: "Anna Schmidt", Status: models.CellOK, Note: "Endkunde mehrfach in der Datei (auch Zeile 3)"}},
+				1: {CustomerNameColumn: {Value: "Ben Meier", Status: models.CellOK, Note: "Endkunde mehrfach in der Datei (auch Zeile 4)"}},
+				2: {CustomerNameColumn: {Value: "A. Schmidt", Status: models.CellOK, Note: "Endkunde mehrfach in der Datei (auch Zeile 1)"}},
+				3: {CustomerNameColumn: {Value: "ben meier", Status: models.CellOK, Note: "Endkunde mehrfach in der Datei (auch Zeile 2)"}},
+				4: {
+					CustomerNameColumn:  {Value: "Test", Status: models.CellInvalid, Note: "Platzhalter statt Endkundenname"},
+					CustomerEmailColumn: {Value: "x@mailinator.com", Status: models.CellInvalid, Note: "Wegwerf-E-Mail-Domain"},
+				},
+			},
+		},
+		{
+			name:   "alle prüfungen aus",
+			checks: models.CustomerDataChecks{},
+			want:   map[int]map[string]models.ValidatedCell{0: {}, 1: {}, 2: {}, 3: {}, 4: {}},
+		},
+		{
+			name:   "nur namen",
+			checks: models.CustomerDataChecks{Name: true},
+			want: map[int]map[string]models.ValidatedCell{
+				0: {}, 1: {}, 2: {}, 3: {},
+				4: {CustomerNameColumn: {Value: "Test", Status: models.CellInvalid, Note: "Platzhalter statt Endkundenname"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCustomerDataChecker(tt.checks, columns, rows)
+			for i, r := range rows {
+				cells := map[string]models.ValidatedCell{}
+				c.apply(i, r, cells)
+				if !reflect.DeepEqual(cells, tt.want[i]) {
+					t.Errorf("row %d: cells = %+v, want %+v", i, cells, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCustomerDataCheckerColumnRoles(t *testing.T) {
+	columns := []models.ClaimColumn{
+		{Name: "Kunde", Role: models.ClaimColumnRoleCustomerName},
+		{Name: "Anschrift", Role: models.ClaimColumnRoleCustomerAddress},
+		{Name: "Kontakt", Type: models.ClaimColumnTypeEmail},
+	}
+	rows := []map[string]string{
+		{"Kunde": "Anna Schmidt", "Anschrift": "Hauptstraße 5, 10115 Berlin", "Kontakt": "anna@beispiel.de"},
+		{"Kunde": "anna schmidt", "Anschrift": "Hauptstraße 5, 10115 Berlin", "Kontakt": "a.schmidt@beispiel.de"},
+		{"Kunde": "Test", "Anschrift": "Hauptstraße", "Kontakt": "x@beispiel.de"},
+	}
+	want := map[int]map[string]models.ValidatedCell{
+		0: {"Kunde": {Value: "Anna Schmidt", Status: models.CellOK, Note: "Endkunde mehrfach in der Datei (auch Zeile 2)"}},
+		1: {"Kunde": {Value: "anna schmidt", Status: models.CellOK, Note: "Endkunde mehrfach in der Datei (auch Zeile 1)"}},
+		2: {
+			"Kunde":     {Value: "Test", Status: models.CellInvalid, Note: "Platzhalter statt Endkundenname"},
+			"Anschrift": {Value: "Hauptstraße", Status: models.CellInvalid, Note: "PLZ fehlt; Straße mit Hausnummer fehlt"},
+		},
+	}
+
+	c := newCustomerDataChecker(DefaultCustomerDataChecks, columns, rows)
+	for i, r := range rows {
+		cells := map[string]models.ValidatedCell{}
+		c.apply(i, r, cells)
+		if !reflect.DeepEqual(cells, want[i]) {
+			t.Errorf("row %d: cells = %+v, want %+v", i, cells, want[i])
+		}
+	}
+}
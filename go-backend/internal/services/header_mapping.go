@@ -0,0 +1,504 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"nba-dashboard/internal/lib"
+	"nba-dashboard/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidHeaderMapping meldet ein Profil ohne Zuordnungen, mit leeren Quell-/Zielnamen oder ungültigem Geltungsbereich.
+var ErrInvalidHeaderMapping = errors.New("invalid header mapping")
+
+// Spaltennamen des Ordertokens im Standardschema; "Ordertoken/Order ID" ist die ältere Schreibweise.
+const (
+	OrderTokenColumn       = "Ordertoken/OrderID"
+	LegacyOrderTokenColumn = "Ordertoken/Order ID"
+)
+
+// Art, wie ein Quell-Header einer Schema-Spalte zugeordnet wurde.
+const (
+	HeaderMatchProfile    = "profile"
+	HeaderMatchExact      = "exact"
+	HeaderMatchNormalized = "normalized"
+	HeaderMatchAlias      = "alias"
+	HeaderMatchNone       = "none"
+)
+
+const (
+	maxHeaderMappings          = 200
+	headerSuggestionMinScore   = 0.5
+	headerSuggestionsPerHeader = 3
+)
+
+// builtinHeaderAliases sind gängige Schreibweisen (normalisiert) für Spalten des Standardschemas.
+// Sie greifen nur, wenn die Zielspalte im aufgelösten Schema vorkommt.
+var builtinHeaderAliases = map[string]string{
+	"orderid":            OrderTokenColumn,
+	"ordertoken":         OrderTokenColumn,
+	"ordernumber":        OrderTokenColumn,
+	"orderno":            OrderTokenColumn,
+	"ordernr":            OrderTokenColumn,
+	"bestellnummer":      OrderTokenColumn,
+	"bestellnr":          OrderTokenColumn,
+	"bestellid":          OrderTokenColumn,
+	"auftragsnummer":     OrderTokenColumn,
+	"transactionid":      OrderTokenColumn,
+	"transaktionsid":     OrderTokenColumn,
+	"sub":                "SubID",
+	"subid1":             "SubID",
+	"publisher":          "Publisher ID",
+	"pubid":              "Publisher ID",
+	"publisherid":        "Publisher ID",
+	"email":              "E-Mailadresse des Endkunden",
+	"emailadresse":       "E-Mailadresse des Endkunden",
+	"mail":               "E-Mailadresse des Endkunden",
+	"kundenemail":        "E-Mailadresse des Endkunden",
+	"name":               "Vollständiger Name des Endkunden",
+	"kundenname":         "Vollständiger Name des Endkunden",
+	"kunde":              "Vollständiger Name des Endkunden",
+	"adresse":            "Adresse des Endkunden",
+	"anschrift":          "Adresse des Endkunden",
+	"kundenadresse":      "Adresse des Endkunden",
+	"datum":              "Timestamp",
+	"zeitstempel":        "Timestamp",
+	"zeitpunkt":          "Timestamp",
+	"bestelldatum":       "Timestamp",
+	"orderdate":          "Timestamp",
+	"provision":          "Höhe der Provision (Optional)",
+	"commission":         "Höhe der Provision (Optional)",
+	"grund":              "Grund der Anfrage",
+	"tarif":              "Abgeschlossener Tarif",
+	"lieferbeginn":       "Belieferungsbeginn (Optional)",
+	"belieferungsbeginn": "Belieferungsbeginn (Optional)",
+}
+
+// HeaderSuggestion ist ein unscharfer Vorschlag für einen nicht zugeordneten Header (Score 0..1).
+type HeaderSuggestion struct {
+	Column string  `json:"column"`
+	Score  float64 `json:"score"`
+}
+
+// HeaderResolution beschreibt die Zuordnung eines Quell-Headers. Column ist leer, wenn nichts zugeordnet wurde;
+// dann stehen ggf. Vorschläge in Suggestions (werden nie automatisch übernommen).
+type HeaderResolution struct {
+	Index       int                `json:"index"`
+	Source      string             `json:"source"`
+	Column      string             `json:"column"`
+	Match       string             `json:"match"`
+	Suggestions []HeaderSuggestion `json:"suggestions,omitempty"`
+}
+
+// HeaderMapping ist die Zuordnung aller Header einer Datei zum Schema.
+type HeaderMapping struct {
+	HeaderRow       int                `json:"headerRow"`
+	Headers         []HeaderResolution `json:"headers"`
+	MissingRequired []string           `json:"missingRequired"`
+}
+
+// SourceFor liefert den Quell-Header, der einer Schema-Spalte zugeordnet ist.
+func (m HeaderMapping) SourceFor(column string) string {
+	for _, header := range m.Headers {
+		if header.Column == column {
+			return header.Source
+		}
+	}
+	return ""
+}
+
+// HeaderContext bündelt Schema und Mapping-Profil, mit denen die Dateien eines Uploads gelesen werden.
+type HeaderContext struct {
+	Schema     ResolvedClaimSchema `json:"schema"`
+	Profile    map[string]string   `json:"profile"`
+	ProfileIDs []uint              `json:"profileIds"`
+}
+
+// NormalizeHeaderName vereinheitlicht Header für Vergleiche (Kleinschreibung, Umlaute, ohne Sonderzeichen).
+func NormalizeHeaderName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss").Replace(name)
+	var b strings.Builder
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// ResolveHeaders ordnet die Header einer Kopfzeile den Schema-Spalten zu. Reihenfolge: Profil, exakter Name,
+// normalisierter Name, eingebauter Alias. Jede Schema-Spalte wird höchstens einem Header zugeordnet.
+func ResolveHeaders(headers []string, columns []models.ClaimColumn, profile map[string]string) HeaderMapping {
+	return resolveHeaders(headers, columns, profile, true)
+}
+
+func resolveHeaders(headers []string, columns []models.ClaimColumn, profile map[string]string, withSuggestions bool) HeaderMapping {
+	known := make(map[string]string, len(columns))
+	byNormalized := make(map[string]string, len(columns))
+	for _, col := range columns {
+		known[col.Name] = col.Name
+		if key := NormalizeHeaderName(col.Name); key != "" {
+			if _, exists := byNormalized[key]; !exists {
+				byNormalized[key] = col.Name
+			}
+		}
+	}
+
+	resolutions := make([]HeaderResolution, 0, len(headers))
+	for i, header := range headers {
+		if source := strings.TrimSpace(header); source != "" {
+			resolutions = append(resolutions, HeaderResolution{Index: i, Source: source, Match: HeaderMatchNone})
+		}
+	}
+
+	claimed := map[string]bool{}
+	passes := []struct {
+		match  string
+		lookup func(source string) string
+	}{
+		{HeaderMatchProfile, func(source string) string { return known[profile[NormalizeHeaderName(source)]] }},
+		{HeaderMatchExact, func(source string) string { return known[source] }},
+		{HeaderMatchNormalized, func(source string) string { return byNormalized[NormalizeHeaderName(source)] }},
+		{HeaderMatchAlias, func(source string) string { return known[builtinHeaderAliases[NormalizeHeaderName(source)]] }},
+	}
+	for _, pass := range passes {
+		for i := range resolutions {
+			if resolutions[i].Column != "" {
+				continue
+			}
+			if column := pass.lookup(resolutions[i].Source); column != "" && !claimed[column] {
+				resolutions[i].Column = column
+				resolutions[i].Match = pass.match
+				claimed[column] = true
+			}
+		}
+	}
+
+	for i := range resolutions {
+		if withSuggestions && resolutions[i].Column == "" {
+			resolutions[i].Suggestions = suggestHeaderColumns(resolutions[i].Source, columns, claimed)
+		}
+	}
+
+	missing := []string{}
+	for _, col := range columns {
+		if col.Required && !claimed[col.Name] {
+			missing = append(missing, col.Name)
+		}
+	}
+	return HeaderMapping{Headers: resolutions, MissingRequired: missing}
+}
+
+// suggestHeaderColumns liefert die ähnlichsten noch freien Schema-Spalten (Levenshtein bzw. Teilwort).
+func suggestHeaderColumns(source string, columns []models.ClaimColumn, claimed map[string]bool) []HeaderSuggestion {
+	needle := NormalizeHeaderName(source)
+	if needle == "" {
+		return nil
+	}
+	suggestions := []HeaderSuggestion{}
+	for _, col := range columns {
+		if claimed[col.Name] {
+			continue
+		}
+		score := headerSimilarity(needle, NormalizeHeaderName(col.Name))
+		if score >= headerSuggestionMinScore {
+			suggestions = append(suggestions, HeaderSuggestion{Column: col.Name, Score: float64(int(score*100)) / 100})
+		}
+	}
+	slices.SortStableFunc(suggestions, func(a, b HeaderSuggestion) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		return 0
+	})
+	if len(suggestions) > headerSuggestionsPerHeader {
+		suggestions = suggestions[:headerSuggestionsPerHeader]
+	}
+	return suggestions
+}
+
+func headerSimilarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	longest := max(len([]rune(a)), len([]rune(b)))
+	score := 1 - float64(levenshtein(a, b))/float64(longest)
+	if shorter := min(len(a), len(b)); shorter >= 4 && (strings.Contains(a, b) || strings.Contains(b, a)) {
+		score = max(score, 0.75)
+	}
+	return score
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// MapTable sucht die Kopfzeile (meiste zugeordnete Header), ordnet die Header zu und liefert die Zeilen
+// mit Schema-Spaltennamen als Schlüssel. Nicht zugeordnete Spalten bleiben unter ihrem Originalnamen erhalten.
+func (h HeaderContext) MapTable(data [][]string) (HeaderMapping, []map[string]string) {
+	mapping := h.mapHeader(data)
+	rows := lib.TableToMaps(data, mapping.HeaderRow)
+	for _, row := range rows {
+		mapping.fillColumns(row)
+	}
+	return mapping, rows
+}
+
+// MapTableRows ordnet wie MapTable nur die angegebenen Datenzeilen zu (1-basiert unter der Kopfzeile,
+// Schlüssel der Rückgabe). Nummern außerhalb der Tabelle werden übergangen.
+func (h HeaderContext) MapTableRows(data [][]string, rowNos []int) (HeaderMapping, map[int]map[string]string) {
+	mapping := h.mapHeader(data)
+	rows := make(map[int]map[string]string, len(rowNos))
+	if mapping.HeaderRow >= len(data) {
+		return mapping, rows
+	}
+	for _, rowNo := range rowNos {
+		idx := mapping.HeaderRow + rowNo
+		if rowNo < 1 || idx >= len(data) {
+			continue
+		}
+		row := lib.TableToMaps([][]string{data[mapping.HeaderRow], data[idx]}, 0)[0]
+		mapping.fillColumns(row)
+		rows[rowNo] = row
+	}
+	return mapping, rows
+}
+
+func (h HeaderContext) columns() []models.ClaimColumn {
+	if len(h.Schema.Columns) == 0 {
+		return DefaultClaimColumns
+	}
+	return h.Schema.Columns
+}
+
+// headerRow liefert die Zeile mit den meisten zugeordneten Headern (0, wenn keine passt).
+func (h HeaderContext) headerRow(data [][]string) int {
+	columns := h.columns()
+	headerIdx, bestHits := 0, 0
+	for i, row := range data {
+		hits := 0
+		for _, header := range resolveHeaders(row, columns, h.Profile, false).Headers {
+			if header.Column != "" {
+				hits++
+			}
+		}
+		if hits > bestHits {
+			headerIdx, bestHits = i, hits
+		}
+	}
+	return headerIdx
+}
+
+func (h HeaderContext) mapHeader(data [][]string) HeaderMapping {
+	headerIdx := h.headerRow(data)
+	var mapping HeaderMapping
+	if headerIdx < len(data) {
+		mapping = ResolveHeaders(data[headerIdx], h.columns(), h.Profile)
+	} else {
+		mapping = ResolveHeaders(nil, h.columns(), h.Profile)
+	}
+	mapping.HeaderRow = headerIdx
+	return mapping
+}
+
+// fillColumns legt die Werte zugeordneter Spalten zusätzlich unter dem Schema-Spaltennamen ab.
+func (m HeaderMapping) fillColumns(row map[string]string) {
+	for _, header := range m.Headers {
+		if header.Column == "" || header.Column == header.Source {
+			continue
+		}
+		if strings.TrimSpace(row[header.Column]) == "" {
+			row[header.Column] = row[header.Source]
+		}
+	}
+}
+
+// TableKey löst Kopfzeile und Ordertoken-Spalte wie MapTable über Profil, Schema und Aliasse auf;
+// Schlüsselwerte werden mit NormalizeOrderToken verglichen. Fehlt der Ordertoken im Schema, zählt nur er.
+func (h HeaderContext) TableKey() lib.TableKey {
+	columns := h.columns()
+	if !slices.ContainsFunc(columns, func(col models.ClaimColumn) bool { return col.Name == OrderTokenColumn }) {
+		columns = []models.ClaimColumn{{Name: OrderTokenColumn}}
+	}
+	return lib.TableKey{
+		Column: func(header []string) string {
+			mapping := resolveHeaders(header, columns, h.Profile, false)
+			if source := mapping.SourceFor(OrderTokenColumn); source != "" {
+				return source
+			}
+			return mapping.SourceFor(LegacyOrderTokenColumn)
+		},
+		Normalize: NormalizeOrderToken,
+		HeaderRow: h.headerRow,
+	}
+}
+
+// RowOrderToken liefert den Ordertoken einer (gemappten) Zeile und die Spalte, aus der er stammt.
+func RowOrderToken(row map[string]string) (string, string) {
+	if token := strings.TrimSpace(row[OrderTokenColumn]); token != "" {
+		return token, OrderTokenColumn
+	}
+	if token := strings.TrimSpace(row[LegacyOrderTokenColumn]); token != "" {
+		return token, LegacyOrderTokenColumn
+	}
+	return "", OrderTokenColumn
+}
+
+// ResolveHeaderMappingProfile führt alle passenden Profile zusammen; speziellere überschreiben allgemeinere
+// (global, Advertiser, Publisher, Advertiser+Publisher). Schlüssel sind normalisierte Quell-Header.
+func ResolveHeaderMappingProfile(db *gorm.DB, advertiserIDs []uint, publisherID uint) (map[string]string, []uint, error) {
+	var profiles []models.HeaderMappingProfile
+	if err := db.Where("advertiser_id IN ? AND publisher_id IN ?", append(slices.Clone(advertiserIDs), 0), []uint{publisherID, 0}).
+		Order("advertiser_id DESC, id ASC").
+		Find(&profiles).Error; err != nil {
+		return nil, nil, err
+	}
+	specificity := func(p models.HeaderMappingProfile) int {
+		score := 0
+		if p.AdvertiserID > 0 {
+			score++
+		}
+		if p.PublisherID > 0 {
+			score += 2
+		}
+		return score
+	}
+	// Bei mehreren Advertisern gewinnt (wie beim Schema) die kleinste ID, daher zuletzt angewendet.
+	slices.SortStableFunc(profiles, func(a, b models.HeaderMappingProfile) int {
+		return specificity(a) - specificity(b)
+	})
+
+	merged := map[string]string{}
+	ids := []uint{}
+	for _, profile := range profiles {
+		for source, column := range profile.Mappings {
+			merged[NormalizeHeaderName(source)] = column
+		}
+		ids = append(ids, profile.ID)
+	}
+	return merged, ids, nil
+}
+
+// UploadPublisherID liefert den Publisher, der den Upload angelegt hat (0 bei Admin-/Advertiser-Uploads).
+func UploadPublisherID(db *gorm.DB, upload models.Upload) (uint, error) {
+	var ids []uint
+	if err := db.Model(&models.User{}).Where("email = ? AND role = ?", upload.UploadedBy, "publisher").Limit(1).Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return ids[0], nil
+}
+
+// LoadUploadHeaderContext löst Schema und Mapping-Profil für einen Upload (optional mit Kampagne) auf.
+func LoadUploadHeaderContext(db *gorm.DB, upload models.Upload, campaignID string) (HeaderContext, error) {
+	schema, err := ResolveUploadClaimSchema(db, upload, campaignID)
+	if err != nil {
+		return HeaderContext{Schema: schema}, err
+	}
+	advertiserIDs, err := UploadAdvertiserIDs(db, upload, time.Now())
+	if err != nil {
+		return HeaderContext{Schema: schema}, err
+	}
+	publisherID, err := UploadPublisherID(db, upload)
+	if err != nil {
+		return HeaderContext{Schema: schema}, err
+	}
+	profile, ids, err := ResolveHeaderMappingProfile(db, advertiserIDs, publisherID)
+	if err != nil {
+		return HeaderContext{Schema: schema}, err
+	}
+	return HeaderContext{Schema: schema, Profile: profile, ProfileIDs: ids}, nil
+}
+
+// ListHeaderMappingProfiles liefert alle Mapping-Profile, globale zuerst.
+func ListHeaderMappingProfiles(db *gorm.DB) ([]models.HeaderMappingProfile, error) {
+	profiles := []models.HeaderMappingProfile{}
+	err := db.Order("advertiser_id ASC, publisher_id ASC").Find(&profiles).Error
+	return profiles, err
+}
+
+// UpsertHeaderMappingProfile legt das Profil für Advertiser+Publisher an oder ersetzt dessen Zuordnungen.
+func UpsertHeaderMappingProfile(tx *gorm.DB, profile models.HeaderMappingProfile) (models.HeaderMappingProfile, error) {
+	if len(profile.Mappings) == 0 || len(profile.Mappings) > maxHeaderMappings {
+		return profile, ErrInvalidHeaderMapping
+	}
+	mappings := make(map[string]string, len(profile.Mappings))
+	for source, column := range profile.Mappings {
+		source, column = strings.TrimSpace(source), strings.TrimSpace(column)
+		if NormalizeHeaderName(source) == "" || column == "" {
+			return profile, ErrInvalidHeaderMapping
+		}
+		mappings[source] = column
+	}
+	profile.Mappings = mappings
+	profile.Name = strings.TrimSpace(profile.Name)
+
+	if profile.AdvertiserID > 0 {
+		if err := ValidateUploadAccessAssignments(tx, []UploadAccessAssignment{{AdvertiserID: profile.AdvertiserID}}); err != nil {
+			if errors.Is(err, ErrInvalidAdvertiserSelection) {
+				return profile, ErrInvalidHeaderMapping
+			}
+			return profile, err
+		}
+	}
+	if profile.PublisherID > 0 {
+		var count int64
+		if err := tx.Model(&models.User{}).Where("id = ? AND role = ?", profile.PublisherID, "publisher").Count(&count).Error; err != nil {
+			return profile, err
+		}
+		if count == 0 {
+			return profile, ErrInvalidHeaderMapping
+		}
+	}
+
+	profile.ID = 0
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "advertiser_id"}, {Name: "publisher_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "mappings", "updated_by", "updated_at"}),
+	}).Create(&profile).Error
+	if err != nil {
+		return profile, err
+	}
+	err = tx.Where("advertiser_id = ? AND publisher_id = ?", profile.AdvertiserID, profile.PublisherID).First(&profile).Error
+	return profile, err
+}
+
+// DeleteHeaderMappingProfile entfernt ein Mapping-Profil.
+func DeleteHeaderMappingProfile(tx *gorm.DB, profileID uint) error {
+	result := tx.Delete(&models.HeaderMappingProfile{}, profileID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"nba-dashboard/internal/lib"
+	"nba-dashboard/internal/models"
+)
+
+func TestNormalizeHeaderName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Ordertoken/OrderID", "ordertokenorderid"},
+		{"Ordertoken/Order ID", "ordertokenorderid"},
+		{" E-Mail-Adresse ", "emailadresse"},
+		{"Höhe der Provision (Optional)", "hoehederprovisionoptional"},
+		{"Straße", "strasse"},
+		{"---", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeHeaderName(tt.name); got != tt.want {
+			t.Errorf("NormalizeHeaderName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResolveHeaders(t *testing.T) {
+	columns := []models.ClaimColumn{
+		{Name: OrderTokenColumn, Required: true},
+		{Name: "SubID", Required: true},
+		{Name: "Timestamp"},
+	}
+	tests := []struct {
+		name    string
+		headers []string
+		profile map[string]string
+		want    []string
+		missing []string
+	}{
+		{
+			name:    "exakte namen",
+			headers: []string{"Ordertoken/OrderID", "SubID", ""},
+			want:    []string{"Ordertoken/OrderID=Ordertoken/OrderID/exact", "SubID=SubID/exact"},
+			missing: []string{},
+		},
+		{
+			name:    "normalisierte namen",
+			headers: []string{"ordertoken orderid", "Sub-ID"},
+			want:    []string{"ordertoken orderid=Ordertoken/OrderID/normalized", "Sub-ID=SubID/normalized"},
+			missing: []string{},
+		},
+		{
+			name:    "alias, jede spalte nur einmal",
+			headers: []string{"Order ID", "Bestellnummer", "Bestelldatum"},
+			want:    []string{"Order ID=Ordertoken/OrderID/alias", "Bestellnummer=/none", "Bestelldatum=Timestamp/alias"},
+			missing: []string{"SubID"},
+		},
+		{
+			name:    "exakter name schlägt früheren alias",
+			headers: []string{"Order ID", "Ordertoken/OrderID"},
+			want:    []string{"Order ID=/none", "Ordertoken/OrderID=Ordertoken/OrderID/exact"},
+			missing: []string{"SubID"},
+		},
+		{
+			name:    "profil vor allen anderen",
+			headers: []string{"Order ID", "Ref"},
+			profile: map[string]string{"orderid": "SubID", "ref": OrderTokenColumn},
+			want:    []string{"Order ID=SubID/profile", "Ref=Ordertoken/OrderID/profile"},
+			missing: []string{},
+		},
+		{
+			name:    "profilziel außerhalb des schemas zählt nicht",
+			headers: []string{"Ref"},
+			profile: map[string]string{"ref": "Unbekannt"},
+			want:    []string{"Ref=/none"},
+			missing: []string{OrderTokenColumn, "SubID"},
+		},
+		{
+			name:    "orderwert ist kein ordertoken",
+			headers: []string{"Orderwert", "SubID"},
+			want:    []string{"Orderwert=/none", "SubID=SubID/exact"},
+			missing: []string{OrderTokenColumn},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapping := ResolveHeaders(tt.headers, columns, tt.profile)
+			got := make([]string, 0, len(mapping.Headers))
+			for _, h := range mapping.Headers {
+				got = append(got, h.Source+"="+h.Column+"/"+h.Match)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("headers = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(mapping.MissingRequired, tt.missing) {
+				t.Errorf("MissingRequired = %v, want %v", mapping.MissingRequired, tt.missing)
+			}
+		})
+	}
+}
+
+func TestResolveHeadersSuggestions(t *testing.T) {
+	columns := []models.ClaimColumn{{Name: "Grund der Anfrage"}, {Name: "SubID"}}
+	mapping := ResolveHeaders([]string{"Grund d. Anfrage", "SubID", "xyz"}, columns, nil)
+
+	if s := mapping.Headers[0].Suggestions; len(s) == 0 || s[0].Column != "Grund der Anfrage" {
+		t.Errorf("suggestions for %q = %+v, want Grund der Anfrage first", mapping.Headers[0].Source, s)
+	}
+	if s := mapping.Headers[1].Suggestions; len(s) != 0 {
+		t.Errorf("mapped header must not carry suggestions, got %+v", s)
+	}
+	if s := mapping.Headers[2].Suggestions; len(s) != 0 {
+		t.Errorf("suggestions for %q = %+v, want none", mapping.Headers[2].Source, s)
+	}
+}
+
+func TestHeaderContextMapTable(t *testing.T) {
+	ctx := HeaderContext{Schema: ResolvedClaimSchema{Columns: []models.ClaimColumn{
+		{Name: OrderTokenColumn, Required: true},
+		{Name: "SubID", Required: true},
+	}}}
+	data := [][]string{
+		{"Nachbuchungen März"},
+		{"Order ID", "Sub", "Notiz"},
+		{"A1", "s1", "x"},
+	}
+	mapping, rows := ctx.MapTable(data)
+	if mapping.HeaderRow != 1 {
+		t.Fatalf("HeaderRow = %d, want 1", mapping.HeaderRow)
+	}
+	want := []map[string]string{{
+		"Order ID": "A1", OrderTokenColumn: "A1",
+		"Sub": "s1", "SubID": "s1",
+		"Notiz": "x",
+	}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %v, want %v", rows, want)
+	}
+}
+
+func TestHeaderContextTableKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		ctx    HeaderContext
+		header []string
+		want   string
+	}{
+		{
+			name:   "standardspalte",
+			header: []string{"Ordertoken/OrderID", "Orderwert"},
+			want:   "Ordertoken/OrderID",
+		},
+		{
+			name:   "ältere schreibweise",
+			header: []string{"Ordertoken/Order ID", "SubID"},
+			want:   "Ordertoken/Order ID",
+		},
+		{
+			name:   "alias",
+			header: []string{"Orderwert", "Bestellnummer"},
+			want:   "Bestellnummer",
+		},
+		{
+			name:   "mapping-profil",
+			ctx:    HeaderContext{Profile: map[string]string{"ref": OrderTokenColumn}},
+			header: []string{"Orderwert", "Ref"},
+			want:   "Ref",
+		},
+		{
+			name:   "schema ohne ordertoken nutzt nur die standardspalte",
+			ctx:    HeaderContext{Schema: ResolvedClaimSchema{Columns: []models.ClaimColumn{{Name: "SubID"}}}},
+			header: []string{"SubID", "Order ID"},
+			want:   "Order ID",
+		},
+		{
+			name:   "keine ordertoken-spalte",
+			header: []string{"Orderwert", "Order Status"},
+			want:   "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := tt.ctx.TableKey()
+			if got := key.Column(tt.header); got != tt.want {
+				t.Errorf("Column(%v) = %q, want %q", tt.header, got, tt.want)
+			}
+			if got := key.Normalize("0012345"); got != "12345" {
+				t.Errorf("Normalize(%q) = %q, want %q", "0012345", got, "12345")
+			}
+		})
+	}
+}
+
+func TestHeaderContextPatchRows(t *testing.T) {
+	// Die Hinweiszeile nennt schon "Ordertoken/OrderID", die Kopfzeile (meiste Treffer) ist aber die zweite.
+	data := [][]string{
+		{"Pflichtfeld:", "Ordertoken/OrderID"},
+		{"Publisher ID", "Ordertoken/OrderID", "SubID"},
+		{"1", "0012345", "a"},
+		{"1", "B-2", "b"},
+	}
+	ctx := HeaderContext{}
+	mapping, _ := ctx.MapTable(data)
+	key := ctx.TableKey()
+	if got := key.HeaderRow(data); got != mapping.HeaderRow {
+		t.Fatalf("TableKey.HeaderRow = %d, MapTable.HeaderRow = %d", got, mapping.HeaderRow)
+	}
+
+	patch, err := lib.ApplyTableOps(data, []lib.TableOp{{Op: lib.TableOpSetCell, OrderToken: "12345", Column: "SubID", Value: "z"}}, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, rows := ctx.MapTableRows(patch.Data, patch.ChangedRows)
+	if len(rows) != 1 || rows[1]["SubID"] != "z" || rows[1][OrderTokenColumn] != "0012345" {
+		t.Errorf("mapped rows = %v, want row 1 with SubID z", rows)
+	}
+}
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"nba-dashboard/internal/models"
+)
+
+// Art, wie ein Ordertoken der Datei einer Order zugeordnet bzw. vorgeschlagen wurde.
+const (
+	OrderTokenMatchExact      = "exact"
+	OrderTokenMatchNormalized = "normalized"
+	OrderTokenMatchScientific = "scientific"
+	OrderTokenMatchPrefix     = "prefix"
+	OrderTokenMatchEdit       = "edit_distance"
+)
+
+const (
+	orderTokenSuggestionLimit   = 3
+	orderTokenMinConfidence     = 0.6
+	orderTokenMinPrefixLength   = 6
+	orderTokenBucketKeyLength   = 3
+	orderTokenMaxBucketCompares = 5000
+)
+
+var (
+	scientificTokenPattern = regexp.MustCompile(`^[+-]?(\d+)(?:[.,](\d+))?E([+-]?\d+)$`)
+	decimalZeroPattern     = regexp.MustCompile(`^(\d+)[.,]0+$`)
+)
+
+// NormalizeOrderToken vereinheitlicht Ordertokens für den Abgleich: ohne Leer- und Steuerzeichen, ohne
+// Excel-Textpräfix/Anführungszeichen, Großschreibung, "12345.0" -> "12345", wissenschaftliche Notation
+// ausgeschrieben und rein numerische Tokens ohne führende Nullen.
+func NormalizeOrderToken(raw string) string {
+	token := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.Is(unicode.Cf, r) || unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, raw)
+	token = strings.ToUpper(strings.Trim(token, `'"`))
+	if m := decimalZeroPattern.FindStringSubmatch(token); m != nil {
+		token = m[1]
+	}
+	if expanded, ok := expandScientificToken(token); ok {
+		token = expanded
+	}
+	if isDigits(token) {
+		if trimmed := strings.TrimLeft(token, "0"); trimmed != "" {
+			token = trimmed
+		} else {
+			token = "0"
+		}
+	}
+	return token
+}
+
+// expandScientificToken schreibt z. B. "1.23457E+11" als "123457000000" aus (nur ganzzahlige Werte).
+func expandScientificToken(token string) (string, bool) {
+	if !scientificTokenPattern.MatchString(token) {
+		return "", false
+	}
+	value, ok := new(big.Float).SetPrec(256).SetString(strings.Replace(token, ",", ".", 1))
+	if !ok || !value.IsInt() || value.Sign() < 0 {
+		return "", false
+	}
+	integer, _ := value.Int(nil)
+	return integer.String(), true
+}
+
+func isDigits(val string) bool {
+	if val == "" {
+		return false
+	}
+	for _, r := range val {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// OrderTokenIndex hält die Orders einer Validierung für exakten, normalisierten und unscharfen Abgleich.
+type OrderTokenIndex struct {
+	exact      map[string]ExternalOrder
+	normalized map[string][]ExternalOrder
+	sortedKeys []string
+	byPrefix   map[string][]string
+	bySuffix   map[string][]string
+	byLength   map[int][]string
+}
+
+// NewOrderTokenIndex indiziert alle Orders mit Token; bei doppelten Tokens gewinnt das erste Vorkommen.
+func NewOrderTokenIndex(orderSets ...[]ExternalOrder) *OrderTokenIndex {
+	idx := &OrderTokenIndex{
+		exact:      map[string]ExternalOrder{},
+		normalized: map[string][]ExternalOrder{},
+		byPrefix:   map[string][]string{},
+		bySuffix:   map[string][]string{},
+		byLength:   map[int][]string{},
+	}
+	for _, orders := range orderSets {
+		for _, o := range orders {
+			token := strings.TrimSpace(o.OrderToken)
+			if token == "" {
+				continue
+			}
+			if _, seen := idx.exact[token]; seen {
+				continue
+			}
+			idx.exact[token] = o
+			key := NormalizeOrderToken(token)
+			if key == "" {
+				continue
+			}
+			if _, seen := idx.normalized[key]; !seen {
+				idx.sortedKeys = append(idx.sortedKeys, key)
+				idx.byPrefix[tokenPrefix(key)] = append(idx.byPrefix[tokenPrefix(key)], key)
+				idx.bySuffix[tokenSuffix(key)] = append(idx.bySuffix[tokenSuffix(key)], key)
+				if isDigits(key) {
+					idx.byLength[len(key)] = append(idx.byLength[len(key)], key)
+				}
+			}
+			idx.normalized[key] = append(idx.normalized[key], o)
+		}
+	}
+	sort.Strings(idx.sortedKeys)
+	return idx
+}
+
+func tokenPrefix(key string) string {
+	return key[:min(len(key), orderTokenBucketKeyLength)]
+}
+
+func tokenSuffix(key string) string {
+	return key[max(0, len(key)-orderTokenBucketKeyLength):]
+}
+
+// Len liefert die Anzahl indizierter Tokens.
+func (idx *OrderTokenIndex) Len() int {
+	return len(idx.exact)
+}
+
+// Match sucht die Order zu einem Token der Datei: erst exakt, dann normalisiert. Normalisiert zählt nur ein
+// eindeutiger Treffer; mehrdeutige Treffer kommen über Suggest als Vorschläge zurück.
+func (idx *OrderTokenIndex) Match(raw string) (ExternalOrder, string, bool) {
+	token := strings.TrimSpace(raw)
+	if token == "" {
+		return ExternalOrder{}, "", false
+	}
+	if o, ok := idx.exact[token]; ok {
+		return o, OrderTokenMatchExact, true
+	}
+	if matches := idx.normalized[NormalizeOrderToken(token)]; len(matches) == 1 {
+		return matches[0], OrderTokenMatchNormalized, true
+	}
+	return ExternalOrder{}, "", false
+}
+
+// Suggest liefert bis zu drei ähnliche Order-Tokens mit Konfidenz (0–1): mehrdeutig normalisiert, durch
+// wissenschaftliche Notation gekürzte Zahlen, Präfix-Treffer und kleine Editierdistanz.
+func (idx *OrderTokenIndex) Suggest(raw string) []models.ValueSuggestion {
+	key := NormalizeOrderToken(raw)
+	if key == "" || len(idx.sortedKeys) == 0 {
+		return nil
+	}
+	best := map[string]models.ValueSuggestion{}
+	add := func(candidateKey string, confidence float64, reason string) {
+		if confidence < orderTokenMinConfidence {
+			return
+		}
+		for _, o := range idx.normalized[candidateKey] {
+			value := strings.TrimSpace(o.OrderToken)
+			if current, ok := best[value]; !ok || confidence > current.Confidence {
+				best[value] = models.ValueSuggestion{Value: value, Confidence: math.Round(confidence*100) / 100, Reason: reason}
+			}
+		}
+	}
+
+	if len(idx.normalized[key]) > 1 {
+		add(key, 0.95, OrderTokenMatchNormalized)
+	}
+	idx.suggestScientific(strings.TrimSpace(raw), add)
+	idx.suggestPrefix(key, add)
+	idx.suggestEditDistance(key, add)
+
+	suggestions := make([]models.ValueSuggestion, 0, len(best))
+	for _, s := range best {
+		suggestions = append(suggestions, s)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Confidence != suggestions[j].Confidence {
+			return suggestions[i].Confidence > suggestions[j].Confidence
+		}
+		return suggestions[i].Value < suggestions[j].Value
+	})
+	if len(suggestions) > orderTokenSuggestionLimit {
+		suggestions = suggestions[:orderTokenSuggestionLimit]
+	}
+	return suggestions
+}
+
+// suggestScientific findet zu einer von Excel gekürzten Zahl ("1,23457E+11") Tokens gleicher Länge, die auf
+// dieselben signifikanten Stellen runden.
+func (idx *OrderTokenIndex) suggestScientific(raw string, add func(string, float64, string)) {
+	m := scientificTokenPattern.FindStringSubmatch(strings.ToUpper(strings.ReplaceAll(raw, " ", "")))
+	if m == nil {
+		return
+	}
+	mantissa := strings.TrimLeft(m[1]+m[2], "0")
+	exponent, err := strconv.Atoi(m[3])
+	if err != nil || mantissa == "" {
+		return
+	}
+	length := len(strings.TrimLeft(m[1], "0")) + exponent
+	if length < len(mantissa) || length > 40 {
+		return
+	}
+	want, _ := new(big.Int).SetString(mantissa, 10)
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(length-len(mantissa))), nil)
+	half := new(big.Int).Div(scale, big.NewInt(2))
+	for _, candidate := range idx.byLength[length] {
+		value, ok := new(big.Int).SetString(candidate, 10)
+		if !ok {
+			continue
+		}
+		rounded := new(big.Int).Div(value.Add(value, half), scale)
+		if rounded.Cmp(want) == 0 {
+			add(candidate, 0.7+0.25*float64(len(mantissa))/float64(length), OrderTokenMatchScientific)
+		}
+	}
+}
+
+// suggestPrefix findet Tokens, die mit dem Datei-Token beginnen (abgeschnitten) oder dessen Anfang sind.
+func (idx *OrderTokenIndex) suggestPrefix(key string, add func(string, float64, string)) {
+	if len(key) < orderTokenMinPrefixLength {
+		return
+	}
+	start := sort.SearchStrings(idx.sortedKeys, key)
+	for i := start; i < len(idx.sortedKeys) && i < start+orderTokenMaxBucketCompares; i++ {
+		candidate := idx.sortedKeys[i]
+		if !strings.HasPrefix(candidate, key) {
+			break
+		}
+		if candidate != key {
+			add(candidate, float64(len(key))/float64(len(candidate)), OrderTokenMatchPrefix)
+		}
+	}
+	for n := len(key) - 1; n >= orderTokenMinPrefixLength; n-- {
+		if _, ok := idx.normalized[key[:n]]; ok {
+			add(key[:n], float64(n)/float64(len(key)), OrderTokenMatchPrefix)
+		}
+	}
+}
+
+// suggestEditDistance vergleicht nur Tokens mit gleichem Anfang oder Ende (ein Tippfehler lässt meist eine
+// Seite intakt) und ähnlicher Länge; erlaubt sind 1 (bis 6 Zeichen), 2 (bis 12) bzw. 3 Abweichungen.
+func (idx *OrderTokenIndex) suggestEditDistance(key string, add func(string, float64, string)) {
+	maxDist := 3
+	switch {
+	case len(key) <= 6:
+		maxDist = 1
+	case len(key) <= 12:
+		maxDist = 2
+	}
+	seen := map[string]bool{key: true}
+	compared := 0
+	for _, bucket := range [][]string{idx.byPrefix[tokenPrefix(key)], idx.bySuffix[tokenSuffix(key)]} {
+		for _, candidate := range bucket {
+			if seen[candidate] || compared >= orderTokenMaxBucketCompares {
+				continue
+			}
+			seen[candidate] = true
+			if diff := len(candidate) - len(key); diff > maxDist || -diff > maxDist {
+				continue
+			}
+			compared++
+			if dist := levenshtein(key, candidate); dist <= maxDist {
+				add(candidate, 1-float64(dist)/float64(max(len(key), len(candidate))), OrderTokenMatchEdit)
+			}
+		}
+	}
+}
+
+// OrderTokenSuggestionNote formuliert den besten Vorschlag als Hinweis für die Zelle.
+func OrderTokenSuggestionNote(suggestions []models.ValueSuggestion) string {
+	if len(suggestions) == 0 {
+		return "Ordertoken nicht im Netzwerk gefunden"
+	}
+	return fmt.Sprintf("Meinten Sie %q? (Konfidenz %d %%)", suggestions[0].Value, int(math.Round(suggestions[0].Confidence*100)))
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"testing"
+
+	"nba-dashboard/internal/models"
+)
+
+func TestNormalizeOrderToken(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"12345", "12345"},
+		{" 0012345 ", "12345"},
+		{"12345.0", "12345"},
+		{"12345,00", "12345"},
+		{"1.23457E+11", "123457000000"},
+		{"1,2345E+4", "12345"},
+		{"1.2345e4", "12345"},
+		{"'00042", "42"},
+		{`"4711"`, "4711"},
+		{"000", "0"},
+		{"abc-12", "ABC-12"},
+		{"0A12", "0A12"},
+		{"ab\u200bc d", "ABCD"},
+		{"1.5E+0", "1.5E+0"},
+		{"-1E+3", "-1E+3"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			if got := NormalizeOrderToken(tt.raw); got != tt.want {
+				t.Errorf("NormalizeOrderToken(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeOrderTokenCollisions(t *testing.T) {
+	tests := []struct {
+		name   string
+		tokens []string
+		same   bool
+	}{
+		{"führende nullen", []string{"0012345", "012345", "12345"}, true},
+		{"wissenschaftliche notation", []string{"1,2345E+4", "1.2345E+04", "12345"}, true},
+		{"excel-dezimal", []string{"12345.0", "12345,000", "12345"}, true},
+		{"groß-/kleinschreibung und leerzeichen", []string{"ab 12", "AB12", " aB12 "}, true},
+		{"alphanumerisch behält nullen", []string{"0A12", "A12"}, false},
+		{"gekürzte notation ist nicht gleich", []string{"1.23457E+11", "123456789012"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := NormalizeOrderToken(tt.tokens[0])
+			for _, token := range tt.tokens[1:] {
+				if got := NormalizeOrderToken(token) == first; got != tt.same {
+					t.Errorf("%q vs %q: same = %v, want %v", tt.tokens[0], token, got, tt.same)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderTokenIndexMatch(t *testing.T) {
+	idx := NewOrderTokenIndex([]ExternalOrder{
+		{OrderToken: "A-100"},
+		{OrderToken: "0012345"},
+		{OrderToken: "012"},
+		{OrderToken: "12"},
+	})
+	tests := []struct {
+		raw       string
+		wantToken string
+		wantKind  string
+		wantOK    bool
+	}{
+		{"A-100", "A-100", OrderTokenMatchExact, true},
+		{" a-100 ", "A-100", OrderTokenMatchNormalized, true},
+		{"12345", "0012345", OrderTokenMatchNormalized, true},
+		{"1,2345E+4", "0012345", OrderTokenMatchNormalized, true},
+		{"12", "12", OrderTokenMatchExact, true},
+		{"0012", "", "", false},
+		{"B-100", "", "", false},
+		{"", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			order, kind, ok := idx.Match(tt.raw)
+			if ok != tt.wantOK || kind != tt.wantKind || order.OrderToken != tt.wantToken {
+				t.Errorf("Match(%q) = (%q, %q, %v), want (%q, %q, %v)", tt.raw, order.OrderToken, kind, ok, tt.wantToken, tt.wantKind, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestOrderTokenIndexSuggest(t *testing.T) {
+	idx := NewOrderTokenIndex([]ExternalOrder{
+		{OrderToken: "012"},
+		{OrderToken: "12"},
+		{OrderToken: "123456789012"},
+		{OrderToken: "ABCDEF123"},
+		{OrderToken: "ORD-55821"},
+	})
+	tests := []struct {
+		name       string
+		raw        string
+		wantValues []string
+		wantReason string
+	}{
+		{"mehrdeutig normalisiert", "0012", []string{"012", "12"}, OrderTokenMatchNormalized},
+		{"von excel gekürzt", "1,23457E+11", []string{"123456789012"}, OrderTokenMatchScientific},
+		{"abgeschnitten", "ABCDEF12", []string{"ABCDEF123"}, OrderTokenMatchPrefix},
+		{"tippfehler", "ORD-55812", []string{"ORD-55821"}, OrderTokenMatchEdit},
+		{"kein ähnlicher token", "ZZZ-999", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suggestions := idx.Suggest(tt.raw)
+			if len(suggestions) != len(tt.wantValues) {
+				t.Fatalf("Suggest(%q) = %+v, want values %v", tt.raw, suggestions, tt.wantValues)
+			}
+			for i, s := range suggestions {
+				if s.Value != tt.wantValues[i] || s.Reason != tt.wantReason {
+					t.Errorf("suggestion %d = %+v, want value %q reason %q", i, s, tt.wantValues[i], tt.wantReason)
+				}
+				if s.Confidence < orderTokenMinConfidence || s.Confidence > 1 {
+					t.Errorf("suggestion %d confidence %v out of range", i, s.Confidence)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderTokenIndexSuggestLimit(t *testing.T) {
+	idx := NewOrderTokenIndex([]ExternalOrder{
+		{OrderToken: "ORDER1001"},
+		{OrderToken: "ORDER1002"},
+		{OrderToken: "ORDER1003"},
+		{OrderToken: "ORDER1004"},
+		{OrderToken: "ORDER1005"},
+	})
+	suggestions := idx.Suggest("ORDER1000")
+	if len(suggestions) != orderTokenSuggestionLimit {
+		t.Fatalf("Suggest returned %d suggestions, want %d", len(suggestions), orderTokenSuggestionLimit)
+	}
+	for i := 1; i < len(suggestions); i++ {
+		if suggestions[i].Confidence > suggestions[i-1].Confidence {
+			t.Errorf("suggestions not sorted by confidence: %+v", suggestions)
+		}
+	}
+}
+
+func TestOrderTokenSuggestionNote(t *testing.T) {
+	tests := []struct {
+		suggestions []models.ValueSuggestion
+		want        string
+	}{
+		{nil, "Ordertoken nicht im Netzwerk gefunden"},
+		{[]models.ValueSuggestion{{Value: "A-100", Confidence: 0.915}}, `Meinten Sie "A-100"? (Konfidenz 92 %)`},
+	}
+	for _, tt := range tests {
+		if got := OrderTokenSuggestionNote(tt.suggestions); got != tt.want {
+			t.Errorf("OrderTokenSuggestionNote(%+v) = %q, want %q", tt.suggestions, got, tt.want)
+		}
+	}
+}
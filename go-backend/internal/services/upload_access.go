@@ -0,0 +1,80 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"nba-dashboard/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GrantUploadAccess legt eine Advertiser-Freigabe an oder aktualisiert deren Ablaufdatum.
+// Bestehende Freigaben anderer Advertiser bleiben unberührt; eine erneute Freigabe
+// setzt einen bereits verarbeiteten Ablauf (ExpiredAt) und den Bearbeitungsstand auf "assigned" zurück.
+func GrantUploadAccess(tx *gorm.DB, uploadID uint, advertiserID uint, expiresAt *time.Time) error {
+	access := models.UploadAccess{
+		UploadID:     uploadID,
+		AdvertiserID: advertiserID,
+		ExpiresAt:    expiresAt,
+		Status:       models.UploadAccessStatusAssigned,
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "upload_id"},
+			{Name: "advertiser_id"},
+		},
+		DoUpdates: clause.AssignmentColumns([]string{"expires_at", "expired_at", "status", "updated_at"}),
+	}).Create(&access).Error
+}
+
+// MarkUploadAccessProgress hält den Bearbeitungsstand eines Advertisers an einem Upload fest.
+func MarkUploadAccessProgress(tx *gorm.DB, uploadID uint, advertiserEmail string, status string) error {
+	now := time.Now()
+	return tx.Model(&models.UploadAccess{}).
+		Where("upload_id = ? AND advertiser_id IN (?)", uploadID,
+			tx.Model(&models.User{}).Select("id").Where("email = ? AND role = ?", advertiserEmail, "advertiser")).
+		Updates(map[string]any{
+			"status":           status,
+			"last_activity_at": &now,
+		}).Error
+}
+
+// UploadAdvertiserIDs liefert die Advertiser eines Uploads: aktive Freigaben und bei
+// Advertiser-Manuellanfragen der anlegende Advertiser selbst.
+func UploadAdvertiserIDs(tx *gorm.DB, upload models.Upload, now time.Time) ([]uint, error) {
+	var advertiserIDs []uint
+	if err := tx.Model(&models.UploadAccess{}).
+		Where("upload_id = ? AND expired_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", upload.ID, now).
+		Order("advertiser_id ASC").
+		Pluck("advertiser_id", &advertiserIDs).Error; err != nil {
+		return nil, err
+	}
+	if IsAdvertiserManualRequest(upload) {
+		var ownerIDs []uint
+		if err := tx.Model(&models.User{}).Where("email = ? AND role = ?", upload.UploadedBy, "advertiser").Pluck("id", &ownerIDs).Error; err != nil {
+			return nil, err
+		}
+		advertiserIDs = append(advertiserIDs, ownerIDs...)
+	}
+	return advertiserIDs, nil
+}
+
+// HasActiveUploadAccess prüft, ob ein Advertiser eine nicht abgelaufene Freigabe für den Upload hat.
+func HasActiveUploadAccess(db *gorm.DB, uploadID uint, advertiserID uint) (bool, error) {
+	var access models.UploadAccess
+	err := db.Where(
+		"upload_id = ? AND advertiser_id = ? AND (expires_at IS NULL OR expires_at > ?)",
+		uploadID,
+		advertiserID,
+		time.Now(),
+	).First(&access).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"nba-dashboard/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// UploadAccessExpiryProcessor verarbeitet abgelaufene Advertiser-Freigaben im Hintergrund:
+// Freigabe markieren, Audit-Event schreiben und den Upload ggf. in den Ablauf-Status schieben.
+type UploadAccessExpiryProcessor struct {
+	db            *gorm.DB
+	pollInterval  time.Duration
+	initialDelay  time.Duration
+	batchSize     int
+	expiredStatus string
+}
+
+type UploadAccessExpiryMetrics struct {
+	Enabled             bool      `json:"enabled"`
+	StartedAt           time.Time `json:"started_at"`
+	PollIntervalSeconds int       `json:"poll_interval_seconds"`
+	ExpiredStatus       string    `json:"expired_status"`
+	LastTickAt          time.Time `json:"last_tick_at"`
+	LastTickExpired     int       `json:"last_tick_expired"`
+	TotalExpired        int64     `json:"total_expired"`
+	TotalUploadsMoved   int64     `json:"total_uploads_moved"`
+	LastError           string    `json:"last_error"`
+}
+
+var (
+	expiryMetricsMu sync.Mutex
+	expiryMetrics   = UploadAccessExpiryMetrics{}
+)
+
+// StartUploadAccessExpiryProcessor startet den Prozessor; er endet mit ctx (App-Lebenszyklus).
+func StartUploadAccessExpiryProcessor(ctx context.Context, db *gorm.DB) {
+	if !envEnabled("UPLOAD_ACCESS_EXPIRY_ENABLED", true) {
+		log.Println("ℹ️ Upload access expiry processor disabled via UPLOAD_ACCESS_EXPIRY_ENABLED")
+		return
+	}
+
+	p := &UploadAccessExpiryProcessor{
+		db:            db,
+		pollInterval:  envDurationSeconds("UPLOAD_ACCESS_EXPIRY_POLL_SECONDS", 300),
+		initialDelay:  envDurationSeconds("UPLOAD_ACCESS_EXPIRY_INITIAL_DELAY_SECONDS", 20),
+		batchSize:     envInt("UPLOAD_ACCESS_EXPIRY_BATCH_SIZE", 200),
+		expiredStatus: uploadAccessExpiredStatus(),
+	}
+	if p.pollInterval < 15*time.Second {
+		p.pollInterval = 15 * time.Second
+	}
+	if p.batchSize <= 0 {
+		p.batchSize = 200
+	}
+
+	goBackgroundJob(func() { p.run(ctx) })
+	log.Printf("✅ Upload access expiry processor started (poll=%s, status=%s)", p.pollInterval, p.expiredStatus)
+
+	expiryMetricsMu.Lock()
+	expiryMetrics.Enabled = true
+	expiryMetrics.StartedAt = time.Now()
+	expiryMetrics.PollIntervalSeconds = int(p.pollInterval.Seconds())
+	expiryMetrics.ExpiredStatus = p.expiredStatus
+	expiryMetricsMu.Unlock()
+}
+
+func (p *UploadAccessExpiryProcessor) run(ctx context.Context) {
+	if p.initialDelay > 0 {
+		select {
+		case <-time.After(p.initialDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	p.tick(ctx)
+	ticker := time.NewTicker(p.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.tick(ctx)
+		}
+	}
+}
+
+func (p *UploadAccessExpiryProcessor) tick(ctx context.Context) {
+	now := time.Now()
+	var expired []models.UploadAccess
+	if err := p.db.WithContext(ctx).
+		Where("expires_at IS NOT NULL AND expires_at <= ? AND expired_at IS NULL", now).
+		Order("expires_at asc, id asc").
+		Limit(p.batchSize).
+		Find(&expired).Error; err != nil {
+		log.Printf("❌ expiry processor failed to load grants: %v", err)
+		recordExpiryTick(now, 0, 0, err.Error())
+		return
+	}
+
+	uploadOrder, byUpload := groupExpiredGrants(expired)
+
+	processed := 0
+	moved := 0
+	lastErr := ""
+	for _, uploadID := range uploadOrder {
+		// Beim Herunterfahren keinen weiteren Upload anfangen; der Rest folgt im nächsten Lauf.
+		if ctx.Err() != nil {
+			break
+		}
+		didMove, err := p.expireUploadGrants(ctx, uploadID, byUpload[uploadID], now)
+		if err != nil {
+			log.Printf("❌ expiry processor failed for upload=%d: %v", uploadID, err)
+			lastErr = err.Error()
+			continue
+		}
+		processed += len(byUpload[uploadID])
+		if didMove {
+			moved++
+		}
+	}
+	if processed > 0 {
+		log.Printf("✅ expiry processor expired grants=%d uploads_moved=%d", processed, moved)
+	}
+	recordExpiryTick(now, processed, moved, lastErr)
+}
+
+// groupExpiredGrants gruppiert abgelaufene Freigaben je Upload, in der Reihenfolge des ersten Auftretens.
+func groupExpiredGrants(expired []models.UploadAccess) ([]uint, map[uint][]models.UploadAccess) {
+	byUpload := map[uint][]models.UploadAccess{}
+	uploadOrder := make([]uint, 0)
+	for _, access := range expired {
+		if _, seen := byUpload[access.UploadID]; !seen {
+			uploadOrder = append(uploadOrder, access.UploadID)
+		}
+		byUpload[access.UploadID] = append(byUpload[access.UploadID], access)
+	}
+	return uploadOrder, byUpload
+}
+
+// uploadAccessExpiryMovesUpload meldet, ob ein Upload nach Ablauf seiner Freigaben in den Ablauf-Status
+// wechselt: nur zugewiesene Uploads ohne weitere aktive Freigabe.
+func uploadAccessExpiryMovesUpload(upload models.Upload, activeGrants int64) bool {
+	return upload.Status == models.UploadStatusAssigned && activeGrants == 0
+}
+
+func (p *UploadAccessExpiryProcessor) expireUploadGrants(ctx context.Context, uploadID uint, grants []models.UploadAccess, now time.Time) (bool, error) {
+	moved := false
+	// Eine begonnene Transaktion läuft auch beim Herunterfahren zu Ende.
+	err := p.db.WithContext(context.WithoutCancel(ctx)).Transaction(func(tx *gorm.DB) error {
+		for _, access := range grants {
+			result := tx.Model(&models.UploadAccess{}).
+				Where("id = ? AND expired_at IS NULL", access.ID).
+				Update("expired_at", now)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				continue
+			}
+			if err := CreateAuditEvent(tx, nil, "UPLOAD_ACCESS_EXPIRED", "upload", uploadID, "", map[string]any{
+				"advertiser_id": access.AdvertiserID,
+				"expires_at":    access.ExpiresAt,
+				"status":        access.Status,
+			}, nil, map[string]any{
+				"source": "upload_access_expiry_processor",
+			}); err != nil {
+				return err
+			}
+		}
+
+		var upload models.Upload
+		if err := tx.First(&upload, uploadID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			return err
+		}
+		if upload.Status != models.UploadStatusAssigned {
+			return nil
+		}
+
+		var active int64
+		if err := tx.Model(&models.UploadAccess{}).
+			Where("upload_id = ? AND (expires_at IS NULL OR expires_at > ?)", uploadID, now).
+			Count(&active).Error; err != nil {
+			return err
+		}
+		if !uploadAccessExpiryMovesUpload(upload, active) {
+			return nil
+		}
+
+		actor := WorkflowActor{Role: WorkflowRoleSystem, Email: "system:upload-access-expiry"}
+		if err := TransitionUploadStatus(tx, &upload, p.expiredStatus, actor, "access_expired"); err != nil {
+			return err
+		}
+		moved = true
+		return nil
+	})
+	return moved, err
+}
+
+// UpcomingUploadAccessExpiry ist eine bald ablaufende Freigabe für die Admin-Übersicht.
+type UpcomingUploadAccessExpiry struct {
+	UploadID        uint      `json:"uploadId"`
+	Filename        string    `json:"filename"`
+	UploadStatus    string    `json:"uploadStatus"`
+	AdvertiserID    uint      `json:"advertiserId"`
+	AdvertiserEmail string    `json:"advertiserEmail"`
+	ExpiresAt       time.Time `json:"expiresAt"`
+	AccessStatus    string    `json:"accessStatus"`
+}
+
+// ListUpcomingUploadAccessExpiries liefert alle noch aktiven Freigaben, die bis "until" ablaufen.
+func ListUpcomingUploadAccessExpiries(db *gorm.DB, now time.Time, until time.Time) ([]UpcomingUploadAccessExpiry, error) {
+	out := []UpcomingUploadAccessExpiry{}
+	err := db.Table("upload_accesses AS ua").
+		Select("ua.upload_id AS upload_id, u.filename AS filename, u.status AS upload_status, ua.advertiser_id AS advertiser_id, adv.email AS advertiser_email, ua.expires_at AS expires_at, ua.status AS access_status").
+		Joins("JOIN uploads AS u ON u.id = ua.upload_id AND u.deleted_at IS NULL").
+		Joins("LEFT JOIN users AS adv ON adv.id = ua.advertiser_id").
+		Where("ua.expires_at IS NOT NULL AND ua.expires_at > ? AND ua.expires_at <= ?", now, until).
+		Order("ua.expires_at ASC, ua.upload_id ASC").
+		Scan(&out).Error
+	return out, err
+}
+
+func GetUploadAccessExpiryMetrics() UploadAccessExpiryMetrics {
+	expiryMetricsMu.Lock()
+	defer expiryMetricsMu.Unlock()
+	return expiryMetrics
+}
+
+func recordExpiryTick(at time.Time, expired int, moved int, lastErr string) {
+	expiryMetricsMu.Lock()
+	defer expiryMetricsMu.Unlock()
+	expiryMetrics.LastTickAt = at
+	expiryMetrics.LastTickExpired = expired
+	expiryMetrics.TotalExpired += int64(expired)
+	expiryMetrics.TotalUploadsMoved += int64(moved)
+	if lastErr != "" {
+		expiryMetrics.LastError = lastErr
+	}
+}
+
+func uploadAccessExpiredStatus() string {
+	status := strings.TrimSpace(os.Getenv("UPLOAD_ACCESS_EXPIRED_STATUS"))
+	switch status {
+	case "":
+		return models.UploadStatusAccessExpired
+	case models.UploadStatusAccessExpired, models.UploadStatusPending:
+		return status
+	default:
+		log.Printf("⚠️ UPLOAD_ACCESS_EXPIRED_STATUS=%q is not a valid workflow target, using %q", status, models.UploadStatusAccessExpired)
+		return models.UploadStatusAccessExpired
+	}
+}
+
+func envEnabled(key string, fallback bool) bool {
+	value := strings.TrimSpace(strings.ToLower(os.Getenv(key)))
+	if value == "" {
+		return fallback
+	}
+	return value == "1" || value == "true" || value == "yes" || value == "on"
+}
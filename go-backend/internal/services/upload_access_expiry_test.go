@@ -0,0 +1,63 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"nba-dashboard/internal/models"
+)
+
+func TestGroupExpiredGrants(t *testing.T) {
+	expired := []models.UploadAccess{
+		{ID: 1, UploadID: 7, AdvertiserID: 3},
+		{ID: 2, UploadID: 5, AdvertiserID: 3},
+		{ID: 3, UploadID: 7, AdvertiserID: 4},
+	}
+	order, byUpload := groupExpiredGrants(expired)
+	if !reflect.DeepEqual(order, []uint{7, 5}) {
+		t.Errorf("order = %v, want [7 5]", order)
+	}
+	if len(byUpload[7]) != 2 || byUpload[7][0].ID != 1 || byUpload[7][1].ID != 3 {
+		t.Errorf("grants for upload 7 = %+v", byUpload[7])
+	}
+	if len(byUpload[5]) != 1 || byUpload[5][0].ID != 2 {
+		t.Errorf("grants for upload 5 = %+v", byUpload[5])
+	}
+}
+
+func TestUploadAccessExpiryTransition(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		active int64
+		want   bool
+	}{
+		{"zugewiesen ohne aktive freigabe", models.UploadStatusAssigned, 0, true},
+		{"zugewiesen mit weiterer freigabe", models.UploadStatusAssigned, 1, false},
+		{"offen", models.UploadStatusPending, 0, false},
+		{"bereits abgelaufen", models.UploadStatusAccessExpired, 0, false},
+		{"abgeschlossen", models.UploadStatusApproved, 0, false},
+	}
+	for _, target := range []string{"", models.UploadStatusPending, "approved"} {
+		t.Setenv("UPLOAD_ACCESS_EXPIRED_STATUS", target)
+		expiredStatus := uploadAccessExpiredStatus()
+		if target != models.UploadStatusPending && expiredStatus != models.UploadStatusAccessExpired {
+			t.Fatalf("UPLOAD_ACCESS_EXPIRED_STATUS=%q: status = %q, want %q", target, expiredStatus, models.UploadStatusAccessExpired)
+		}
+		for _, tt := range tests {
+			t.Run(tt.name+"/"+expiredStatus, func(t *testing.T) {
+				upload := models.Upload{Status: tt.status}
+				got := uploadAccessExpiryMovesUpload(upload, tt.active)
+				if got != tt.want {
+					t.Fatalf("moves = %v, want %v", got, tt.want)
+				}
+				// Der Prozessor verschiebt nur über den Workflow; der Übergang muss dort erlaubt sein.
+				if got {
+					if err := CheckUploadTransition(upload, expiredStatus, WorkflowRoleSystem); err != nil {
+						t.Errorf("transition %s -> %s: %v", tt.status, expiredStatus, err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+
+	"nba-dashboard/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var commentMentionPattern = regexp.MustCompile(`(?:^|\s)@([A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,})`)
+
+// ParseCommentMentions liefert alle per "@user@example.com" erwähnten E-Mail-Adressen (klein, ohne Duplikate).
+func ParseCommentMentions(body string) []string {
+	seen := map[string]struct{}{}
+	out := []string{}
+	for _, match := range commentMentionPattern.FindAllStringSubmatch(body, -1) {
+		email := strings.ToLower(strings.TrimRight(match[1], "."))
+		if _, ok := seen[email]; ok {
+			continue
+		}
+		seen[email] = struct{}{}
+		out = append(out, email)
+	}
+	return out
+}
+
+// CanUserReadUpload prüft die Sichtbarkeit eines Uploads für einen User
+// (Admin, Uploader oder Advertiser mit aktiver Freigabe) – dieselben Regeln wie beim Inhalt.
+func CanUserReadUpload(db *gorm.DB, user models.User, upload models.Upload) (bool, error) {
+	if user.Role == "admin" || strings.EqualFold(user.Email, upload.UploadedBy) {
+		return true, nil
+	}
+	if user.Role != "advertiser" {
+		return false, nil
+	}
+	return HasActiveUploadAccess(db, upload.ID, user.ID)
+}
+
+// ResolveUploadCommentMentions löst erwähnte E-Mail-Adressen zu Usern auf. Adressen ohne User
+// oder ohne Sicht auf den Upload werden als invalid zurückgegeben.
+func ResolveUploadCommentMentions(db *gorm.DB, upload models.Upload, emails []string) ([]models.User, []string, error) {
+	if len(emails) == 0 {
+		return nil, nil, nil
+	}
+	var users []models.User
+	if err := db.Where("LOWER(email) IN ?", emails).Find(&users).Error; err != nil {
+		return nil, nil, err
+	}
+	byEmail := make(map[string]models.User, len(users))
+	for _, user := range users {
+		byEmail[strings.ToLower(user.Email)] = user
+	}
+
+	resolved := make([]models.User, 0, len(emails))
+	invalid := []string{}
+	for _, email := range emails {
+		user, ok := byEmail[email]
+		if !ok {
+			invalid = append(invalid, email)
+			continue
+		}
+		canRead, err := CanUserReadUpload(db, user, upload)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !canRead {
+			invalid = append(invalid, email)
+			continue
+		}
+		resolved = append(resolved, user)
+	}
+	return resolved, invalid, nil
+}
+
+// ReplaceUploadCommentMentions setzt die Erwähnungen eines Kommentars neu.
+func ReplaceUploadCommentMentions(tx *gorm.DB, commentID uint, users []models.User) error {
+	if err := tx.Where("comment_id = ?", commentID).Delete(&models.UploadCommentMention{}).Error; err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return nil
+	}
+	mentions := make([]models.UploadCommentMention, 0, len(users))
+	for _, user := range users {
+		mentions = append(mentions, models.UploadCommentMention{CommentID: commentID, UserID: user.ID})
+	}
+	return tx.Create(&mentions).Error
+}
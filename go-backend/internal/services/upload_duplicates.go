@@ -0,0 +1,117 @@
+package services
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"nba-dashboard/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Umgang mit inhaltsgleichen Uploads desselben Uploaders (UPLOAD_DUPLICATE_POLICY).
+const (
+	UploadDuplicatePolicyReject = "reject"
+	UploadDuplicatePolicyFlag   = "flag"
+)
+
+// UploadDuplicatePolicy liefert die konfigurierte Duplikat-Policy (Standard: reject).
+func UploadDuplicatePolicy() string {
+	policy := strings.TrimSpace(strings.ToLower(os.Getenv("UPLOAD_DUPLICATE_POLICY")))
+	switch policy {
+	case "":
+		return UploadDuplicatePolicyReject
+	case UploadDuplicatePolicyReject, UploadDuplicatePolicyFlag:
+		return policy
+	default:
+		log.Printf("⚠️ UPLOAD_DUPLICATE_POLICY=%q is invalid, using %q", policy, UploadDuplicatePolicyReject)
+		return UploadDuplicatePolicyReject
+	}
+}
+
+// UploadFileSHA256 berechnet den Hash einer gespeicherten Upload-Datei.
+func UploadFileSHA256(path string) (string, error) {
+	sum, _, err := fileSHA256(path)
+	return sum, err
+}
+
+// FindExactDuplicateUploads liefert nicht gelöschte Uploads desselben Uploaders mit identischem Inhalt.
+// Beim Scan abgelehnte Uploads in Quarantäne zählen nicht.
+func FindExactDuplicateUploads(db *gorm.DB, sha256 string, uploadedBy string, excludeID uint) ([]models.Upload, error) {
+	uploads := []models.Upload{}
+	if sha256 == "" {
+		return uploads, nil
+	}
+	err := db.Where("sha256 = ? AND uploaded_by = ? AND id <> ?", sha256, uploadedBy, excludeID).
+		Where("scan_status <> ?", models.UploadScanStatusRejected).
+		Order("created_at asc, id asc").
+		Find(&uploads).Error
+	return uploads, err
+}
+
+// NearDuplicateUpload ist ein anderer Upload mit überlappenden Ordertokens.
+type NearDuplicateUpload struct {
+	UploadID     uint      `json:"uploadId"`
+	Filename     string    `json:"filename"`
+	UploadedBy   string    `json:"uploadedBy"`
+	Status       string    `json:"status"`
+	CreatedAt    time.Time `json:"createdAt"`
+	SharedTokens int       `json:"sharedTokens"`
+	OtherTokens  int       `json:"otherTokens"`
+	Overlap      float64   `json:"overlap"`
+	SameContent  bool      `json:"sameContent"`
+}
+
+type NearDuplicateReport struct {
+	UploadID   uint                  `json:"uploadId"`
+	SHA256     string                `json:"sha256"`
+	Tokens     int                   `json:"tokens"`
+	Duplicates []NearDuplicateUpload `json:"duplicates"`
+}
+
+// FindNearDuplicateUploads listet die Uploads mit der größten Ordertoken-Überschneidung zum Upload.
+// Overlap ist der Anteil der eigenen Ordertokens, die auch im anderen Upload vorkommen.
+// Ist uploadedBy gesetzt, werden nur Uploads dieses Uploaders berücksichtigt; Split-Ausgangsdatei und
+// Teil-Uploads zählen nicht.
+func FindNearDuplicateUploads(db *gorm.DB, upload models.Upload, uploadedBy string, limit int) (NearDuplicateReport, error) {
+	report := NearDuplicateReport{UploadID: upload.ID, SHA256: upload.SHA256, Duplicates: []NearDuplicateUpload{}}
+
+	var tokens int64
+	if err := db.Model(&models.UploadOrderCandidate{}).
+		Where("upload_id = ? AND order_token <> ''", upload.ID).
+		Distinct("order_token").
+		Count(&tokens).Error; err != nil {
+		return report, err
+	}
+	report.Tokens = int(tokens)
+	if tokens == 0 {
+		return report, nil
+	}
+
+	ownTokens := db.Model(&models.UploadOrderCandidate{}).
+		Select("order_token").
+		Where("upload_id = ? AND order_token <> ''", upload.ID)
+	query := db.Table("upload_order_candidates AS c").
+		Select(`c.upload_id AS upload_id, u.filename AS filename, u.uploaded_by AS uploaded_by, u.status AS status, u.created_at AS created_at,
+			COUNT(DISTINCT c.order_token) AS shared_tokens,
+			(SELECT COUNT(DISTINCT o.order_token) FROM upload_order_candidates o WHERE o.upload_id = c.upload_id AND o.order_token <> '' AND o.deleted_at IS NULL) AS other_tokens,
+			(u.sha256 <> '' AND u.sha256 = ?) AS same_content`, upload.SHA256).
+		Joins("JOIN uploads AS u ON u.id = c.upload_id AND u.deleted_at IS NULL").
+		Where("c.upload_id <> ? AND c.deleted_at IS NULL AND c.order_token IN (?)", upload.ID, ownTokens).
+		Group("c.upload_id, u.filename, u.uploaded_by, u.status, u.created_at, u.sha256").
+		Order("shared_tokens DESC, c.upload_id DESC").
+		Limit(limit)
+	query = ExcludeSplitRelatives(query, "u", upload)
+	if uploadedBy != "" {
+		query = query.Where("u.uploaded_by = ?", uploadedBy)
+	}
+	if err := query.Scan(&report.Duplicates).Error; err != nil {
+		return report, err
+	}
+	for i := range report.Duplicates {
+		report.Duplicates[i].Overlap = float64(report.Duplicates[i].SharedTokens) / float64(tokens)
+	}
+	return report, nil
+}
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"nba-dashboard/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultUploadPageSize = 50
+	MaxUploadPageSize     = 200
+)
+
+// Sammelbegriffe für den Listenfilter "kind"; daneben sind alle models.UploadKind* direkt erlaubt.
+const (
+	UploadListKindFile          = "file"
+	UploadListKindManualRequest = "manual_request"
+)
+
+var uploadListKinds = map[string][]string{
+	UploadListKindFile:                       {models.UploadKindPublisherFile, models.UploadKindAdminUpload},
+	UploadListKindManualRequest:              {models.UploadKindPublisherManualRequest, models.UploadKindAdvertiserManualRequest},
+	models.UploadKindPublisherFile:           {models.UploadKindPublisherFile},
+	models.UploadKindPublisherManualRequest:  {models.UploadKindPublisherManualRequest},
+	models.UploadKindAdvertiserManualRequest: {models.UploadKindAdvertiserManualRequest},
+	models.UploadKindAdminUpload:             {models.UploadKindAdminUpload},
+}
+
+// IsValidUploadListKind prüft den Listenfilter "kind".
+func IsValidUploadListKind(kind string) bool {
+	_, ok := uploadListKinds[kind]
+	return ok
+}
+
+var ErrInvalidUploadCursor = errors.New("invalid cursor")
+
+// UploadListScope beschreibt, welche Uploads der anfragende User überhaupt sehen darf.
+type UploadListScope struct {
+	Role         string
+	Email        string
+	AdvertiserID uint
+}
+
+type UploadListFilter struct {
+	Statuses     []string
+	UploadedBy   string
+	AdvertiserID uint
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+	Search       string
+	Kind         string
+}
+
+type UploadListOptions struct {
+	Filter    UploadListFilter
+	SortField string
+	SortDesc  bool
+	Limit     int
+	Cursor    string
+}
+
+type UploadListPage struct {
+	Uploads    []models.Upload
+	NextCursor string
+}
+
+type uploadSortColumn struct {
+	column string
+	parse  func(raw string) (any, error)
+	format func(upload models.Upload) string
+}
+
+func parseCursorTime(raw string) (any, error) { return time.Parse(time.RFC3339Nano, raw) }
+
+func parseCursorString(raw string) (any, error) { return raw, nil }
+
+var uploadSortColumns = map[string]uploadSortColumn{
+	"created_at": {
+		column: "created_at",
+		parse:  parseCursorTime,
+		format: func(u models.Upload) string { return u.CreatedAt.UTC().Format(time.RFC3339Nano) },
+	},
+	"updated_at": {
+		column: "updated_at",
+		parse:  parseCursorTime,
+		format: func(u models.Upload) string { return u.UpdatedAt.UTC().Format(time.RFC3339Nano) },
+	},
+	"upload_date": {
+		column: "upload_date",
+		parse:  parseCursorTime,
+		format: func(u models.Upload) string { return u.UploadDate.UTC().Format(time.RFC3339Nano) },
+	},
+	"filename": {
+		column: "filename",
+		parse:  parseCursorString,
+		format: func(u models.Upload) string { return u.Filename },
+	},
+	"status": {
+		column: "status",
+		parse:  parseCursorString,
+		format: func(u models.Upload) string { return u.Status },
+	},
+	"file_size": {
+		column: "file_size",
+		parse:  func(raw string) (any, error) { return strconv.ParseInt(raw, 10, 64) },
+		format: func(u models.Upload) string { return strconv.FormatInt(u.FileSize, 10) },
+	},
+}
+
+// IsValidUploadSortField prüft den Sortierparameter gegen die erlaubten Spalten.
+func IsValidUploadSortField(field string) bool {
+	_, ok := uploadSortColumns[field]
+	return ok
+}
+
+type uploadCursor struct {
+	Value string `json:"v"`
+	ID    uint   `json:"id"`
+}
+
+func encodeUploadCursor(value string, id uint) string {
+	raw, _ := json.Marshal(uploadCursor{Value: value, ID: id})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeUploadCursor(cursor string) (uploadCursor, error) {
+	var out uploadCursor
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(cursor))
+	if err != nil {
+		return out, ErrInvalidUploadCursor
+	}
+	if err := json.Unmarshal(raw, &out); err != nil || out.ID == 0 {
+		return out, ErrInvalidUploadCursor
+	}
+	return out, nil
+}
+
+// ListUploads liefert eine stabil sortierte Seite von Uploads (Keyset-Pagination über Sortspalte + ID).
+// Limit <= 0 liefert alle Treffer ohne Cursor.
+func ListUploads(db *gorm.DB, scope UploadListScope, opts UploadListOptions) (UploadListPage, error) {
+	page := UploadListPage{Uploads: []models.Upload{}}
+
+	sortField := opts.SortField
+	if sortField == "" {
+		sortField = "created_at"
+	}
+	sortCol, ok := uploadSortColumns[sortField]
+	if !ok {
+		return page, fmt.Errorf("unsupported sort field %q", sortField)
+	}
+
+	query, err := scopedUploadQuery(db, scope)
+	if err != nil {
+		return page, err
+	}
+	query = applyUploadListFilter(query, opts.Filter)
+
+	direction := "ASC"
+	comparator := ">"
+	if opts.SortDesc {
+		direction = "DESC"
+		comparator = "<"
+	}
+
+	if strings.TrimSpace(opts.Cursor) != "" {
+		value, id, err := sortCol.cursorPosition(opts.Cursor)
+		if err != nil {
+			return page, err
+		}
+		query = query.Where(fmt.Sprintf("(uploads.%s, uploads.id) %s (?, ?)", sortCol.column, comparator), value, id)
+	}
+
+	query = query.Order(fmt.Sprintf("uploads.%s %s, uploads.id %s", sortCol.column, direction, direction))
+	if opts.Limit > 0 {
+		query = query.Limit(opts.Limit + 1)
+	}
+
+	var uploads []models.Upload
+	if err := query.Find(&uploads).Error; err != nil {
+		return page, err
+	}
+
+	page.Uploads, page.NextCursor = sortCol.paginate(uploads, opts.Limit)
+	return page, nil
+}
+
+// cursorPosition liest Sortwert und ID aus einem Cursor dieser Sortspalte.
+func (s uploadSortColumn) cursorPosition(cursor string) (any, uint, error) {
+	decoded, err := decodeUploadCursor(cursor)
+	if err != nil {
+		return nil, 0, err
+	}
+	value, err := s.parse(decoded.Value)
+	if err != nil {
+		return nil, 0, ErrInvalidUploadCursor
+	}
+	return value, decoded.ID, nil
+}
+
+// paginate kürzt die mit Limit+1 geladenen Uploads auf die Seite und liefert den Cursor ab der letzten
+// Zeile; ohne weitere Treffer bleibt der Cursor leer.
+func (s uploadSortColumn) paginate(uploads []models.Upload, limit int) ([]models.Upload, string) {
+	if limit <= 0 || len(uploads) <= limit {
+		return uploads, ""
+	}
+	uploads = uploads[:limit]
+	last := uploads[len(uploads)-1]
+	return uploads, encodeUploadCursor(s.format(last), last.ID)
+}
+
+func scopedUploadQuery(db *gorm.DB, scope UploadListScope) (*gorm.DB, error) {
+	query := db.Model(&models.Upload{})
+	switch scope.Role {
+	case "admin":
+		return query, nil
+	case "publisher":
+		return query.Where("uploads.uploaded_by = ?", scope.Email), nil
+	case "advertiser":
+		// Eigene Uploads plus zugewiesene Uploads, die nicht mehr "pending" sind.
+		return query.Where(
+			"(uploads.uploaded_by = ? OR (uploads.status <> ? AND EXISTS (SELECT 1 FROM upload_accesses ua WHERE ua.upload_id = uploads.id AND ua.advertiser_id = ?)))",
+			scope.Email,
+			models.UploadStatusPending,
+			scope.AdvertiserID,
+		), nil
+	default:
+		return nil, fmt.Errorf("role %q is not allowed to list uploads", scope.Role)
+	}
+}
+
+func applyUploadListFilter(query *gorm.DB, filter UploadListFilter) *gorm.DB {
+	if len(filter.Statuses) > 0 {
+		query = query.Where("uploads.status IN ?", filter.Statuses)
+	}
+	if v := strings.TrimSpace(filter.UploadedBy); v != "" {
+		query = query.Where("LOWER(uploads.uploaded_by) = LOWER(?)", v)
+	}
+	if filter.AdvertiserID > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM upload_accesses ua WHERE ua.upload_id = uploads.id AND ua.advertiser_id = ?)", filter.AdvertiserID)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("uploads.created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("uploads.created_at < ?", *filter.CreatedTo)
+	}
+	if v := strings.TrimSpace(filter.Search); v != "" {
+		query = query.Where("uploads.filename ILIKE ?", "%"+escapeLikePattern(v)+"%")
+	}
+	if kinds, ok := uploadListKinds[filter.Kind]; ok {
+		query = query.Where("uploads.kind IN ?", kinds)
+	}
+	return query
+}
+
+func escapeLikePattern(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
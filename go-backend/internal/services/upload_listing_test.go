@@ -0,0 +1,114 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"nba-dashboard/internal/models"
+)
+
+func TestUploadCursorRoundTrip(t *testing.T) {
+	upload := models.Upload{
+		ID:       42,
+		Filename: "Nachbuchung März.xlsx",
+		Status:   models.UploadStatusPending,
+		FileSize: 2048,
+	}
+	upload.CreatedAt = time.Date(2026, 3, 1, 14, 30, 0, 123456789, time.FixedZone("CET", 3600))
+	upload.UpdatedAt = upload.CreatedAt.Add(time.Hour)
+	upload.UploadDate = upload.CreatedAt
+
+	want := map[string]any{
+		"created_at":  upload.CreatedAt,
+		"updated_at":  upload.UpdatedAt,
+		"upload_date": upload.UploadDate,
+		"filename":    upload.Filename,
+		"status":      upload.Status,
+		"file_size":   upload.FileSize,
+	}
+	for field, col := range uploadSortColumns {
+		t.Run(field, func(t *testing.T) {
+			_, cursor := col.paginate([]models.Upload{upload, {ID: 43}}, 1)
+			if cursor == "" {
+				t.Fatal("expected a next cursor")
+			}
+			value, id, err := col.cursorPosition(cursor)
+			if err != nil {
+				t.Fatalf("cursorPosition: %v", err)
+			}
+			if id != upload.ID {
+				t.Errorf("id = %d, want %d", id, upload.ID)
+			}
+			if ts, ok := value.(time.Time); ok {
+				if !ts.Equal(want[field].(time.Time)) {
+					t.Errorf("value = %v, want %v", ts, want[field])
+				}
+				return
+			}
+			if value != want[field] {
+				t.Errorf("value = %#v, want %#v", value, want[field])
+			}
+		})
+	}
+}
+
+func TestUploadSortColumnPaginate(t *testing.T) {
+	col := uploadSortColumns["filename"]
+	uploads := []models.Upload{{ID: 1, Filename: "a.csv"}, {ID: 2, Filename: "b.csv"}, {ID: 3, Filename: "c.csv"}}
+
+	tests := []struct {
+		name       string
+		limit      int
+		wantIDs    []uint
+		wantCursor bool
+	}{
+		{"weitere seite", 2, []uint{1, 2}, true},
+		{"letzte seite", 3, []uint{1, 2, 3}, false},
+		{"ohne limit", 0, []uint{1, 2, 3}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, cursor := col.paginate(uploads, tt.limit)
+			ids := make([]uint, 0, len(page))
+			for _, u := range page {
+				ids = append(ids, u.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("ids = %v, want %v", ids, tt.wantIDs)
+			}
+			if (cursor != "") != tt.wantCursor {
+				t.Fatalf("cursor = %q, want cursor: %v", cursor, tt.wantCursor)
+			}
+			if cursor == "" {
+				return
+			}
+			value, id, err := col.cursorPosition(cursor)
+			if err != nil || value != "b.csv" || id != 2 {
+				t.Errorf("cursor position = (%v, %d, %v), want (b.csv, 2, nil)", value, id, err)
+			}
+		})
+	}
+}
+
+func TestUploadCursorInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		field  string
+		cursor string
+	}{
+		{"kein base64", "created_at", "%%%"},
+		{"kein json", "created_at", "bm9wZQ"},
+		{"id fehlt", "filename", encodeUploadCursor("a.csv", 0)},
+		{"zeit ungültig", "created_at", encodeUploadCursor("gestern", 5)},
+		{"größe ungültig", "file_size", encodeUploadCursor("groß", 5)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := uploadSortColumns[tt.field].cursorPosition(tt.cursor); !errors.Is(err, ErrInvalidUploadCursor) {
+				t.Errorf("err = %v, want ErrInvalidUploadCursor", err)
+			}
+		})
+	}
+}
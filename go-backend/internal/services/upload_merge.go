@@ -0,0 +1,79 @@
+package services
+
+import (
+	"nba-dashboard/internal/lib"
+	"nba-dashboard/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// MergedUploadOwner bestimmt Uploader und Herkunft der Sammeldatei: stammen alle Quellen vom selben
+// Uploader bzw. haben dieselbe Herkunft, wird das übernommen, sonst gilt der Admin als Uploader.
+func MergedUploadOwner(sources []models.Upload, adminEmail string) (uploadedBy string, kind string) {
+	uploadedBy, kind = sources[0].UploadedBy, sources[0].Kind
+	for _, source := range sources[1:] {
+		if source.UploadedBy != uploadedBy {
+			uploadedBy = adminEmail
+		}
+		if source.Kind != kind {
+			kind = models.UploadKindAdminUpload
+		}
+	}
+	return uploadedBy, kind
+}
+
+// CreateMergedUpload legt die Sammeldatei mit Anfangsstatus, Revision und Zeilenherkunft an und setzt
+// die Quellen auf "superseded". Die Datei muss bereits unter merged.FilePath liegen.
+func CreateMergedUpload(tx *gorm.DB, sources []models.Upload, merged *models.Upload, origins []lib.MergedRowOrigin, actor WorkflowActor) error {
+	merged.Status = models.UploadStatusPending
+	if err := tx.Create(merged).Error; err != nil {
+		return err
+	}
+	if err := RecordInitialUploadStatus(tx, *merged, actor, "merge"); err != nil {
+		return err
+	}
+	if _, err := RecordUploadRevision(tx, *merged, actor, "merge", nil); err != nil {
+		return err
+	}
+
+	rows := make([]models.UploadMergedRow, 0, len(origins))
+	for _, origin := range origins {
+		rows = append(rows, models.UploadMergedRow{
+			MergedUploadID: merged.ID,
+			RowNo:          origin.RowNo,
+			SourceUploadID: origin.SourceID,
+			SourceRowNo:    origin.SourceRowNo,
+			OrderToken:     origin.OrderToken,
+		})
+	}
+	if len(rows) > 0 {
+		if err := tx.CreateInBatches(&rows, 500).Error; err != nil {
+			return err
+		}
+	}
+
+	sourceIDs := make([]uint, 0, len(sources))
+	for i := range sources {
+		if err := TransitionUploadStatus(tx, &sources[i], models.UploadStatusSuperseded, actor, "merged"); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Upload{}).Where("id = ?", sources[i].ID).
+			Update("superseded_by_upload_id", merged.ID).Error; err != nil {
+			return err
+		}
+		sources[i].SupersededByUploadID = &merged.ID
+		sourceIDs = append(sourceIDs, sources[i].ID)
+	}
+
+	return CreateAuditEvent(tx, actor.UserID, "UPLOAD_MERGED", "upload", merged.ID, "", nil, map[string]any{
+		"source_upload_ids": sourceIDs,
+		"rows":              len(rows),
+	}, nil)
+}
+
+// ListMergedRows liefert die Zeilenherkunft eines zusammengeführten Uploads.
+func ListMergedRows(db *gorm.DB, mergedUploadID uint) ([]models.UploadMergedRow, error) {
+	var rows []models.UploadMergedRow
+	err := db.Where("merged_upload_id = ?", mergedUploadID).Order("row_no ASC").Find(&rows).Error
+	return rows, err
+}
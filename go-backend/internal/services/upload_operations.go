@@ -0,0 +1,123 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"nba-dashboard/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrUploadActionForbidden wird geliefert, wenn der User die Aktion an diesem Upload nicht ausführen darf.
+var ErrUploadActionForbidden = errors.New("not allowed for this upload")
+
+// ErrInvalidAdvertiserSelection meldet unbekannte oder nicht-Advertiser-IDs in einer Zuweisung.
+var ErrInvalidAdvertiserSelection = errors.New("invalid advertiser selection")
+
+// Aktionen, die einzeln und gesammelt (Bulk) auf Uploads ausgeführt werden können.
+const (
+	UploadActionAssign   = "assign"
+	UploadActionApprove  = "approve"
+	UploadActionReject   = "reject"
+	UploadActionComplete = "complete"
+	UploadActionDelete   = "delete"
+)
+
+type UploadAccessAssignment struct {
+	AdvertiserID uint       `json:"advertiserId"`
+	ExpiresAt    *time.Time `json:"expiresAt"`
+}
+
+// CheckUploadActionPermission bündelt die Rollenregeln der Einzel-Endpunkte.
+func CheckUploadActionPermission(db *gorm.DB, user models.User, upload models.Upload, action string) error {
+	switch action {
+	case UploadActionAssign, UploadActionApprove, UploadActionReject:
+		if user.Role == "admin" {
+			return nil
+		}
+	case UploadActionComplete:
+		switch user.Role {
+		case "publisher":
+			return nil
+		case "advertiser":
+			ok, err := HasActiveUploadAccess(db, upload.ID, user.ID)
+			if err != nil {
+				return err
+			}
+			if ok {
+				return nil
+			}
+		}
+	case UploadActionDelete:
+		if user.Role == "admin" || upload.UploadedBy == user.Email {
+			return nil
+		}
+		if user.Role == "advertiser" && IsAdvertiserManualRequest(upload) {
+			ok, err := HasActiveUploadAccess(db, upload.ID, user.ID)
+			if err != nil {
+				return err
+			}
+			if ok {
+				return nil
+			}
+		}
+	}
+	return ErrUploadActionForbidden
+}
+
+// ValidateUploadAccessAssignments prüft, dass alle Zuweisungen auf existierende Advertiser zeigen.
+func ValidateUploadAccessAssignments(db *gorm.DB, assignments []UploadAccessAssignment) error {
+	if len(assignments) == 0 {
+		return ErrInvalidAdvertiserSelection
+	}
+	advertiserIDs := make([]uint, 0, len(assignments))
+	seen := map[uint]struct{}{}
+	for _, a := range assignments {
+		if a.AdvertiserID == 0 {
+			return ErrInvalidAdvertiserSelection
+		}
+		if _, ok := seen[a.AdvertiserID]; !ok {
+			seen[a.AdvertiserID] = struct{}{}
+			advertiserIDs = append(advertiserIDs, a.AdvertiserID)
+		}
+	}
+	var count int64
+	if err := db.Model(&models.User{}).Where("id IN ? AND role = ?", advertiserIDs, "advertiser").Count(&count).Error; err != nil {
+		return err
+	}
+	if int(count) != len(advertiserIDs) {
+		return ErrInvalidAdvertiserSelection
+	}
+	return nil
+}
+
+// AssignUploadAccess legt die Freigaben an und schiebt den Upload in den passenden Folgestatus.
+func AssignUploadAccess(tx *gorm.DB, upload *models.Upload, assignments []UploadAccessAssignment, actor WorkflowActor) error {
+	nextStatus := models.UploadStatusAssigned
+	if IsAdvertiserManualRequest(*upload) && upload.Status == models.UploadStatusFeedback {
+		// Sonderfall: nach Publisher-Rücklauf sendet Admin final an Advertiser.
+		nextStatus = models.UploadStatusReturnedToPublisher
+	}
+	for _, a := range assignments {
+		if err := GrantUploadAccess(tx, upload.ID, a.AdvertiserID, a.ExpiresAt); err != nil {
+			return err
+		}
+	}
+	if err := TransitionUploadStatus(tx, upload, nextStatus, actor, "access_granted"); err != nil {
+		return err
+	}
+	// Neue Advertiser können eine strengere SLA-Regel mitbringen, auch ohne Statuswechsel.
+	return RefreshUploadSLADueAt(tx, upload)
+}
+
+// UpdateUploadStatus setzt approved/rejected/completed über den Workflow.
+func UpdateUploadStatus(tx *gorm.DB, upload *models.Upload, status string, actor WorkflowActor, reason string) error {
+	if err := TransitionUploadStatus(tx, upload, status, actor, reason); err != nil {
+		return err
+	}
+	if actor.Role == "advertiser" && status == models.UploadStatusCompleted {
+		return MarkUploadAccessProgress(tx, upload.ID, actor.Email, models.UploadAccessStatusCompleted)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"nba-dashboard/internal/models"
+)
+
+// Fälle, die eine aktive Advertiser-Freigabe prüfen, brauchen die Datenbank und fehlen hier.
+func TestCheckUploadActionPermission(t *testing.T) {
+	admin := models.User{ID: 1, Email: "admin@uppr.de", Role: "admin"}
+	publisher := models.User{ID: 2, Email: "pub@beispiel.de", Role: "publisher"}
+	advertiser := models.User{ID: 3, Email: "adv@beispiel.de", Role: "advertiser"}
+
+	publisherFile := models.Upload{ID: 10, UploadedBy: publisher.Email, Kind: models.UploadKindPublisherFile}
+	otherFile := models.Upload{ID: 11, UploadedBy: "other@beispiel.de", Kind: models.UploadKindPublisherFile}
+	manualRequest := models.Upload{ID: 12, UploadedBy: advertiser.Email, Kind: models.UploadKindAdvertiserManualRequest}
+
+	tests := []struct {
+		name    string
+		user    models.User
+		upload  models.Upload
+		action  string
+		allowed bool
+	}{
+		{"admin weist zu", admin, publisherFile, UploadActionAssign, true},
+		{"admin gibt frei", admin, publisherFile, UploadActionApprove, true},
+		{"admin lehnt ab", admin, publisherFile, UploadActionReject, true},
+		{"admin löscht fremde datei", admin, otherFile, UploadActionDelete, true},
+		{"admin schließt nicht ab", admin, publisherFile, UploadActionComplete, false},
+		{"publisher schließt ab", publisher, publisherFile, UploadActionComplete, true},
+		{"publisher löscht eigene datei", publisher, publisherFile, UploadActionDelete, true},
+		{"publisher löscht fremde datei nicht", publisher, otherFile, UploadActionDelete, false},
+		{"publisher gibt nicht frei", publisher, publisherFile, UploadActionApprove, false},
+		{"publisher weist nicht zu", publisher, publisherFile, UploadActionAssign, false},
+		{"advertiser löscht eigene anfrage", advertiser, manualRequest, UploadActionDelete, true},
+		{"advertiser löscht publisher-datei nicht", advertiser, publisherFile, UploadActionDelete, false},
+		{"advertiser lehnt nicht ab", advertiser, publisherFile, UploadActionReject, false},
+		{"unbekannte aktion", admin, publisherFile, "archive", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckUploadActionPermission(nil, tt.user, tt.upload, tt.action)
+			if tt.allowed && err != nil {
+				t.Errorf("expected action to be allowed, got %v", err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrUploadActionForbidden) {
+				t.Errorf("err = %v, want ErrUploadActionForbidden", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"nba-dashboard/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RecordUploadRevision legt den aktuellen Dateistand eines Uploads (FilePath, Name, Typ) als
+// neue Revision an. Die Datei unter FilePath darf danach nicht mehr überschrieben werden.
+// Die Revisionsnummer wird unter Sperre des Uploads vergeben (eindeutig je Upload über idx_upload_revision_no).
+func RecordUploadRevision(tx *gorm.DB, upload models.Upload, actor WorkflowActor, reason string, restoredFrom *uint) (models.UploadRevision, error) {
+	sum, size, err := fileSHA256(upload.FilePath)
+	if err != nil {
+		return models.UploadRevision{}, err
+	}
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Upload{}, upload.ID).Error; err != nil {
+		return models.UploadRevision{}, err
+	}
+	var maxNo int
+	if err := tx.Model(&models.UploadRevision{}).
+		Where("upload_id = ?", upload.ID).
+		Select("COALESCE(MAX(revision_no), 0)").
+		Scan(&maxNo).Error; err != nil {
+		return models.UploadRevision{}, err
+	}
+
+	revision := models.UploadRevision{
+		UploadID:               upload.ID,
+		RevisionNo:             maxNo + 1,
+		FilePath:               upload.FilePath,
+		Filename:               upload.Filename,
+		ContentType:            upload.ContentType,
+		FileSize:               size,
+		SHA256:                 sum,
+		AuthorUserID:           actor.UserID,
+		AuthorEmail:            strings.TrimSpace(actor.Email),
+		AuthorRole:             strings.ToLower(strings.TrimSpace(actor.Role)),
+		Reason:                 strings.TrimSpace(reason),
+		RestoredFromRevisionID: restoredFrom,
+	}
+	if err := tx.Create(&revision).Error; err != nil {
+		return models.UploadRevision{}, err
+	}
+	// Der Hash des aktuellen Stands dient der Duplikaterkennung beim Hochladen.
+	if err := tx.Model(&models.Upload{}).Where("id = ?", upload.ID).UpdateColumn("sha256", sum).Error; err != nil {
+		return models.UploadRevision{}, err
+	}
+	return revision, nil
+}
+
+// EnsureBaselineUploadRevision sichert bei Uploads aus der Zeit vor der Revisionshistorie
+// den bisherigen Dateistand als erste Revision, bevor er durch einen neuen ersetzt wird.
+func EnsureBaselineUploadRevision(tx *gorm.DB, upload models.Upload) error {
+	var count int64
+	if err := tx.Model(&models.UploadRevision{}).Where("upload_id = ?", upload.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	if _, err := os.Stat(upload.FilePath); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	actor := WorkflowActor{Email: upload.UploadedBy}
+	_, err := RecordUploadRevision(tx, upload, actor, "baseline", nil)
+	return err
+}
+
+// ListUploadRevisions liefert alle Revisionen eines Uploads, neueste zuerst.
+func ListUploadRevisions(db *gorm.DB, uploadID uint) ([]models.UploadRevision, error) {
+	revisions := []models.UploadRevision{}
+	err := db.Where("upload_id = ?", uploadID).Order("revision_no desc").Find(&revisions).Error
+	return revisions, err
+}
+
+// UploadRevisionFilePaths liefert alle (eindeutigen) Dateipfade der Revisionen eines Uploads.
+func UploadRevisionFilePaths(db *gorm.DB, uploadID uint) ([]string, error) {
+	var paths []string
+	err := db.Model(&models.UploadRevision{}).
+		Where("upload_id = ?", uploadID).
+		Distinct("file_path").
+		Pluck("file_path", &paths).Error
+	return paths, err
+}
+
+// UploadRevisionConflictError meldet, dass der Upload seit dem gelesenen Stand geändert wurde.
+type UploadRevisionConflictError struct {
+	ExpectedRevisionID uint
+	CurrentRevisionID  uint
+}
+
+func (e *UploadRevisionConflictError) Error() string {
+	return fmt.Sprintf("upload was modified: expected revision %d, current revision %d", e.ExpectedRevisionID, e.CurrentRevisionID)
+}
+
+// HeadUploadRevisionID liefert die ID der aktuellen Revision (0 bei Alt-Uploads ohne Revision).
+func HeadUploadRevisionID(db *gorm.DB, uploadID uint) (uint, error) {
+	var ids []uint
+	if err := db.Model(&models.UploadRevision{}).
+		Where("upload_id = ?", uploadID).
+		Order("revision_no desc").
+		Limit(1).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return ids[0], nil
+}
+
+// UploadRevisionETag formatiert eine Revisions-ID als ETag.
+func UploadRevisionETag(revisionID uint) string {
+	return fmt.Sprintf(`"rev-%d"`, revisionID)
+}
+
+// ParseUploadRevisionETag liest einen If-Match-Wert (`"rev-12"`, `W/"rev-12"` oder `12`).
+func ParseUploadRevisionETag(raw string) (uint, bool) {
+	value := strings.TrimSpace(raw)
+	value = strings.TrimPrefix(value, "W/")
+	value = strings.Trim(value, `"`)
+	value = strings.TrimPrefix(value, "rev-")
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// CheckUploadHeadRevision prüft, ob expected noch der aktuelle Stand ist.
+func CheckUploadHeadRevision(db *gorm.DB, uploadID uint, expected uint) error {
+	current, err := HeadUploadRevisionID(db, uploadID)
+	if err != nil {
+		return err
+	}
+	return compareUploadRevision(expected, current)
+}
+
+func compareUploadRevision(expected uint, current uint) error {
+	if current != expected {
+		return &UploadRevisionConflictError{ExpectedRevisionID: expected, CurrentRevisionID: current}
+	}
+	return nil
+}
+
+func fileSHA256(path string) (string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
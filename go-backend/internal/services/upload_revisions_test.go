@@ -0,0 +1,64 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseUploadRevisionETag(t *testing.T) {
+	tests := []struct {
+		raw    string
+		want   uint
+		wantOK bool
+	}{
+		{UploadRevisionETag(12), 12, true},
+		{`W/"rev-12"`, 12, true},
+		{" 12 ", 12, true},
+		{`"rev-"`, 0, false},
+		{`"rev-abc"`, 0, false},
+		{"*", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseUploadRevisionETag(tt.raw)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseUploadRevisionETag(%q) = (%d, %v), want (%d, %v)", tt.raw, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestCompareUploadRevision(t *testing.T) {
+	tests := []struct {
+		name     string
+		ifMatch  string
+		current  uint
+		conflict bool
+	}{
+		{"aktueller stand", UploadRevisionETag(7), 7, false},
+		{"alt-upload ohne revision", UploadRevisionETag(0), 0, false},
+		{"veralteter stand", UploadRevisionETag(6), 7, true},
+		{"revision verschwunden", UploadRevisionETag(7), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, ok := ParseUploadRevisionETag(tt.ifMatch)
+			if !ok {
+				t.Fatalf("ParseUploadRevisionETag(%q) failed", tt.ifMatch)
+			}
+			err := compareUploadRevision(expected, tt.current)
+			if !tt.conflict {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var conflict *UploadRevisionConflictError
+			if !errors.As(err, &conflict) {
+				t.Fatalf("err = %v, want UploadRevisionConflictError", err)
+			}
+			if conflict.ExpectedRevisionID != expected || conflict.CurrentRevisionID != tt.current {
+				t.Errorf("conflict = %+v, want expected=%d current=%d", conflict, expected, tt.current)
+			}
+		})
+	}
+}
@@ -0,0 +1,367 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"nba-dashboard/internal/lib"
+	"nba-dashboard/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// UploadScanner prüft eine hochgeladene Datei. Befunde führen zur Ablehnung,
+// ein Fehler bedeutet, dass der Scanner kein Urteil fällen konnte.
+type UploadScanner interface {
+	Name() string
+	Scan(ctx context.Context, path string) ([]string, error)
+}
+
+// UploadScanResult fasst die Ergebnisse aller konfigurierten Scanner zusammen.
+type UploadScanResult struct {
+	Status   string                     `json:"status"`
+	Findings []models.UploadScanFinding `json:"findings"`
+}
+
+const uploadQuarantineDir = "uploads/quarantine"
+
+// QuarantineUploadPath liefert den Quarantäne-Pfad für eine neu eingehende Datei.
+func QuarantineUploadPath(storedPath string) (string, error) {
+	if err := os.MkdirAll(uploadQuarantineDir, 0o755); err != nil {
+		return "", err
+	}
+	return filepath.Join(uploadQuarantineDir, filepath.Base(storedPath)), nil
+}
+
+// ReleaseQuarantinedFile verschiebt eine geprüfte Datei aus der Quarantäne in den Upload-Ordner.
+func ReleaseQuarantinedFile(path string) (string, error) {
+	if filepath.Dir(path) != filepath.Clean(uploadQuarantineDir) {
+		return path, nil
+	}
+	released := filepath.Join("uploads", filepath.Base(path))
+	if err := os.Rename(path, released); err != nil {
+		return path, err
+	}
+	return released, nil
+}
+
+// IsUploadQuarantined meldet Uploads, deren Datei (noch) nicht ausgeliefert werden darf.
+func IsUploadQuarantined(upload models.Upload) bool {
+	switch upload.ScanStatus {
+	case models.UploadScanStatusPending, models.UploadScanStatusRejected, models.UploadScanStatusError:
+		return true
+	default:
+		return false
+	}
+}
+
+// ConfiguredUploadScanners baut die Scanner aus UPLOAD_SCANNERS (Standard: xlsx_macros,csv_formula).
+func ConfiguredUploadScanners() []UploadScanner {
+	raw := strings.TrimSpace(os.Getenv("UPLOAD_SCANNERS"))
+	if raw == "" {
+		raw = "xlsx_macros,csv_formula"
+	}
+	scanners := make([]UploadScanner, 0, 3)
+	for _, name := range strings.Split(raw, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "", "none":
+		case "xlsx_macros":
+			scanners = append(scanners, SpreadsheetActiveContentScanner{})
+		case "csv_formula":
+			scanners = append(scanners, CSVFormulaScanner{})
+		case "clamav":
+			address := strings.TrimSpace(os.Getenv("CLAMAV_ADDRESS"))
+			if address == "" {
+				address = "tcp:127.0.0.1:3310"
+			}
+			scanners = append(scanners, ClamAVScanner{
+				Address: address,
+				Timeout: envDurationSeconds("CLAMAV_TIMEOUT_SECONDS", 30),
+			})
+		default:
+			log.Printf("⚠️ unknown upload scanner %q in UPLOAD_SCANNERS", name)
+		}
+	}
+	return scanners
+}
+
+// ScanUploadFile führt alle Scanner aus: Befund => rejected, Scannerfehler => error, sonst clean.
+func ScanUploadFile(ctx context.Context, scanners []UploadScanner, path string) UploadScanResult {
+	result := UploadScanResult{Status: models.UploadScanStatusClean, Findings: []models.UploadScanFinding{}}
+	failed := false
+	for _, scanner := range scanners {
+		findings, err := scanner.Scan(ctx, path)
+		if err != nil {
+			log.Printf("❌ upload scanner %s failed for %s: %v", scanner.Name(), path, err)
+			failed = true
+			result.Findings = append(result.Findings, models.UploadScanFinding{Scanner: scanner.Name(), Message: "scan failed: " + err.Error()})
+			continue
+		}
+		for _, finding := range findings {
+			result.Findings = append(result.Findings, models.UploadScanFinding{Scanner: scanner.Name(), Message: finding})
+			result.Status = models.UploadScanStatusRejected
+		}
+	}
+	if failed && result.Status != models.UploadScanStatusRejected {
+		result.Status = models.UploadScanStatusError
+	}
+	return result
+}
+
+// RescanUpload prüft einen Upload in Quarantäne erneut (z. B. nach Scannerausfall) und gibt ihn bei
+// sauberem Ergebnis frei. Die Revisionen zeigen danach auf den neuen Pfad.
+func RescanUpload(db *gorm.DB, upload *models.Upload, actor WorkflowActor) error {
+	result := ScanUploadFile(context.Background(), ConfiguredUploadScanners(), upload.FilePath)
+
+	oldPath := upload.FilePath
+	newPath := oldPath
+	if result.Status == models.UploadScanStatusClean {
+		released, err := ReleaseQuarantinedFile(oldPath)
+		if err != nil {
+			return err
+		}
+		newPath = released
+	}
+
+	now := time.Now()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Struct-Update, damit der JSON-Serializer für scan_findings greift.
+		if err := tx.Model(&models.Upload{ID: upload.ID}).
+			Select("file_path", "scan_status", "scan_findings", "scanned_at").
+			Updates(models.Upload{
+				FilePath:     newPath,
+				ScanStatus:   result.Status,
+				ScanFindings: result.Findings,
+				ScannedAt:    &now,
+			}).Error; err != nil {
+			return err
+		}
+		if newPath != oldPath {
+			if err := tx.Model(&models.UploadRevision{}).
+				Where("upload_id = ? AND file_path = ?", upload.ID, oldPath).
+				Update("file_path", newPath).Error; err != nil {
+				return err
+			}
+		}
+		return CreateAuditEvent(tx, actor.UserID, "UPLOAD_SCANNED", "upload", upload.ID, "", map[string]any{
+			"scan_status": upload.ScanStatus,
+		}, map[string]any{
+			"scan_status": result.Status,
+			"findings":    result.Findings,
+		}, nil)
+	})
+	if err != nil {
+		if newPath != oldPath {
+			_ = os.Rename(newPath, oldPath)
+		}
+		return err
+	}
+	upload.FilePath = newPath
+	upload.ScanStatus = result.Status
+	upload.ScanFindings = result.Findings
+	upload.ScannedAt = &now
+	return nil
+}
+
+// SpreadsheetActiveContentScanner lehnt Tabellen mit Makros, ActiveX oder externen Verknüpfungen ab.
+type SpreadsheetActiveContentScanner struct{}
+
+func (SpreadsheetActiveContentScanner) Name() string { return "xlsx_macros" }
+
+func (SpreadsheetActiveContentScanner) Scan(_ context.Context, path string) ([]string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xlsx":
+		return scanXLSXArchive(path)
+	case ".xls":
+		return scanXLSBinary(path)
+	default:
+		return nil, nil
+	}
+}
+
+func scanXLSXArchive(path string) ([]string, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return []string{"file is not a valid xlsx archive"}, nil
+	}
+	defer archive.Close()
+
+	var findings []string
+	seen := map[string]bool{}
+	add := func(message string) {
+		if !seen[message] {
+			seen[message] = true
+			findings = append(findings, message)
+		}
+	}
+	for _, entry := range archive.File {
+		name := strings.ToLower(entry.Name)
+		switch {
+		case strings.HasSuffix(name, "vbaproject.bin"):
+			add("workbook contains VBA macros")
+		case strings.HasPrefix(name, "xl/externallinks/"):
+			add("workbook contains external links")
+		case strings.HasPrefix(name, "xl/activex/"):
+			add("workbook contains ActiveX controls")
+		case strings.HasPrefix(name, "xl/embeddings/"):
+			add("workbook contains embedded objects")
+		}
+	}
+	return findings, nil
+}
+
+// Im alten Binärformat erkennt man Makros am VBA-Projektstream (UTF-16-Name im OLE-Verzeichnis).
+var xlsVBAMarker = []byte{'_', 0, 'V', 0, 'B', 0, 'A', 0, '_', 0, 'P', 0, 'R', 0, 'O', 0, 'J', 0, 'E', 0, 'C', 0, 'T', 0}
+
+func scanXLSBinary(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Contains(data, xlsVBAMarker) {
+		return []string{"workbook contains VBA macros"}, nil
+	}
+	return nil, nil
+}
+
+// CSVFormulaScanner erkennt Zellen, die in Tabellenprogrammen als Formel ausgeführt würden (CSV-Injection).
+type CSVFormulaScanner struct{}
+
+func (CSVFormulaScanner) Name() string { return "csv_formula" }
+
+func (CSVFormulaScanner) Scan(_ context.Context, path string) ([]string, error) {
+	if strings.ToLower(filepath.Ext(path)) != ".csv" {
+		return nil, nil
+	}
+	data, err := lib.ReadUploadAsTable(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []string
+	for rowIdx, row := range data {
+		for colIdx, cell := range row {
+			if !isFormulaLikeCell(cell) {
+				continue
+			}
+			if len(findings) == 5 {
+				return append(findings, "further formula-like cells omitted"), nil
+			}
+			findings = append(findings, fmt.Sprintf("row %d, column %d starts a formula: %q", rowIdx+1, colIdx+1, truncateFinding(cell)))
+		}
+	}
+	return findings, nil
+}
+
+func isFormulaLikeCell(cell string) bool {
+	value := strings.TrimLeft(cell, " \t\r\n")
+	if value == "" {
+		return false
+	}
+	switch value[0] {
+	case '=', '@':
+		return true
+	case '+', '-':
+		// Vorzeichenbehaftete Zahlen und Telefonnummern sind harmlos, Funktionsaufrufe/DDE nicht.
+		if _, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", "."), 64); err == nil {
+			return false
+		}
+		return strings.ContainsAny(value, "(!|=")
+	default:
+		return false
+	}
+}
+
+func truncateFinding(value string) string {
+	if len(value) > 40 {
+		return value[:40] + "…"
+	}
+	return value
+}
+
+// ClamAVScanner schickt die Datei per INSTREAM an einen clamd (Address "unix:/pfad" oder "tcp:host:port").
+type ClamAVScanner struct {
+	Address string
+	Timeout time.Duration
+}
+
+func (ClamAVScanner) Name() string { return "clamav" }
+
+func (s ClamAVScanner) Scan(ctx context.Context, path string) ([]string, error) {
+	network, address := "tcp", s.Address
+	if rest, ok := strings.CutPrefix(s.Address, "unix:"); ok {
+		network, address = "unix", rest
+	} else if rest, ok := strings.CutPrefix(s.Address, "tcp:"); ok {
+		address = rest
+	}
+
+	dialer := net.Dialer{Timeout: s.Timeout}
+	conn, err := dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if s.Timeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(s.Timeout))
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 64*1024)
+	size := make([]byte, 4)
+	for {
+		n, readErr := file.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(size); err != nil {
+				return nil, err
+			}
+			if _, err := conn.Write(buf[:n]); err != nil {
+				return nil, err
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return nil, err
+	}
+
+	reply, err := io.ReadAll(conn)
+	if err != nil {
+		return nil, err
+	}
+	verdict := strings.TrimSpace(strings.TrimRight(string(reply), "\x00"))
+	switch {
+	case strings.HasSuffix(verdict, "OK"):
+		return nil, nil
+	case strings.HasSuffix(verdict, "FOUND"):
+		signature := strings.TrimSuffix(strings.TrimPrefix(verdict, "stream: "), " FOUND")
+		return []string{"malware detected: " + signature}, nil
+	default:
+		return nil, fmt.Errorf("unexpected clamd reply: %s", verdict)
+	}
+}
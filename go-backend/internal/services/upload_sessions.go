@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"nba-dashboard/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrUploadSessionNotFound   = errors.New("upload session not found")
+	ErrUploadSessionClosed     = errors.New("upload session is no longer open")
+	ErrUploadSessionIncomplete = errors.New("upload session is incomplete")
+	ErrUploadChunkTooLarge     = errors.New("chunk exceeds declared upload size")
+)
+
+// UploadSessionOffsetError meldet einen Chunk, der nicht am aktuellen Offset ansetzt.
+type UploadSessionOffsetError struct {
+	Expected int64
+	Got      int64
+}
+
+func (e *UploadSessionOffsetError) Error() string {
+	return fmt.Sprintf("upload offset mismatch: expected %d, got %d", e.Expected, e.Got)
+}
+
+const uploadSessionDir = "uploads/sessions"
+
+// UploadResumableMaxBytes ist die Obergrenze für Dateien über Upload-Sessions (Standard 200 MB).
+func UploadResumableMaxBytes() int64 {
+	return int64(envInt("UPLOAD_RESUMABLE_MAX_BYTES", 200*1024*1024))
+}
+
+// UploadChunkMaxBytes begrenzt einen einzelnen Chunk; muss unter dem Fiber-BodyLimit liegen (Standard 8 MB).
+func UploadChunkMaxBytes() int64 {
+	return int64(envInt("UPLOAD_CHUNK_MAX_BYTES", 8*1024*1024))
+}
+
+// Nach dieser Zeit gilt ein Abschluss ("finalizing") als abgebrochen, z. B. nach einem Absturz.
+const uploadSessionFinalizeTimeout = time.Hour
+
+func uploadSessionTTL() time.Duration {
+	hours := envInt("UPLOAD_SESSION_TTL_HOURS", 24)
+	if hours < 1 {
+		hours = 1
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// CreateUploadSession legt eine neue Session samt leerer Teildatei an.
+func CreateUploadSession(db *gorm.DB, ownerUserID *uint, ownerEmail string, filename string, contentType string, size int64) (models.UploadSession, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return models.UploadSession{}, err
+	}
+	id := hex.EncodeToString(raw)
+
+	if err := os.MkdirAll(uploadSessionDir, 0o755); err != nil {
+		return models.UploadSession{}, err
+	}
+	session := models.UploadSession{
+		ID:          id,
+		OwnerUserID: ownerUserID,
+		OwnerEmail:  ownerEmail,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+		TempPath:    filepath.Join(uploadSessionDir, id+".part"),
+		Status:      models.UploadSessionStatusOpen,
+		ExpiresAt:   time.Now().Add(uploadSessionTTL()),
+	}
+	file, err := os.OpenFile(session.TempPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return models.UploadSession{}, err
+	}
+	_ = file.Close()
+
+	if err := db.Create(&session).Error; err != nil {
+		_ = os.Remove(session.TempPath)
+		return models.UploadSession{}, err
+	}
+	return session, nil
+}
+
+// GetUploadSession lädt eine Session des Besitzers; fremde Sessions gelten als nicht vorhanden.
+func GetUploadSession(db *gorm.DB, id string, ownerEmail string) (models.UploadSession, error) {
+	var session models.UploadSession
+	if err := db.Where("id = ? AND owner_email = ?", id, ownerEmail).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return session, ErrUploadSessionNotFound
+		}
+		return session, err
+	}
+	return session, nil
+}
+
+// AppendUploadSessionChunk schreibt einen Chunk an den angegebenen Offset. Der Offset muss dem
+// bisherigen Stand entsprechen, damit abgebrochene Übertragungen sauber fortgesetzt werden.
+func AppendUploadSessionChunk(db *gorm.DB, id string, ownerEmail string, offset int64, chunk []byte) (models.UploadSession, error) {
+	var session models.UploadSession
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND owner_email = ?", id, ownerEmail).
+			First(&session).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUploadSessionNotFound
+			}
+			return err
+		}
+		if session.Status != models.UploadSessionStatusOpen || time.Now().After(session.ExpiresAt) {
+			return ErrUploadSessionClosed
+		}
+		if offset != session.Offset {
+			return &UploadSessionOffsetError{Expected: session.Offset, Got: offset}
+		}
+		if offset+int64(len(chunk)) > session.Size {
+			return ErrUploadChunkTooLarge
+		}
+
+		file, err := os.OpenFile(session.TempPath, os.O_WRONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		if _, err := file.WriteAt(chunk, offset); err != nil {
+			_ = file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+
+		session.Offset = offset + int64(len(chunk))
+		return tx.Model(&session).Update("offset", session.Offset).Error
+	})
+	return session, err
+}
+
+// BeginUploadSessionFinalize sperrt eine vollständige Session für den Abschluss, damit sie
+// nicht doppelt zu einem Upload wird.
+func BeginUploadSessionFinalize(db *gorm.DB, id string, ownerEmail string) (models.UploadSession, error) {
+	session, err := GetUploadSession(db, id, ownerEmail)
+	if err != nil {
+		return session, err
+	}
+	if session.Status != models.UploadSessionStatusOpen {
+		return session, ErrUploadSessionClosed
+	}
+	if session.Offset != session.Size {
+		return session, ErrUploadSessionIncomplete
+	}
+	result := db.Model(&models.UploadSession{}).
+		Where("id = ? AND status = ?", session.ID, models.UploadSessionStatusOpen).
+		Update("status", models.UploadSessionStatusFinalizing)
+	if result.Error != nil {
+		return session, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return session, ErrUploadSessionClosed
+	}
+	session.Status = models.UploadSessionStatusFinalizing
+	if err := os.Truncate(session.TempPath, session.Size); err != nil {
+		return session, err
+	}
+	return session, nil
+}
+
+// ReopenUploadSession gibt eine Session nach fehlgeschlagener Prüfung wieder frei, damit der Client sie
+// fortsetzen bzw. den Abschluss wiederholen kann, statt von vorn zu beginnen.
+func ReopenUploadSession(db *gorm.DB, session models.UploadSession) error {
+	return db.Model(&models.UploadSession{}).
+		Where("id = ? AND status = ?", session.ID, models.UploadSessionStatusFinalizing).
+		Update("status", models.UploadSessionStatusOpen).Error
+}
+
+// FinishUploadSession schließt eine Session ab; uploadID ist nil, wenn der Abschluss fehlschlug.
+func FinishUploadSession(db *gorm.DB, session models.UploadSession, uploadID *uint) error {
+	status := models.UploadSessionStatusFinalized
+	if uploadID == nil {
+		status = models.UploadSessionStatusAborted
+		_ = os.Remove(session.TempPath)
+	}
+	now := time.Now()
+	return db.Model(&models.UploadSession{}).Where("id = ?", session.ID).Updates(map[string]any{
+		"status":      status,
+		"upload_id":   uploadID,
+		"finished_at": now,
+	}).Error
+}
+
+// AbortUploadSession bricht eine offene Session ab und entfernt die Teildatei. Die Session wird dafür
+// gesperrt, damit ein gleichzeitig laufender Chunk nicht in die gelöschte Datei schreibt.
+func AbortUploadSession(db *gorm.DB, id string, ownerEmail string) error {
+	var session models.UploadSession
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND owner_email = ?", id, ownerEmail).
+			First(&session).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUploadSessionNotFound
+			}
+			return err
+		}
+		if session.Status != models.UploadSessionStatusOpen {
+			return ErrUploadSessionClosed
+		}
+		return tx.Model(&session).Updates(map[string]any{
+			"status":      models.UploadSessionStatusAborted,
+			"finished_at": time.Now(),
+		}).Error
+	}); err != nil {
+		return err
+	}
+	_ = os.Remove(session.TempPath)
+	return nil
+}
+
+// CleanupExpiredUploadSessions bricht abgelaufene offene Sessions sowie hängengebliebene Abschlüsse ab und
+// räumt ihre Teildateien weg. Abgebrochen wird nur, wenn sich der Status seit dem Laden nicht geändert hat.
+func CleanupExpiredUploadSessions(ctx context.Context, db *gorm.DB) int {
+	now := time.Now()
+	var expired []models.UploadSession
+	if err := db.WithContext(ctx).Where("(status = ? AND expires_at <= ?) OR (status = ? AND updated_at <= ?)",
+		models.UploadSessionStatusOpen, now,
+		models.UploadSessionStatusFinalizing, now.Add(-uploadSessionFinalizeTimeout)).
+		Limit(100).
+		Find(&expired).Error; err != nil {
+		log.Printf("warning: failed to load expired upload sessions: %v", err)
+		return 0
+	}
+	cleaned := 0
+	for _, session := range expired {
+		// Beim Herunterfahren keine weitere Session anfangen; der Rest folgt im nächsten Lauf.
+		if ctx.Err() != nil {
+			break
+		}
+		result := db.WithContext(context.WithoutCancel(ctx)).Model(&models.UploadSession{}).
+			Where("id = ? AND status = ?", session.ID, session.Status).
+			Updates(map[string]any{
+				"status":      models.UploadSessionStatusAborted,
+				"finished_at": now,
+			})
+		if result.Error != nil {
+			log.Printf("warning: failed to expire upload session %s: %v", session.ID, result.Error)
+			continue
+		}
+		if result.RowsAffected > 0 {
+			_ = os.Remove(session.TempPath)
+			cleaned++
+		}
+	}
+	return cleaned
+}
+
+// StartUploadSessionCleanup startet den Hintergrundjob, der abgelaufene Upload-Sessions aufräumt.
+// Er endet mit ctx (App-Lebenszyklus).
+func StartUploadSessionCleanup(ctx context.Context, db *gorm.DB) {
+	if !envEnabled("UPLOAD_SESSION_CLEANUP_ENABLED", true) {
+		log.Println("ℹ️ Upload session cleanup disabled via UPLOAD_SESSION_CLEANUP_ENABLED")
+		return
+	}
+
+	pollInterval := envDurationSeconds("UPLOAD_SESSION_CLEANUP_POLL_SECONDS", 900)
+	if pollInterval < time.Minute {
+		pollInterval = time.Minute
+	}
+
+	goBackgroundJob(func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			if cleaned := CleanupExpiredUploadSessions(ctx, db); cleaned > 0 {
+				log.Printf("✅ upload session cleanup aborted sessions=%d", cleaned)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+	log.Printf("✅ Upload session cleanup started (poll=%s)", pollInterval)
+}
@@ -0,0 +1,430 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"nba-dashboard/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidUploadSLAPolicy meldet eine SLA-Regel mit unbekanntem Status, Advertiser oder ungültiger Dauer.
+var ErrInvalidUploadSLAPolicy = errors.New("invalid upload sla policy")
+
+const (
+	maxUploadSLAHours         = 24 * 365
+	uploadSLARefreshBatchSize = 200
+)
+
+// Partei, auf die ein Upload im jeweiligen offenen Status wartet. Die SLA-Uhr läuft nur, solange der Upload
+// bei derselben Partei liegt wie im aktuellen Status.
+const (
+	uploadSLAPartyNetwork    = "network"
+	uploadSLAPartyAdvertiser = "advertiser"
+	uploadSLAPartyPublisher  = "publisher"
+)
+
+var uploadSLAWaitingOn = map[string]string{
+	models.UploadStatusPending:                     uploadSLAPartyNetwork,
+	models.UploadStatusAssigned:                    uploadSLAPartyAdvertiser,
+	models.UploadStatusFeedback:                    uploadSLAPartyNetwork,
+	models.UploadStatusFeedbackSubmitted:           uploadSLAPartyNetwork,
+	models.UploadStatusFeedbackSubmittedAdvertiser: uploadSLAPartyNetwork,
+	models.UploadStatusReturnedToPublisher:         uploadSLAPartyPublisher,
+	models.UploadStatusSentToPublisherAdvertiser:   uploadSLAPartyPublisher,
+	models.UploadStatusAccessExpired:               uploadSLAPartyNetwork,
+}
+
+// IsUploadSLAStatus prüft, ob für einen Status eine Frist konfiguriert werden kann (nur offene Status).
+func IsUploadSLAStatus(status string) bool {
+	return slices.Contains(openUploadStatuses, status)
+}
+
+// ListUploadSLAPolicies liefert alle SLA-Regeln, Standardregeln zuerst.
+func ListUploadSLAPolicies(db *gorm.DB) ([]models.UploadSLAPolicy, error) {
+	policies := []models.UploadSLAPolicy{}
+	err := db.Order("status asc, advertiser_id asc").Find(&policies).Error
+	return policies, err
+}
+
+// UpsertUploadSLAPolicy legt eine Regel für Status+Advertiser an oder ändert deren Dauer.
+func UpsertUploadSLAPolicy(tx *gorm.DB, status string, advertiserID uint, durationHours int) (models.UploadSLAPolicy, error) {
+	policy := models.UploadSLAPolicy{Status: status, AdvertiserID: advertiserID, DurationHours: durationHours}
+	if !IsUploadSLAStatus(status) || durationHours <= 0 || durationHours > maxUploadSLAHours {
+		return policy, ErrInvalidUploadSLAPolicy
+	}
+	if advertiserID > 0 {
+		if err := ValidateUploadAccessAssignments(tx, []UploadAccessAssignment{{AdvertiserID: advertiserID}}); err != nil {
+			if errors.Is(err, ErrInvalidAdvertiserSelection) {
+				return policy, ErrInvalidUploadSLAPolicy
+			}
+			return policy, err
+		}
+	}
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "status"}, {Name: "advertiser_id"}},
+		DoUpdates: clause.Assignments(map[string]any{"duration_hours": durationHours, "updated_at": time.Now()}),
+	}).Create(&policy).Error
+	if err != nil {
+		return policy, err
+	}
+	err = tx.Where("status = ? AND advertiser_id = ?", status, advertiserID).First(&policy).Error
+	return policy, err
+}
+
+// DeleteUploadSLAPolicy entfernt eine Regel und liefert sie zurück (für die Neuberechnung der Fristen).
+func DeleteUploadSLAPolicy(tx *gorm.DB, policyID uint) (models.UploadSLAPolicy, error) {
+	var policy models.UploadSLAPolicy
+	if err := tx.First(&policy, policyID).Error; err != nil {
+		return policy, err
+	}
+	return policy, tx.Delete(&policy).Error
+}
+
+// RefreshUploadSLADueDates berechnet die Fristen aller offenen Uploads in einem Status neu (nach Regeländerungen).
+// Die Uploads werden blockweise geladen, damit große Bestände nicht auf einmal im Speicher liegen.
+func RefreshUploadSLADueDates(db *gorm.DB, status string) error {
+	now := time.Now()
+	var lastID uint
+	for {
+		var uploads []models.Upload
+		if err := db.Where("id > ? AND status = ?", lastID, status).
+			Order("id asc").
+			Limit(uploadSLARefreshBatchSize).
+			Find(&uploads).Error; err != nil {
+			return err
+		}
+		for i := range uploads {
+			if _, err := refreshUploadSLA(db, &uploads[i], now); err != nil {
+				return err
+			}
+		}
+		if len(uploads) < uploadSLARefreshBatchSize {
+			return nil
+		}
+		lastID = uploads[len(uploads)-1].ID
+	}
+}
+
+// RefreshUploadSLADueAt berechnet und speichert die Frist für den aktuellen Status eines Uploads.
+func RefreshUploadSLADueAt(tx *gorm.DB, upload *models.Upload) error {
+	_, err := refreshUploadSLA(tx, upload, time.Now())
+	return err
+}
+
+// refreshUploadSLA liefert zusätzlich die ID des Statuswechsels, mit dem der aktuelle Aufenthalt begann.
+func refreshUploadSLA(tx *gorm.DB, upload *models.Upload, now time.Time) (uint, error) {
+	dueAt, visitID, err := computeUploadSLADueAt(tx, *upload, now)
+	if err != nil {
+		return 0, err
+	}
+	if sameDueAt(upload.SLADueAt, dueAt) {
+		return visitID, nil
+	}
+	if err := tx.Model(&models.Upload{}).Where("id = ?", upload.ID).UpdateColumn("sla_due_at", dueAt).Error; err != nil {
+		return 0, err
+	}
+	upload.SLADueAt = dueAt
+	return visitID, nil
+}
+
+// computeUploadSLADueAt bestimmt die Frist aus der Statushistorie. Gezählt wird die gesamte Zeit, in der der
+// Upload auf dieselbe Partei wartete wie im aktuellen Status (auch in anderen Status dieser Partei); liegt er
+// zwischenzeitlich bei der Gegenseite, ruht die Uhr und läuft bei Rückkehr mit der Restzeit weiter.
+func computeUploadSLADueAt(tx *gorm.DB, upload models.Upload, now time.Time) (*time.Time, uint, error) {
+	if !IsUploadSLAStatus(upload.Status) {
+		return nil, 0, nil
+	}
+	limit, ok, err := resolveUploadSLADuration(tx, upload, now)
+	if err != nil || !ok {
+		return nil, 0, err
+	}
+
+	var transitions []models.UploadStatusTransition
+	if err := tx.Select("id", "from_status", "to_status", "occurred_at").
+		Where("upload_id = ?", upload.ID).
+		Order("occurred_at asc, id asc").
+		Find(&transitions).Error; err != nil {
+		return nil, 0, err
+	}
+
+	enteredAt, consumed := uploadSLAWaitingTime(upload, transitions)
+	dueAt := enteredAt.Add(limit - consumed)
+	var visitID uint
+	if len(transitions) > 0 {
+		visitID = transitions[len(transitions)-1].ID
+	}
+	return &dueAt, visitID, nil
+}
+
+// uploadSLAWaitingTime liefert den Beginn des aktuellen Statusaufenthalts und die davor bereits verbrauchte
+// Zeit: alle abgeschlossenen Abschnitte, in denen der Upload auf die Partei des aktuellen Status wartete.
+// Vor dem ersten Statuswechsel gilt der Ausgangsstatus des Protokolls (sonst "pending").
+func uploadSLAWaitingTime(upload models.Upload, transitions []models.UploadStatusTransition) (time.Time, time.Duration) {
+	party := uploadSLAWaitingOn[upload.Status]
+	status := models.UploadStatusPending
+	if len(transitions) > 0 && transitions[0].FromStatus != "" {
+		status = transitions[0].FromStatus
+	}
+	since := upload.CreatedAt
+	var consumed time.Duration
+	for _, t := range transitions {
+		if uploadSLAWaitingOn[status] == party {
+			consumed += t.OccurredAt.Sub(since)
+		}
+		status, since = t.ToStatus, t.OccurredAt
+	}
+	return since, consumed
+}
+
+// resolveUploadSLADuration wählt die strengste Regel der beteiligten Advertiser, sonst die Standardregel.
+func resolveUploadSLADuration(tx *gorm.DB, upload models.Upload, now time.Time) (time.Duration, bool, error) {
+	advertiserIDs, err := UploadAdvertiserIDs(tx, upload, now)
+	if err != nil {
+		return 0, false, err
+	}
+
+	var policies []models.UploadSLAPolicy
+	if err := tx.Where("status = ? AND advertiser_id IN ?", upload.Status, append(advertiserIDs, 0)).
+		Find(&policies).Error; err != nil {
+		return 0, false, err
+	}
+
+	hours := 0
+	for _, p := range policies {
+		if p.AdvertiserID > 0 && (hours == 0 || p.DurationHours < hours) {
+			hours = p.DurationHours
+		}
+	}
+	if hours == 0 {
+		for _, p := range policies {
+			if p.AdvertiserID == 0 {
+				hours = p.DurationHours
+			}
+		}
+	}
+	if hours <= 0 {
+		return 0, false, nil
+	}
+	return time.Duration(hours) * time.Hour, true, nil
+}
+
+func sameDueAt(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+// OverdueUpload ist ein Upload mit überschrittener Frist für die Admin-Übersicht.
+type OverdueUpload struct {
+	UploadID     uint       `json:"uploadId"`
+	Filename     string     `json:"filename"`
+	Status       string     `json:"status"`
+	UploadedBy   string     `json:"uploadedBy"`
+	DueAt        time.Time  `json:"dueAt"`
+	OverdueHours int        `json:"overdueHours"`
+	EscalatedAt  *time.Time `json:"escalatedAt"`
+}
+
+// ListOverdueUploads liefert offene Uploads, deren Frist bis "now" abgelaufen ist (optional je Advertiser).
+func ListOverdueUploads(db *gorm.DB, now time.Time, advertiserID uint) ([]OverdueUpload, error) {
+	out := []OverdueUpload{}
+	query := db.Table("uploads AS u").
+		Select("u.id AS upload_id, u.filename AS filename, u.status AS status, u.uploaded_by AS uploaded_by, u.sla_due_at AS due_at, (SELECT MAX(e.detected_at) FROM upload_sla_escalations e WHERE e.upload_id = u.id AND e.status = u.status AND e.status_transition_id = COALESCE((SELECT MAX(t.id) FROM upload_status_transitions t WHERE t.upload_id = u.id), 0)) AS escalated_at").
+		Where("u.deleted_at IS NULL AND u.sla_due_at IS NOT NULL AND u.sla_due_at <= ? AND u.status IN ?", now, openUploadStatuses)
+	if advertiserID > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM upload_accesses ua WHERE ua.upload_id = u.id AND ua.advertiser_id = ?)", advertiserID)
+	}
+	if err := query.Order("u.sla_due_at ASC, u.id ASC").Scan(&out).Error; err != nil {
+		return nil, err
+	}
+	for i := range out {
+		out[i].OverdueHours = int(now.Sub(out[i].DueAt).Hours())
+	}
+	return out, nil
+}
+
+// UploadSLAMonitor rechnet Fristen offener Uploads regelmäßig nach und eskaliert Überschreitungen.
+type UploadSLAMonitor struct {
+	db           *gorm.DB
+	pollInterval time.Duration
+	initialDelay time.Duration
+	batchSize    int
+}
+
+type UploadSLAMonitorMetrics struct {
+	Enabled             bool      `json:"enabled"`
+	StartedAt           time.Time `json:"started_at"`
+	PollIntervalSeconds int       `json:"poll_interval_seconds"`
+	LastTickAt          time.Time `json:"last_tick_at"`
+	LastTickChecked     int       `json:"last_tick_checked"`
+	LastTickEscalated   int       `json:"last_tick_escalated"`
+	TotalEscalated      int64     `json:"total_escalated"`
+	LastError           string    `json:"last_error"`
+}
+
+var (
+	slaMetricsMu sync.Mutex
+	slaMetrics   = UploadSLAMonitorMetrics{}
+)
+
+// StartUploadSLAMonitor startet die SLA-Überwachung; sie endet mit ctx (App-Lebenszyklus).
+func StartUploadSLAMonitor(ctx context.Context, db *gorm.DB) {
+	if !envEnabled("UPLOAD_SLA_ENABLED", true) {
+		log.Println("ℹ️ Upload SLA monitor disabled via UPLOAD_SLA_ENABLED")
+		return
+	}
+
+	m := &UploadSLAMonitor{
+		db:           db,
+		pollInterval: envDurationSeconds("UPLOAD_SLA_POLL_SECONDS", 300),
+		initialDelay: envDurationSeconds("UPLOAD_SLA_INITIAL_DELAY_SECONDS", 30),
+		batchSize:    envInt("UPLOAD_SLA_BATCH_SIZE", 200),
+	}
+	if m.pollInterval < 15*time.Second {
+		m.pollInterval = 15 * time.Second
+	}
+	if m.batchSize <= 0 {
+		m.batchSize = 200
+	}
+
+	goBackgroundJob(func() { m.run(ctx) })
+	log.Printf("✅ Upload SLA monitor started (poll=%s)", m.pollInterval)
+
+	slaMetricsMu.Lock()
+	slaMetrics.Enabled = true
+	slaMetrics.StartedAt = time.Now()
+	slaMetrics.PollIntervalSeconds = int(m.pollInterval.Seconds())
+	slaMetricsMu.Unlock()
+}
+
+func (m *UploadSLAMonitor) run(ctx context.Context) {
+	if m.initialDelay > 0 {
+		select {
+		case <-time.After(m.initialDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	m.tick(ctx)
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.tick(ctx)
+		}
+	}
+}
+
+func (m *UploadSLAMonitor) tick(ctx context.Context) {
+	now := time.Now()
+	checked := 0
+	escalated := 0
+	lastErr := ""
+
+	var lastID uint
+	for {
+		var uploads []models.Upload
+		if err := m.db.WithContext(ctx).
+			Where("id > ? AND status IN ?", lastID, openUploadStatuses).
+			Order("id asc").
+			Limit(m.batchSize).
+			Find(&uploads).Error; err != nil {
+			log.Printf("❌ SLA monitor failed to load uploads: %v", err)
+			lastErr = err.Error()
+			break
+		}
+		for i := range uploads {
+			// Beim Herunterfahren keinen weiteren Upload anfangen; der Rest folgt im nächsten Lauf.
+			if ctx.Err() != nil {
+				break
+			}
+			didEscalate, err := m.checkUpload(ctx, &uploads[i], now)
+			if err != nil {
+				log.Printf("❌ SLA monitor failed for upload=%d: %v", uploads[i].ID, err)
+				lastErr = err.Error()
+				continue
+			}
+			checked++
+			if didEscalate {
+				escalated++
+			}
+		}
+		if len(uploads) < m.batchSize || ctx.Err() != nil {
+			break
+		}
+		lastID = uploads[len(uploads)-1].ID
+	}
+
+	if escalated > 0 {
+		log.Printf("⚠️ SLA monitor escalated uploads=%d", escalated)
+	}
+	recordSLATick(now, checked, escalated, lastErr)
+}
+
+func (m *UploadSLAMonitor) checkUpload(ctx context.Context, upload *models.Upload, now time.Time) (bool, error) {
+	escalated := false
+	// Eine begonnene Transaktion läuft auch beim Herunterfahren zu Ende.
+	err := m.db.WithContext(context.WithoutCancel(ctx)).Transaction(func(tx *gorm.DB) error {
+		visitID, err := refreshUploadSLA(tx, upload, now)
+		if err != nil {
+			return err
+		}
+		if upload.SLADueAt == nil || upload.SLADueAt.After(now) {
+			return nil
+		}
+
+		escalation := models.UploadSLAEscalation{
+			UploadID:           upload.ID,
+			Status:             upload.Status,
+			StatusTransitionID: visitID,
+			DueAt:              *upload.SLADueAt,
+			DetectedAt:         now,
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&escalation)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		escalated = true
+		return CreateAuditEvent(tx, nil, "UPLOAD_SLA_BREACHED", "upload", upload.ID, "", nil, map[string]any{
+			"status": upload.Status,
+			"due_at": escalation.DueAt,
+		}, map[string]any{
+			"source": "upload_sla_monitor",
+		})
+	})
+	return escalated, err
+}
+
+func GetUploadSLAMonitorMetrics() UploadSLAMonitorMetrics {
+	slaMetricsMu.Lock()
+	defer slaMetricsMu.Unlock()
+	return slaMetrics
+}
+
+func recordSLATick(at time.Time, checked int, escalated int, lastErr string) {
+	slaMetricsMu.Lock()
+	defer slaMetricsMu.Unlock()
+	slaMetrics.LastTickAt = at
+	slaMetrics.LastTickChecked = checked
+	slaMetrics.LastTickEscalated = escalated
+	slaMetrics.TotalEscalated += int64(escalated)
+	if lastErr != "" {
+		slaMetrics.LastError = lastErr
+	}
+}
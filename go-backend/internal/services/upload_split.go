@@ -0,0 +1,119 @@
+package services
+
+import (
+	"errors"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"nba-dashboard/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrUploadAlreadySplit: Ein Upload wird nur einmal aufgeteilt; vorhandene Teil-Uploads zuerst löschen.
+var ErrUploadAlreadySplit = errors.New("upload already has split child uploads")
+
+var splitValueSanitizer = regexp.MustCompile(`[^\pL\pN._-]+`)
+
+// SplitChildFilename bildet den Anzeigenamen eines Teil-Uploads: <Original>_<Wert>.<Endung>.
+func SplitChildFilename(parent models.Upload, value string) string {
+	ext := filepath.Ext(parent.Filename)
+	name := strings.TrimSuffix(filepath.Base(parent.Filename), ext)
+	suffix := strings.Trim(splitValueSanitizer.ReplaceAllString(value, "-"), "-.")
+	if suffix == "" {
+		suffix = "ohne-wert"
+	}
+	if ext == "" {
+		ext = filepath.Ext(parent.FilePath)
+	}
+	return name + "_" + suffix + ext
+}
+
+// EnsureUploadNotSplit prüft, dass es noch keine (nicht gelöschten) Teil-Uploads gibt. Innerhalb einer
+// Transaktion wird die Ausgangsdatei dabei gesperrt, damit parallele Splits nicht beide durchkommen.
+func EnsureUploadNotSplit(db *gorm.DB, parentID uint) error {
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Upload{}, parentID).Error; err != nil {
+		return err
+	}
+	var count int64
+	if err := db.Model(&models.Upload{}).Where("parent_upload_id = ?", parentID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrUploadAlreadySplit
+	}
+	return nil
+}
+
+// ExcludeSplitRelatives blendet bei Duplikatprüfungen Ausgangsdatei und Teil-Uploads eines Splits aus; sie
+// enthalten zwangsläufig dieselben Ordertokens. alias ist der Tabellenalias der Uploads in query.
+func ExcludeSplitRelatives(query *gorm.DB, alias string, upload models.Upload) *gorm.DB {
+	query = query.Where("("+alias+".parent_upload_id IS NULL OR "+alias+".parent_upload_id <> ?)", upload.ID)
+	if upload.ParentUploadID != nil {
+		query = query.Where(alias+".id <> ?", *upload.ParentUploadID)
+	}
+	return query
+}
+
+// MatchSplitAdvertisers ordnet Split-Werten Advertiser zu: über ID, E-Mail, Name oder Firma
+// (ohne Groß-/Kleinschreibung). Mehrdeutige Werte bleiben ohne Zuordnung.
+func MatchSplitAdvertisers(db *gorm.DB, values []string) (map[string]uint, error) {
+	var advertisers []models.User
+	if err := db.Where("role = ?", "advertiser").Find(&advertisers).Error; err != nil {
+		return nil, err
+	}
+	byKey := map[string]uint{}
+	ambiguous := map[string]bool{}
+	add := func(key string, id uint) {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "" {
+			return
+		}
+		if existing, ok := byKey[key]; ok && existing != id {
+			ambiguous[key] = true
+			return
+		}
+		byKey[key] = id
+	}
+	for _, a := range advertisers {
+		add(strconv.FormatUint(uint64(a.ID), 10), a.ID)
+		add(a.Email, a.ID)
+		add(a.Name, a.ID)
+		add(a.Company, a.ID)
+	}
+
+	matches := map[string]uint{}
+	for _, value := range values {
+		key := strings.ToLower(strings.TrimSpace(value))
+		if id, ok := byKey[key]; ok && !ambiguous[key] {
+			matches[value] = id
+		}
+	}
+	return matches, nil
+}
+
+// CreateSplitChildUpload legt einen Teil-Upload mit Anfangsstatus und Revision an und gibt ihn
+// optional direkt für einen Advertiser frei. Die Datei muss bereits unter child.FilePath liegen.
+func CreateSplitChildUpload(tx *gorm.DB, parent models.Upload, child *models.Upload, advertiserID uint, expiresAt *time.Time, actor WorkflowActor) error {
+	child.ParentUploadID = &parent.ID
+	child.Kind = parent.Kind
+	child.UploadedBy = parent.UploadedBy
+	child.Status = models.UploadStatusPending
+	if err := tx.Create(child).Error; err != nil {
+		return err
+	}
+	if err := RecordInitialUploadStatus(tx, *child, actor, "split"); err != nil {
+		return err
+	}
+	if _, err := RecordUploadRevision(tx, *child, actor, "split", nil); err != nil {
+		return err
+	}
+	if advertiserID == 0 {
+		return nil
+	}
+	return AssignUploadAccess(tx, child, []UploadAccessAssignment{{AdvertiserID: advertiserID, ExpiresAt: expiresAt}}, actor)
+}
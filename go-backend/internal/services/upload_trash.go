@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"nba-dashboard/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrUploadNotInTrash wird geliefert, wenn ein Upload wiederhergestellt/endgültig gelöscht werden
+// soll, der nicht im Papierkorb liegt.
+var ErrUploadNotInTrash = errors.New("upload is not in trash")
+
+// TrashUpload verschiebt einen Upload in den Papierkorb (Soft Delete). Dateien, Freigaben,
+// Revisionen und Kandidaten bleiben erhalten, bis der Purge-Job sie nach Ablauf der Aufbewahrung entfernt.
+// Die Dateien bleiben bewusst an ihrem Pfad: Revisionen (auch wiederhergestellte) teilen sich Pfade,
+// ausgeliefert wird nur über Upload-/Revisions-Zeilen, die der Soft Delete für alle Abfragen ausblendet,
+// und RestoreUpload muss so nichts zurückverschieben.
+func TrashUpload(tx *gorm.DB, upload models.Upload, actor WorkflowActor) error {
+	if err := tx.Model(&upload).Update("deleted_by", actor.Email).Error; err != nil {
+		return err
+	}
+	if err := tx.Delete(&upload).Error; err != nil {
+		return err
+	}
+	return CreateAuditEvent(tx, actor.UserID, "UPLOAD_TRASHED", "upload", upload.ID, "", nil, nil, map[string]any{
+		"filename": upload.Filename,
+		"status":   upload.Status,
+	})
+}
+
+// RestoreUpload holt einen Upload aus dem Papierkorb zurück.
+func RestoreUpload(tx *gorm.DB, uploadID uint, actor WorkflowActor) (models.Upload, error) {
+	upload, err := findTrashedUpload(tx, uploadID)
+	if err != nil {
+		return upload, err
+	}
+	if err := tx.Unscoped().Model(&upload).Updates(map[string]any{
+		"deleted_at": nil,
+		"deleted_by": "",
+	}).Error; err != nil {
+		return upload, err
+	}
+	upload.DeletedAt = gorm.DeletedAt{}
+	upload.DeletedBy = ""
+	return upload, CreateAuditEvent(tx, actor.UserID, "UPLOAD_RESTORED", "upload", upload.ID, "", nil, nil, map[string]any{
+		"filename": upload.Filename,
+	})
+}
+
+// ListTrashedUploads liefert alle Uploads im Papierkorb, zuletzt gelöschte zuerst.
+func ListTrashedUploads(db *gorm.DB) ([]models.Upload, error) {
+	uploads := []models.Upload{}
+	err := db.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at desc, id desc").Find(&uploads).Error
+	return uploads, err
+}
+
+// PurgeUpload löscht einen Upload aus dem Papierkorb endgültig inkl. abhängiger Datensätze
+// und liefert die Dateien, die nach dem Commit entfernt werden sollen.
+// Statushistorie und Audit-Events bleiben als Nachweis erhalten; Verweise anderer Uploads und
+// Upload-Sessions auf den Upload werden geleert, damit keine verwaisten IDs zurückbleiben.
+func PurgeUpload(tx *gorm.DB, uploadID uint, actor WorkflowActor) ([]string, error) {
+	upload, err := findTrashedUpload(tx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	filePaths, err := UploadRevisionFilePaths(tx, upload.ID)
+	if err != nil {
+		return nil, err
+	}
+	if upload.FilePath != "" && !slices.Contains(filePaths, upload.FilePath) {
+		filePaths = append(filePaths, upload.FilePath)
+	}
+
+	commentIDs := tx.Unscoped().Model(&models.UploadComment{}).Select("id").Where("upload_id = ?", upload.ID)
+	if err := tx.Where("comment_id IN (?)", commentIDs).Delete(&models.UploadCommentMention{}).Error; err != nil {
+		return nil, err
+	}
+	for _, model := range []any{
+		&models.UploadComment{},
+		&models.UploadAccess{},
+		&models.UploadRevision{},
+		&models.ValidationResult{},
+		&models.ValidationJob{},
+		&models.UploadOrderCandidate{},
+		&models.UploadSLAEscalation{},
+	} {
+		if err := tx.Unscoped().Where("upload_id = ?", upload.ID).Delete(model).Error; err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Where("merged_upload_id = ? OR source_upload_id = ?", upload.ID, upload.ID).Delete(&models.UploadMergedRow{}).Error; err != nil {
+		return nil, err
+	}
+	for _, column := range []string{"parent_upload_id", "superseded_by_upload_id", "duplicate_of_upload_id"} {
+		if err := tx.Unscoped().Model(&models.Upload{}).Where(column+" = ?", upload.ID).UpdateColumn(column, nil).Error; err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Model(&models.UploadSession{}).Where("upload_id = ?", upload.ID).UpdateColumn("upload_id", nil).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Unscoped().Delete(&upload).Error; err != nil {
+		return nil, err
+	}
+	if err := CreateAuditEvent(tx, actor.UserID, "UPLOAD_PURGED", "upload", upload.ID, "", nil, nil, map[string]any{
+		"filename":   upload.Filename,
+		"deleted_at": upload.DeletedAt.Time,
+	}); err != nil {
+		return nil, err
+	}
+	return filePaths, nil
+}
+
+// RemoveUploadFiles entfernt Dateien nach einem Purge; Fehler werden nur geloggt.
+func RemoveUploadFiles(paths []string) {
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Printf("warning: failed to delete upload file after purge: %v", err)
+		}
+	}
+}
+
+// UploadTrashRetention ist die Aufbewahrungsdauer im Papierkorb (UPLOAD_TRASH_RETENTION_DAYS, Standard 30).
+func UploadTrashRetention() time.Duration {
+	days := envInt("UPLOAD_TRASH_RETENTION_DAYS", 30)
+	if days < 1 {
+		days = 1
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+func findTrashedUpload(tx *gorm.DB, uploadID uint) (models.Upload, error) {
+	var upload models.Upload
+	if err := tx.Unscoped().First(&upload, uploadID).Error; err != nil {
+		return upload, err
+	}
+	if !upload.DeletedAt.Valid {
+		return upload, ErrUploadNotInTrash
+	}
+	return upload, nil
+}
+
+type UploadTrashPurgeMetrics struct {
+	Enabled             bool      `json:"enabled"`
+	RetentionDays       int       `json:"retention_days"`
+	LastRunAt           time.Time `json:"last_run_at"`
+	LastRunPurged       int       `json:"last_run_purged"`
+	TotalPurged         int64     `json:"total_purged"`
+	LastError           string    `json:"last_error"`
+	PollIntervalSeconds int       `json:"poll_interval_seconds"`
+}
+
+var (
+	trashMetricsMu sync.Mutex
+	trashMetrics   = UploadTrashPurgeMetrics{}
+)
+
+// StartUploadTrashPurger startet den Hintergrundjob, der Uploads nach Ablauf der Aufbewahrung endgültig löscht.
+// Er endet mit ctx (App-Lebenszyklus).
+func StartUploadTrashPurger(ctx context.Context, db *gorm.DB) {
+	if !envEnabled("UPLOAD_TRASH_PURGE_ENABLED", true) {
+		log.Println("ℹ️ Upload trash purge disabled via UPLOAD_TRASH_PURGE_ENABLED")
+		return
+	}
+
+	pollInterval := envDurationSeconds("UPLOAD_TRASH_PURGE_POLL_SECONDS", 3600)
+	if pollInterval < time.Minute {
+		pollInterval = time.Minute
+	}
+	retention := UploadTrashRetention()
+
+	trashMetricsMu.Lock()
+	trashMetrics.Enabled = true
+	trashMetrics.RetentionDays = int(retention.Hours() / 24)
+	trashMetrics.PollIntervalSeconds = int(pollInterval.Seconds())
+	trashMetricsMu.Unlock()
+
+	goBackgroundJob(func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			purgeExpiredTrash(ctx, db, retention)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+	log.Printf("✅ Upload trash purge started (poll=%s, retention=%s)", pollInterval, retention)
+}
+
+func purgeExpiredTrash(ctx context.Context, db *gorm.DB, retention time.Duration) {
+	var ids []uint
+	if err := db.WithContext(ctx).Unscoped().Model(&models.Upload{}).
+		Where("deleted_at IS NOT NULL AND deleted_at <= ?", time.Now().Add(-retention)).
+		Order("deleted_at asc").
+		Limit(100).
+		Pluck("id", &ids).Error; err != nil {
+		log.Printf("❌ trash purge failed to load uploads: %v", err)
+		recordTrashPurge(0, err.Error())
+		return
+	}
+
+	purged := 0
+	lastErr := ""
+	for _, id := range ids {
+		// Beim Herunterfahren keinen weiteren Upload anfangen; eine begonnene Löschung läuft samt Dateien zu Ende.
+		if ctx.Err() != nil {
+			break
+		}
+		var paths []string
+		if err := db.WithContext(context.WithoutCancel(ctx)).Transaction(func(tx *gorm.DB) error {
+			var err error
+			paths, err = PurgeUpload(tx, id, WorkflowActor{Role: WorkflowRoleSystem})
+			return err
+		}); err != nil {
+			log.Printf("❌ trash purge failed for upload=%d: %v", id, err)
+			lastErr = err.Error()
+			continue
+		}
+		RemoveUploadFiles(paths)
+		purged++
+	}
+	if purged > 0 {
+		log.Printf("✅ trash purge removed uploads=%d", purged)
+	}
+	recordTrashPurge(purged, lastErr)
+}
+
+func recordTrashPurge(purged int, lastErr string) {
+	trashMetricsMu.Lock()
+	defer trashMetricsMu.Unlock()
+	trashMetrics.LastRunAt = time.Now()
+	trashMetrics.LastRunPurged = purged
+	trashMetrics.TotalPurged += int64(purged)
+	if lastErr != "" {
+		trashMetrics.LastError = lastErr
+	}
+}
+
+func GetUploadTrashPurgeMetrics() UploadTrashPurgeMetrics {
+	trashMetricsMu.Lock()
+	defer trashMetricsMu.Unlock()
+	return trashMetrics
+}
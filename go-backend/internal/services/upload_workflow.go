@@ -0,0 +1,288 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"nba-dashboard/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrIllegalUploadTransition wird von TransitionUploadStatus geliefert, wenn der Workflow
+// den gewünschten Statuswechsel nicht erlaubt (falscher Ausgangsstatus, Rolle oder Guard).
+var ErrIllegalUploadTransition = errors.New("illegal upload status transition")
+
+// ErrUploadContentLocked meldet Uploads, deren Dateistand im aktuellen Status nicht mehr geändert werden darf.
+var ErrUploadContentLocked = errors.New("upload content is locked in its current status")
+
+// WorkflowRoleSystem kennzeichnet Statuswechsel durch Hintergrundjobs.
+const WorkflowRoleSystem = "system"
+
+// WorkflowActor beschreibt, wer einen Statuswechsel auslöst.
+type WorkflowActor struct {
+	UserID *uint
+	Email  string
+	Role   string
+}
+
+type UploadTransitionError struct {
+	From   string
+	To     string
+	Role   string
+	Reason string
+}
+
+func (e *UploadTransitionError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("status transition %q -> %q not allowed for role %q: %s", e.From, e.To, e.Role, e.Reason)
+	}
+	return fmt.Sprintf("status transition %q -> %q not allowed for role %q", e.From, e.To, e.Role)
+}
+
+func (e *UploadTransitionError) Unwrap() error { return ErrIllegalUploadTransition }
+
+// UploadContentLockedError nennt den Status, in dem der Dateistand gesperrt ist.
+type UploadContentLockedError struct {
+	Status string
+}
+
+func (e *UploadContentLockedError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrUploadContentLocked, e.Status)
+}
+
+func (e *UploadContentLockedError) Unwrap() error { return ErrUploadContentLocked }
+
+type uploadTransitionRule struct {
+	To    string
+	Roles []string
+	From  []string
+	Guard func(upload models.Upload) error
+}
+
+var openUploadStatuses = []string{
+	models.UploadStatusPending,
+	models.UploadStatusAssigned,
+	models.UploadStatusFeedback,
+	models.UploadStatusFeedbackSubmitted,
+	models.UploadStatusFeedbackSubmittedAdvertiser,
+	models.UploadStatusReturnedToPublisher,
+	models.UploadStatusSentToPublisherAdvertiser,
+	models.UploadStatusAccessExpired,
+}
+
+// uploadWorkflow ist die einzige Quelle für erlaubte Statuswechsel.
+// Eine Regel greift, wenn Zielstatus, Rolle und Ausgangsstatus passen und der Guard nil liefert.
+var uploadWorkflow = []uploadTransitionRule{
+	{
+		To:    models.UploadStatusAssigned,
+		Roles: []string{"admin"},
+		From:  append(slices.Clone(openUploadStatuses), models.UploadStatusRejected),
+	},
+	{
+		// Letzte Advertiser-Freigabe wurde entzogen oder ist abgelaufen.
+		To:    models.UploadStatusPending,
+		Roles: []string{"admin", WorkflowRoleSystem},
+		From:  []string{models.UploadStatusAssigned},
+	},
+	{
+		To:    models.UploadStatusAccessExpired,
+		Roles: []string{WorkflowRoleSystem},
+		From:  []string{models.UploadStatusAssigned},
+	},
+	{
+		// Advertiser-Bearbeitung geht zuerst in die Netzwerk-Verarbeitung.
+		To:    models.UploadStatusFeedback,
+		Roles: []string{"advertiser"},
+		From:  openUploadStatuses,
+	},
+	{
+		// Publisher schickt eine Advertiser-Manuellanfrage zur Admin-Prüfung zurück.
+		To:    models.UploadStatusFeedback,
+		Roles: []string{"publisher"},
+		From: []string{
+			models.UploadStatusFeedback,
+			models.UploadStatusFeedbackSubmittedAdvertiser,
+			models.UploadStatusSentToPublisherAdvertiser,
+		},
+		Guard: requireAdvertiserManualRequest,
+	},
+	{
+		// Rückfrage des Publishers an den Admin.
+		To:    models.UploadStatusFeedbackSubmitted,
+		Roles: []string{"publisher"},
+		From: []string{
+			models.UploadStatusPending,
+			models.UploadStatusAssigned,
+			models.UploadStatusFeedback,
+			models.UploadStatusFeedbackSubmitted,
+			models.UploadStatusReturnedToPublisher,
+		},
+		Guard: rejectAdvertiserManualRequest,
+	},
+	{
+		To:    models.UploadStatusReturnedToPublisher,
+		Roles: []string{"admin"},
+		From: []string{
+			models.UploadStatusAssigned,
+			models.UploadStatusFeedback,
+			models.UploadStatusFeedbackSubmitted,
+			models.UploadStatusFeedbackSubmittedAdvertiser,
+			models.UploadStatusSentToPublisherAdvertiser,
+		},
+	},
+	{
+		To:    models.UploadStatusSentToPublisherAdvertiser,
+		Roles: []string{"admin"},
+		From: []string{
+			models.UploadStatusAssigned,
+			models.UploadStatusFeedback,
+			models.UploadStatusFeedbackSubmitted,
+			models.UploadStatusFeedbackSubmittedAdvertiser,
+		},
+		Guard: requireAdvertiserManualRequest,
+	},
+	{
+		To:    models.UploadStatusApproved,
+		Roles: []string{"admin"},
+		From:  append(slices.Clone(openUploadStatuses), models.UploadStatusRejected),
+	},
+	{
+		To:    models.UploadStatusRejected,
+		Roles: []string{"admin"},
+		From:  append(slices.Clone(openUploadStatuses), models.UploadStatusApproved),
+	},
+	{
+		// Quelle wurde in eine Sammeldatei übernommen (POST /api/uploads/merge).
+		To:    models.UploadStatusSuperseded,
+		Roles: []string{"admin"},
+		From:  append(slices.Clone(openUploadStatuses), models.UploadStatusApproved),
+	},
+	{
+		To:    models.UploadStatusCompleted,
+		Roles: []string{"publisher"},
+		From:  append(slices.Clone(openUploadStatuses), models.UploadStatusApproved, models.UploadStatusRejected),
+	},
+	{
+		To:    models.UploadStatusCompleted,
+		Roles: []string{"advertiser"},
+		From:  append(slices.Clone(openUploadStatuses), models.UploadStatusApproved, models.UploadStatusRejected),
+		Guard: requireAdvertiserManualRequest,
+	},
+}
+
+func requireAdvertiserManualRequest(upload models.Upload) error {
+	if !IsAdvertiserManualRequest(upload) {
+		return errors.New("only allowed for advertiser manual requests")
+	}
+	return nil
+}
+
+func rejectAdvertiserManualRequest(upload models.Upload) error {
+	if IsAdvertiserManualRequest(upload) {
+		return errors.New("not allowed for advertiser manual requests")
+	}
+	return nil
+}
+
+// IsAdvertiserManualRequest erkennt vom Advertiser angelegte Manuellanfragen (über Upload.Kind,
+// nicht über den Dateinamen, damit Umbenennen/Ersetzen die Workflow-Regeln nicht verändert).
+func IsAdvertiserManualRequest(upload models.Upload) bool {
+	return upload.Kind == models.UploadKindAdvertiserManualRequest
+}
+
+// EnsureUploadContentEditable erlaubt einen neuen Dateistand nur in offenen Status; freigegebene,
+// abgelehnte, abgeschlossene und übernommene Uploads bleiben unverändert.
+func EnsureUploadContentEditable(upload models.Upload) error {
+	if slices.Contains(openUploadStatuses, strings.TrimSpace(upload.Status)) {
+		return nil
+	}
+	return &UploadContentLockedError{Status: upload.Status}
+}
+
+// LockEditableUpload sperrt den Upload für die laufende Transaktion und prüft den Status erneut: er kann
+// seit der Vorprüfung freigegeben oder abgeschlossen worden sein.
+func LockEditableUpload(tx *gorm.DB, uploadID uint) error {
+	var current models.Upload
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "status").First(&current, uploadID).Error; err != nil {
+		return err
+	}
+	return EnsureUploadContentEditable(current)
+}
+
+// CheckUploadTransition prüft einen Statuswechsel gegen den Workflow, ohne etwas zu schreiben.
+func CheckUploadTransition(upload models.Upload, to string, role string) error {
+	from := strings.TrimSpace(upload.Status)
+	role = strings.ToLower(strings.TrimSpace(role))
+
+	reason := ""
+	for _, rule := range uploadWorkflow {
+		if rule.To != to || !slices.Contains(rule.Roles, role) {
+			continue
+		}
+		if !slices.Contains(rule.From, from) {
+			continue
+		}
+		if rule.Guard != nil {
+			if err := rule.Guard(upload); err != nil {
+				reason = err.Error()
+				continue
+			}
+		}
+		return nil
+	}
+	return &UploadTransitionError{From: from, To: to, Role: role, Reason: reason}
+}
+
+// TransitionUploadStatus führt einen Statuswechsel durch und protokolliert ihn.
+// Der Wechsel ist bedingt auf den gelesenen Ausgangsstatus, parallele Änderungen schlagen daher fehl.
+// Ist der Upload bereits im Zielstatus, passiert nichts.
+func TransitionUploadStatus(tx *gorm.DB, upload *models.Upload, to string, actor WorkflowActor, reason string) error {
+	from := strings.TrimSpace(upload.Status)
+	if from == to {
+		return nil
+	}
+	if err := CheckUploadTransition(*upload, to, actor.Role); err != nil {
+		return err
+	}
+
+	result := tx.Model(&models.Upload{}).
+		Where("id = ? AND status = ?", upload.ID, upload.Status).
+		Update("status", to)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return &UploadTransitionError{From: from, To: to, Role: actor.Role, Reason: "status was changed concurrently"}
+	}
+
+	if err := recordUploadTransition(tx, upload.ID, from, to, actor, reason); err != nil {
+		return err
+	}
+	upload.Status = to
+	return RefreshUploadSLADueAt(tx, upload)
+}
+
+// RecordInitialUploadStatus legt den ersten Historieneintrag für einen neu erstellten Upload an.
+func RecordInitialUploadStatus(tx *gorm.DB, upload models.Upload, actor WorkflowActor, reason string) error {
+	if err := recordUploadTransition(tx, upload.ID, "", upload.Status, actor, reason); err != nil {
+		return err
+	}
+	return RefreshUploadSLADueAt(tx, &upload)
+}
+
+func recordUploadTransition(tx *gorm.DB, uploadID uint, from string, to string, actor WorkflowActor, reason string) error {
+	transition := models.UploadStatusTransition{
+		UploadID:    uploadID,
+		FromStatus:  from,
+		ToStatus:    to,
+		ActorUserID: actor.UserID,
+		ActorEmail:  strings.TrimSpace(actor.Email),
+		ActorRole:   strings.ToLower(strings.TrimSpace(actor.Role)),
+		Reason:      strings.TrimSpace(reason),
+	}
+	return tx.Create(&transition).Error
+}
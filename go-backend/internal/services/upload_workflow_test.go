@@ -0,0 +1,141 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"nba-dashboard/internal/models"
+)
+
+func TestCheckUploadTransition(t *testing.T) {
+	const (
+		publisherFile = models.UploadKindPublisherFile
+		manualRequest = models.UploadKindAdvertiserManualRequest
+	)
+	tests := []struct {
+		from    string
+		to      string
+		role    string
+		kind    string
+		allowed bool
+	}{
+		// Zuweisung und Entzug
+		{models.UploadStatusPending, models.UploadStatusAssigned, "admin", publisherFile, true},
+		{models.UploadStatusRejected, models.UploadStatusAssigned, "admin", publisherFile, true},
+		{models.UploadStatusPending, models.UploadStatusAssigned, "publisher", publisherFile, false},
+		{models.UploadStatusPending, models.UploadStatusAssigned, "advertiser", publisherFile, false},
+		{models.UploadStatusCompleted, models.UploadStatusAssigned, "admin", publisherFile, false},
+		{models.UploadStatusAssigned, models.UploadStatusPending, "admin", publisherFile, true},
+		{models.UploadStatusAssigned, models.UploadStatusPending, WorkflowRoleSystem, publisherFile, true},
+		{models.UploadStatusAssigned, models.UploadStatusPending, "publisher", publisherFile, false},
+		{models.UploadStatusFeedback, models.UploadStatusPending, "admin", publisherFile, false},
+		{models.UploadStatusAssigned, models.UploadStatusAccessExpired, WorkflowRoleSystem, publisherFile, true},
+		{models.UploadStatusAssigned, models.UploadStatusAccessExpired, "admin", publisherFile, false},
+		{models.UploadStatusPending, models.UploadStatusAccessExpired, WorkflowRoleSystem, publisherFile, false},
+
+		// Rückmeldungen
+		{models.UploadStatusAssigned, models.UploadStatusFeedback, "advertiser", publisherFile, true},
+		{models.UploadStatusApproved, models.UploadStatusFeedback, "advertiser", publisherFile, false},
+		{models.UploadStatusSentToPublisherAdvertiser, models.UploadStatusFeedback, "publisher", manualRequest, true},
+		{models.UploadStatusSentToPublisherAdvertiser, models.UploadStatusFeedback, "publisher", publisherFile, false},
+		{models.UploadStatusAssigned, models.UploadStatusFeedback, "publisher", manualRequest, false},
+		{models.UploadStatusAssigned, models.UploadStatusFeedbackSubmitted, "publisher", publisherFile, true},
+		{models.UploadStatusReturnedToPublisher, models.UploadStatusFeedbackSubmitted, "publisher", publisherFile, true},
+		{models.UploadStatusAssigned, models.UploadStatusFeedbackSubmitted, "publisher", manualRequest, false},
+		{models.UploadStatusFeedbackSubmittedAdvertiser, models.UploadStatusFeedbackSubmitted, "publisher", publisherFile, false},
+		{models.UploadStatusAssigned, models.UploadStatusFeedbackSubmitted, "admin", publisherFile, false},
+
+		// Rückgabe an Publisher
+		{models.UploadStatusFeedback, models.UploadStatusReturnedToPublisher, "admin", publisherFile, true},
+		{models.UploadStatusPending, models.UploadStatusReturnedToPublisher, "admin", publisherFile, false},
+		{models.UploadStatusFeedback, models.UploadStatusReturnedToPublisher, "advertiser", publisherFile, false},
+		{models.UploadStatusFeedback, models.UploadStatusSentToPublisherAdvertiser, "admin", manualRequest, true},
+		{models.UploadStatusFeedback, models.UploadStatusSentToPublisherAdvertiser, "admin", publisherFile, false},
+		{models.UploadStatusReturnedToPublisher, models.UploadStatusSentToPublisherAdvertiser, "admin", manualRequest, false},
+
+		// Abschluss
+		{models.UploadStatusFeedback, models.UploadStatusApproved, "admin", publisherFile, true},
+		{models.UploadStatusRejected, models.UploadStatusApproved, "admin", publisherFile, true},
+		{models.UploadStatusCompleted, models.UploadStatusApproved, "admin", publisherFile, false},
+		{models.UploadStatusFeedback, models.UploadStatusApproved, "advertiser", publisherFile, false},
+		{models.UploadStatusApproved, models.UploadStatusRejected, "admin", publisherFile, true},
+		{models.UploadStatusCompleted, models.UploadStatusRejected, "admin", publisherFile, false},
+		{models.UploadStatusApproved, models.UploadStatusSuperseded, "admin", publisherFile, true},
+		{models.UploadStatusRejected, models.UploadStatusSuperseded, "admin", publisherFile, false},
+		{models.UploadStatusRejected, models.UploadStatusCompleted, "publisher", publisherFile, true},
+		{models.UploadStatusSuperseded, models.UploadStatusCompleted, "publisher", publisherFile, false},
+		{models.UploadStatusApproved, models.UploadStatusCompleted, "advertiser", manualRequest, true},
+		{models.UploadStatusApproved, models.UploadStatusCompleted, "advertiser", publisherFile, false},
+		{models.UploadStatusApproved, models.UploadStatusCompleted, "admin", publisherFile, false},
+
+		// Rolle wird normalisiert, unbekannte Rollen dürfen nichts
+		{models.UploadStatusPending, models.UploadStatusAssigned, " Admin ", publisherFile, true},
+		{models.UploadStatusPending, models.UploadStatusAssigned, "", publisherFile, false},
+	}
+	for _, tt := range tests {
+		name := tt.from + "->" + tt.to + "/" + tt.role + "/" + tt.kind
+		t.Run(name, func(t *testing.T) {
+			upload := models.Upload{Status: tt.from, Kind: tt.kind}
+			err := CheckUploadTransition(upload, tt.to, tt.role)
+			if tt.allowed {
+				if err != nil {
+					t.Errorf("expected transition to be allowed, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrIllegalUploadTransition) {
+				t.Errorf("err = %v, want ErrIllegalUploadTransition", err)
+			}
+		})
+	}
+}
+
+func TestCheckUploadTransitionGuardReason(t *testing.T) {
+	upload := models.Upload{Status: models.UploadStatusFeedback, Kind: models.UploadKindPublisherFile}
+	err := CheckUploadTransition(upload, models.UploadStatusSentToPublisherAdvertiser, "admin")
+	var transitionErr *UploadTransitionError
+	if !errors.As(err, &transitionErr) || transitionErr.Reason == "" {
+		t.Fatalf("err = %v, want UploadTransitionError with guard reason", err)
+	}
+}
+
+func TestOpenUploadStatusesCanBeApprovedAndRejected(t *testing.T) {
+	for _, status := range openUploadStatuses {
+		upload := models.Upload{Status: status, Kind: models.UploadKindPublisherFile}
+		for _, to := range []string{models.UploadStatusApproved, models.UploadStatusRejected, models.UploadStatusCompleted} {
+			role := "admin"
+			if to == models.UploadStatusCompleted {
+				role = "publisher"
+			}
+			if err := CheckUploadTransition(upload, to, role); err != nil {
+				t.Errorf("%s -> %s (%s): %v", status, to, role, err)
+			}
+		}
+	}
+}
+
+func TestEnsureUploadContentEditable(t *testing.T) {
+	tests := []struct {
+		status string
+		locked bool
+	}{
+		{models.UploadStatusPending, false},
+		{models.UploadStatusAssigned, false},
+		{models.UploadStatusFeedbackSubmitted, false},
+		{models.UploadStatusAccessExpired, false},
+		{models.UploadStatusApproved, true},
+		{models.UploadStatusRejected, true},
+		{models.UploadStatusCompleted, true},
+		{models.UploadStatusSuperseded, true},
+	}
+	for _, tt := range tests {
+		err := EnsureUploadContentEditable(models.Upload{Status: tt.status})
+		if locked := errors.Is(err, ErrUploadContentLocked); locked != tt.locked {
+			t.Errorf("EnsureUploadContentEditable(%s) = %v, want locked=%v", tt.status, err, tt.locked)
+		}
+		var lockedErr *UploadContentLockedError
+		if tt.locked && (!errors.As(err, &lockedErr) || lockedErr.Status != tt.status) {
+			t.Errorf("EnsureUploadContentEditable(%s) = %v, want UploadContentLockedError with status", tt.status, err)
+		}
+	}
+}
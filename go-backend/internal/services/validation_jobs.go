@@ -0,0 +1,498 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"nba-dashboard/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrValidationJobFinished meldet den Abbruch eines bereits beendeten Jobs.
+var ErrValidationJobFinished = errors.New("validation job already finished")
+
+// ValidationRunError ist ein fachlicher Fehler der Validierung. Status ist der HTTP-Status der
+// synchronen Variante (GET /validate); im Job landen Message und Detail.
+type ValidationRunError struct {
+	Status  int
+	Message string
+	Detail  string
+}
+
+func (e *ValidationRunError) Error() string {
+	return e.Message
+}
+
+// ValidationJobRunner führt die eigentliche Validierung aus (registriert aus dem handlers-Paket) und liefert den gespeicherten Lauf.
+type ValidationJobRunner func(ctx context.Context, db *gorm.DB, job models.ValidationJob, progress *ValidationJobProgress) (models.ValidationResult, error)
+
+// ValidationJobProgress schreibt Phase und Zeilenfortschritt eines Jobs gedrosselt in die DB.
+// Ein nil-Progress (synchrone Validierung) ignoriert alle Aufrufe.
+type ValidationJobProgress struct {
+	db        *gorm.DB
+	jobID     uint
+	mu        sync.Mutex
+	lastFlush time.Time
+}
+
+const validationProgressFlushInterval = time.Second
+
+// SetPhase setzt die aktuelle Phase (syncing, matching, saving …).
+func (p *ValidationJobProgress) SetPhase(phase string) {
+	if p == nil {
+		return
+	}
+	p.update(map[string]any{"phase": phase, "heartbeat_at": time.Now()}, true)
+}
+
+// SetRows meldet den Zeilenfortschritt; geschrieben wird höchstens einmal pro Sekunde und am Ende.
+func (p *ValidationJobProgress) SetRows(done int, total int) {
+	if p == nil {
+		return
+	}
+	p.update(map[string]any{"rows_done": done, "rows_total": total, "heartbeat_at": time.Now()}, done >= total)
+}
+
+func (p *ValidationJobProgress) update(values map[string]any, force bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !force && time.Since(p.lastFlush) < validationProgressFlushInterval {
+		return
+	}
+	p.lastFlush = time.Now()
+	if err := p.db.Model(&models.ValidationJob{}).
+		Where("id = ? AND status = ?", p.jobID, models.ValidationJobRunning).
+		Updates(values).Error; err != nil {
+		log.Printf("⚠️ validation job=%d progress update failed: %v", p.jobID, err)
+	}
+}
+
+// ValidationJobWorker arbeitet eingereihte Validierungsjobs ab. Laufende Jobs schreiben regelmäßig
+// einen Heartbeat; Jobs ohne aktuellen Heartbeat (z. B. nach einem Neustart) werden erneut eingereiht.
+type ValidationJobWorker struct {
+	db                *gorm.DB
+	run               ValidationJobRunner
+	pollInterval      time.Duration
+	heartbeatInterval time.Duration
+	staleAfter        time.Duration
+	concurrency       int
+	maxAttempts       int
+
+	mu      sync.Mutex
+	running map[uint]context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+var (
+	validationWorkerMu sync.Mutex
+	validationWorker   *ValidationJobWorker
+)
+
+// StartValidationJobWorker startet den Hintergrund-Worker für POST /api/uploads/:id/validate. Endet ctx
+// (Herunterfahren), nimmt der Worker keine Jobs mehr an und reiht laufende Jobs wieder ein.
+func StartValidationJobWorker(ctx context.Context, db *gorm.DB, run ValidationJobRunner) {
+	if !envEnabled("VALIDATION_JOBS_ENABLED", true) {
+		log.Println("ℹ️ Validation job worker disabled via VALIDATION_JOBS_ENABLED")
+		return
+	}
+
+	w := &ValidationJobWorker{
+		db:                db,
+		run:               run,
+		pollInterval:      envDurationSeconds("VALIDATION_JOB_POLL_SECONDS", 2),
+		heartbeatInterval: envDurationSeconds("VALIDATION_JOB_HEARTBEAT_SECONDS", 15),
+		staleAfter:        envDurationSeconds("VALIDATION_JOB_STALE_SECONDS", 90),
+		concurrency:       envInt("VALIDATION_JOB_CONCURRENCY", 2),
+		maxAttempts:       envInt("VALIDATION_JOB_MAX_ATTEMPTS", 3),
+		running:           map[uint]context.CancelFunc{},
+	}
+	if w.concurrency <= 0 {
+		w.concurrency = 1
+	}
+	if w.maxAttempts <= 0 {
+		w.maxAttempts = 1
+	}
+	if w.staleAfter < 3*w.heartbeatInterval {
+		w.staleAfter = 3 * w.heartbeatInterval
+	}
+
+	validationWorkerMu.Lock()
+	validationWorker = w
+	validationWorkerMu.Unlock()
+
+	go w.loop(ctx)
+	log.Printf("✅ Validation job worker started (poll=%s, concurrency=%d, stale=%s)", w.pollInterval, w.concurrency, w.staleAfter)
+}
+
+// WaitValidationJobWorker wartet nach dem Ende des Worker-Kontexts, bis laufende Jobs wieder eingereiht
+// sind, höchstens bis ctx abläuft.
+func WaitValidationJobWorker(ctx context.Context) {
+	validationWorkerMu.Lock()
+	w := validationWorker
+	validationWorkerMu.Unlock()
+	if w == nil {
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Printf("⚠️ validation worker did not stop in time, running jobs are requeued via heartbeat")
+	}
+}
+
+// ValidationJobsEnabled meldet, ob in diesem Prozess ein Worker läuft.
+func ValidationJobsEnabled() bool {
+	validationWorkerMu.Lock()
+	defer validationWorkerMu.Unlock()
+	return validationWorker != nil
+}
+
+func (w *ValidationJobWorker) loop(ctx context.Context) {
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		w.tick(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *ValidationJobWorker) tick(ctx context.Context) {
+	if err := w.requeueStale(ctx, time.Now()); err != nil {
+		log.Printf("❌ validation worker failed to requeue stale jobs: %v", err)
+	}
+	for w.runningCount() < w.concurrency {
+		job, ok, err := w.claimNext(ctx)
+		if err != nil {
+			log.Printf("❌ validation worker failed to claim job: %v", err)
+			return
+		}
+		if !ok {
+			return
+		}
+		w.start(ctx, job)
+	}
+}
+
+// requeueStale reiht laufende Jobs ohne Heartbeat erneut ein bzw. bricht sie nach maxAttempts ab.
+func (w *ValidationJobWorker) requeueStale(ctx context.Context, now time.Time) error {
+	cutoff := now.Add(-w.staleAfter)
+	var stale []models.ValidationJob
+	if err := w.db.WithContext(ctx).
+		Where("status = ? AND (heartbeat_at IS NULL OR heartbeat_at < ?)", models.ValidationJobRunning, cutoff).
+		Find(&stale).Error; err != nil {
+		return err
+	}
+	for _, job := range stale {
+		values := map[string]any{"status": models.ValidationJobQueued, "phase": models.ValidationPhaseQueued}
+		switch {
+		case job.CancelRequested:
+			values = map[string]any{"status": models.ValidationJobCancelled, "finished_at": now}
+		case job.Attempts >= w.maxAttempts:
+			values = map[string]any{"status": models.ValidationJobFailed, "error": "validation worker stopped", "finished_at": now}
+		}
+		result := w.db.WithContext(ctx).Model(&models.ValidationJob{}).
+			Where("id = ? AND status = ? AND (heartbeat_at IS NULL OR heartbeat_at < ?)", job.ID, models.ValidationJobRunning, cutoff).
+			Updates(values)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("⚠️ validation job=%d had no heartbeat since %s, now %v", job.ID, cutoff.Format(time.RFC3339), values["status"])
+		}
+	}
+	return nil
+}
+
+// claimNext übernimmt den ältesten eingereihten Job; mehrere Instanzen konkurrieren über das bedingte Update.
+func (w *ValidationJobWorker) claimNext(ctx context.Context) (models.ValidationJob, bool, error) {
+	for range 3 {
+		// Find statt First, damit leere Polls nicht als "record not found" geloggt werden.
+		var queued []models.ValidationJob
+		if err := w.db.WithContext(ctx).Where("status = ?", models.ValidationJobQueued).Order("id ASC").Limit(1).Find(&queued).Error; err != nil {
+			return models.ValidationJob{}, false, err
+		}
+		if len(queued) == 0 {
+			return models.ValidationJob{}, false, nil
+		}
+		job := queued[0]
+		now := time.Now()
+		result := w.db.WithContext(ctx).Model(&models.ValidationJob{}).
+			Where("id = ? AND status = ?", job.ID, models.ValidationJobQueued).
+			Updates(map[string]any{
+				"status":       models.ValidationJobRunning,
+				"phase":        models.ValidationPhaseLoading,
+				"attempts":     gorm.Expr("attempts + 1"),
+				"heartbeat_at": now,
+				"started_at":   now,
+				"rows_done":    0,
+				"error":        "",
+				"error_detail": "",
+			})
+		if result.Error != nil {
+			return job, false, result.Error
+		}
+		if result.RowsAffected == 1 {
+			err := w.db.WithContext(ctx).First(&job, job.ID).Error
+			return job, err == nil, err
+		}
+	}
+	return models.ValidationJob{}, false, nil
+}
+
+func (w *ValidationJobWorker) start(parent context.Context, job models.ValidationJob) {
+	ctx, cancel := context.WithCancel(parent)
+	w.mu.Lock()
+	w.running[job.ID] = cancel
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer func() {
+			cancel()
+			w.mu.Lock()
+			delete(w.running, job.ID)
+			w.mu.Unlock()
+		}()
+
+		stopHeartbeat := make(chan struct{})
+		go w.heartbeat(ctx, job.ID, cancel, stopHeartbeat)
+
+		progress := &ValidationJobProgress{db: w.db, jobID: job.ID}
+		result, err := w.runSafely(ctx, job, progress)
+		close(stopHeartbeat)
+		if err != nil && parent.Err() != nil {
+			// Herunterfahren, kein Abbruch durch den User: Job für die nächste Instanz wieder einreihen.
+			w.requeue(job.ID)
+			return
+		}
+		w.finish(job.ID, result, err, err != nil && ctx.Err() != nil)
+	}()
+}
+
+func (w *ValidationJobWorker) runSafely(ctx context.Context, job models.ValidationJob, progress *ValidationJobProgress) (result models.ValidationResult, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("❌ validation job=%d panicked: %v", job.ID, recovered)
+			err = errors.New("validation panicked")
+		}
+	}()
+	return w.run(ctx, w.db, job, progress)
+}
+
+// heartbeat hält den Job als lebendig markiert und bricht ihn ab, sobald ein Abbruch angefordert wurde
+// (auch wenn die Anforderung über eine andere Instanz kam).
+func (w *ValidationJobWorker) heartbeat(ctx context.Context, jobID uint, cancel context.CancelFunc, stop <-chan struct{}) {
+	ticker := time.NewTicker(w.heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.db.Model(&models.ValidationJob{}).
+				Where("id = ? AND status = ?", jobID, models.ValidationJobRunning).
+				Update("heartbeat_at", time.Now()).Error; err != nil {
+				log.Printf("⚠️ validation job=%d heartbeat failed: %v", jobID, err)
+			}
+			var job models.ValidationJob
+			if err := w.db.Select("id", "status", "cancel_requested").First(&job, jobID).Error; err == nil &&
+				(job.CancelRequested || job.Status != models.ValidationJobRunning) {
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+func (w *ValidationJobWorker) requeue(jobID uint) {
+	if err := w.db.Model(&models.ValidationJob{}).
+		Where("id = ? AND status = ?", jobID, models.ValidationJobRunning).
+		Updates(map[string]any{"status": models.ValidationJobQueued, "phase": models.ValidationPhaseQueued}).Error; err != nil {
+		log.Printf("❌ validation job=%d could not be requeued: %v", jobID, err)
+		return
+	}
+	log.Printf("ℹ️ validation job=%d requeued on shutdown", jobID)
+}
+
+func (w *ValidationJobWorker) finish(jobID uint, result models.ValidationResult, runErr error, cancelled bool) {
+	now := time.Now()
+	values := map[string]any{
+		"status":       models.ValidationJobSucceeded,
+		"phase":        models.ValidationPhaseDone,
+		"orders_count": result.OrdersCount,
+		"finished_at":  now,
+		"heartbeat_at": now,
+	}
+	if result.ID > 0 {
+		values["result_id"] = result.ID
+	}
+	switch {
+	case cancelled:
+		values["status"] = models.ValidationJobCancelled
+		delete(values, "phase")
+	case runErr != nil:
+		delete(values, "orders_count")
+		values["status"] = models.ValidationJobFailed
+		values["error"] = runErr.Error()
+		var runError *ValidationRunError
+		if errors.As(runErr, &runError) {
+			values["error_detail"] = runError.Detail
+		}
+		delete(values, "phase")
+	}
+	if err := w.db.Model(&models.ValidationJob{}).
+		Where("id = ? AND status = ?", jobID, models.ValidationJobRunning).
+		Updates(values).Error; err != nil {
+		log.Printf("❌ validation job=%d could not be finished: %v", jobID, err)
+		return
+	}
+	log.Printf("✅ validation job=%d finished: %v", jobID, values["status"])
+}
+
+func (w *ValidationJobWorker) runningCount() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.running)
+}
+
+func (w *ValidationJobWorker) cancelLocal(jobID uint) {
+	w.mu.Lock()
+	cancel, ok := w.running[jobID]
+	w.mu.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+// EnqueueValidationJob reiht eine Validierung ein. Ist für den Upload bereits ein Job eingereiht oder aktiv,
+// wird dieser zurückgegeben (created=false). Je Upload ist höchstens ein aktiver Job erlaubt
+// (idx_validation_jobs_active_upload); verliert ein paralleler Aufruf das Rennen, gilt dasselbe.
+func EnqueueValidationJob(db *gorm.DB, uploadID uint, params models.ValidationParams, requestedBy string) (models.ValidationJob, bool, error) {
+	if job, ok, err := findActiveValidationJob(db, uploadID); err != nil || ok {
+		return job, false, err
+	}
+	job := models.ValidationJob{
+		UploadID:    uploadID,
+		Params:      params,
+		Status:      models.ValidationJobQueued,
+		Phase:       models.ValidationPhaseQueued,
+		RequestedBy: requestedBy,
+	}
+	if err := db.Create(&job).Error; err != nil {
+		if !isUniqueViolation(err) {
+			return job, false, err
+		}
+		active, ok, findErr := findActiveValidationJob(db, uploadID)
+		if findErr != nil || !ok {
+			return job, false, err
+		}
+		return active, false, nil
+	}
+	return job, true, nil
+}
+
+func findActiveValidationJob(db *gorm.DB, uploadID uint) (models.ValidationJob, bool, error) {
+	var active []models.ValidationJob
+	if err := db.Where("upload_id = ? AND status IN ?", uploadID, []string{models.ValidationJobQueued, models.ValidationJobRunning}).
+		Order("id DESC").Limit(1).Find(&active).Error; err != nil {
+		return models.ValidationJob{}, false, err
+	}
+	if len(active) == 0 {
+		return models.ValidationJob{}, false, nil
+	}
+	return active[0], true, nil
+}
+
+// isUniqueViolation erkennt Verletzungen eines Unique-Index (Postgres 23505).
+func isUniqueViolation(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "23505") || strings.Contains(msg, "duplicate key")
+}
+
+// SaveValidationRun speichert einen Lauf. Je Job entsteht höchstens ein Lauf: wird ein Job nach einem
+// Absturz erneut ausgeführt, überschreibt das neue Ergebnis den bereits gespeicherten Lauf dieses Jobs.
+func SaveValidationRun(db *gorm.DB, run *models.ValidationResult) error {
+	if run.JobID == nil {
+		return db.Create(run).Error
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		// Sperre auf den Job serialisiert parallele Ausführungen desselben Jobs.
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.ValidationJob{}, *run.JobID).Error; err != nil {
+			return err
+		}
+		var existing []models.ValidationResult
+		if err := tx.Unscoped().Select("id", "created_at").Where("job_id = ?", *run.JobID).Order("id ASC").Limit(1).Find(&existing).Error; err != nil {
+			return err
+		}
+		if len(existing) == 0 {
+			return tx.Create(run).Error
+		}
+		run.ID = existing[0].ID
+		run.CreatedAt = existing[0].CreatedAt
+		run.ValidatedAt = time.Now()
+		return tx.Unscoped().Save(run).Error
+	})
+}
+
+// CancelValidationJob bricht einen Job ab: eingereihte sofort, laufende beim nächsten Heartbeat
+// (bzw. sofort, wenn er in diesem Prozess läuft).
+func CancelValidationJob(db *gorm.DB, jobID uint) (models.ValidationJob, error) {
+	var job models.ValidationJob
+	if err := db.First(&job, jobID).Error; err != nil {
+		return job, err
+	}
+	switch job.Status {
+	case models.ValidationJobQueued:
+		now := time.Now()
+		result := db.Model(&models.ValidationJob{}).
+			Where("id = ? AND status = ?", jobID, models.ValidationJobQueued).
+			Updates(map[string]any{"status": models.ValidationJobCancelled, "cancel_requested": true, "finished_at": now})
+		if result.Error != nil {
+			return job, result.Error
+		}
+		if result.RowsAffected == 0 {
+			// Inzwischen vom Worker übernommen: wie ein laufender Job behandeln.
+			return CancelValidationJob(db, jobID)
+		}
+	case models.ValidationJobRunning:
+		if err := db.Model(&models.ValidationJob{}).Where("id = ?", jobID).Update("cancel_requested", true).Error; err != nil {
+			return job, err
+		}
+		validationWorkerMu.Lock()
+		worker := validationWorker
+		validationWorkerMu.Unlock()
+		if worker != nil {
+			worker.cancelLocal(jobID)
+		}
+	default:
+		return job, ErrValidationJobFinished
+	}
+	err := db.First(&job, jobID).Error
+	return job, err
+}
+
+// ListUploadValidationJobs liefert die letzten Jobs eines Uploads, neueste zuerst.
+func ListUploadValidationJobs(db *gorm.DB, uploadID uint, limit int) ([]models.ValidationJob, error) {
+	jobs := []models.ValidationJob{}
+	err := db.Where("upload_id = ?", uploadID).Order("id DESC").Limit(limit).Find(&jobs).Error
+	return jobs, err
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"nba-dashboard/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Spalten, die das Netzwerk bei der Validierung befüllt und die im Lauf-Vergleich gegenübergestellt werden.
+const (
+	ValidationStatusColumn     = "Status in der uppr Performance Platform"
+	ValidationCommissionColumn = "Commission aus Netzwerk"
+)
+
+// Art einer Zeilenänderung zwischen zwei Validierungsläufen.
+const (
+	ValidationRowChanged = "changed"
+	ValidationRowAdded   = "added"
+	ValidationRowRemoved = "removed"
+)
+
+// NewValidationRun baut einen neuen (noch nicht gespeicherten) Lauf inkl. Zeilen- und Fehlerzählung.
+func NewValidationRun(uploadID uint, params models.ValidationParams, ordersCount int, rows []models.ValidatedRow) models.ValidationResult {
+	run := models.ValidationResult{
+		UploadID:      uploadID,
+		Params:        params,
+		OrdersCount:   ordersCount,
+		RowsCount:     len(rows),
+		ValidatedRows: rows,
+	}
+	for _, row := range rows {
+		for _, cell := range row.Cells {
+			switch cell.Status {
+			case models.CellInvalid:
+				run.InvalidCount++
+			case models.CellEmpty:
+				run.EmptyCount++
+			}
+		}
+	}
+	return run
+}
+
+// ListValidationRuns liefert alle Läufe eines Uploads ohne Zeilen, neueste zuerst.
+func ListValidationRuns(db *gorm.DB, uploadID uint) ([]models.ValidationResult, error) {
+	runs := []models.ValidationResult{}
+	err := db.Omit("validated_rows").Where("upload_id = ?", uploadID).Order("id DESC").Find(&runs).Error
+	return runs, err
+}
+
+// GetValidationRun lädt einen Lauf inkl. Zeilen; der Lauf muss zum Upload gehören.
+func GetValidationRun(db *gorm.DB, uploadID uint, runID uint) (models.ValidationResult, error) {
+	var run models.ValidationResult
+	err := db.Where("upload_id = ?", uploadID).First(&run, runID).Error
+	return run, err
+}
+
+// LatestValidationRunPair lädt die beiden neuesten Läufe eines Uploads (vorheriger, neuester).
+// Gibt es weniger als zwei Läufe, kommt gorm.ErrRecordNotFound zurück.
+func LatestValidationRunPair(db *gorm.DB, uploadID uint) (models.ValidationResult, models.ValidationResult, error) {
+	var runs []models.ValidationResult
+	if err := db.Where("upload_id = ?", uploadID).Order("id DESC").Limit(2).Find(&runs).Error; err != nil {
+		return models.ValidationResult{}, models.ValidationResult{}, err
+	}
+	if len(runs) < 2 {
+		return models.ValidationResult{}, models.ValidationResult{}, gorm.ErrRecordNotFound
+	}
+	return runs[1], runs[0], nil
+}
+
+// ValidationRowValues sind die verglichenen Werte einer Zeile in einem Lauf.
+type ValidationRowValues struct {
+	Index      int    `json:"index"`
+	Status     string `json:"status"`
+	Commission string `json:"commission"`
+}
+
+// ValidationRowChange ist eine Zeile, deren Status oder Commission sich zwischen zwei Läufen unterscheidet.
+type ValidationRowChange struct {
+	Key               string               `json:"key"`
+	OrderToken        string               `json:"orderToken"`
+	Change            string               `json:"change"`
+	StatusChanged     bool                 `json:"statusChanged"`
+	CommissionChanged bool                 `json:"commissionChanged"`
+	From              *ValidationRowValues `json:"from,omitempty"`
+	To                *ValidationRowValues `json:"to,omitempty"`
+}
+
+// ValidationRunDiff fasst den Vergleich zweier Läufe zusammen.
+type ValidationRunDiff struct {
+	FromRunID uint                  `json:"fromRunId"`
+	ToRunID   uint                  `json:"toRunId"`
+	Changed   int                   `json:"changed"`
+	Added     int                   `json:"added"`
+	Removed   int                   `json:"removed"`
+	Unchanged int                   `json:"unchanged"`
+	Rows      []ValidationRowChange `json:"rows"`
+}
+
+type validationDiffEntry struct {
+	key    string
+	token  string
+	values ValidationRowValues
+}
+
+// DiffValidationRuns vergleicht zwei Läufe zeilenweise. Zeilen werden über Zeilenindex und normalisierten
+// Ordertoken zugeordnet; ändert sich der Token einer Zeile, gilt sie als entfernt und neu hinzugekommen.
+func DiffValidationRuns(from models.ValidationResult, to models.ValidationResult) ValidationRunDiff {
+	diff := ValidationRunDiff{FromRunID: from.ID, ToRunID: to.ID, Rows: []ValidationRowChange{}}
+
+	fromEntries := validationDiffEntries(from.ValidatedRows)
+	fromByKey := make(map[string]validationDiffEntry, len(fromEntries))
+	for _, entry := range fromEntries {
+		fromByKey[entry.key] = entry
+	}
+
+	seen := make(map[string]bool, len(fromEntries))
+	for _, entry := range validationDiffEntries(to.ValidatedRows) {
+		toValues := entry.values
+		prev, ok := fromByKey[entry.key]
+		if !ok {
+			diff.Added++
+			diff.Rows = append(diff.Rows, ValidationRowChange{Key: entry.key, OrderToken: entry.token, Change: ValidationRowAdded, To: &toValues})
+			continue
+		}
+		seen[entry.key] = true
+		statusChanged := prev.values.Status != toValues.Status
+		commissionChanged := prev.values.Commission != toValues.Commission
+		if !statusChanged && !commissionChanged {
+			diff.Unchanged++
+			continue
+		}
+		fromValues := prev.values
+		diff.Changed++
+		diff.Rows = append(diff.Rows, ValidationRowChange{
+			Key:               entry.key,
+			OrderToken:        entry.token,
+			Change:            ValidationRowChanged,
+			StatusChanged:     statusChanged,
+			CommissionChanged: commissionChanged,
+			From:              &fromValues,
+			To:                &toValues,
+		})
+	}
+	for _, entry := range fromEntries {
+		if seen[entry.key] {
+			continue
+		}
+		fromValues := entry.values
+		diff.Removed++
+		diff.Rows = append(diff.Rows, ValidationRowChange{Key: entry.key, OrderToken: entry.token, Change: ValidationRowRemoved, From: &fromValues})
+	}
+	return diff
+}
+
+func validationDiffEntries(rows []models.ValidatedRow) []validationDiffEntry {
+	entries := make([]validationDiffEntry, 0, len(rows))
+	for _, row := range rows {
+		token := strings.TrimSpace(row.Cells[OrderTokenColumn].Value)
+		if token == "" {
+			token = strings.TrimSpace(row.Cells[LegacyOrderTokenColumn].Value)
+		}
+		key := fmt.Sprintf("row:%d", row.Index)
+		if token != "" {
+			key = fmt.Sprintf("row:%d#token:%s", row.Index, NormalizeOrderToken(token))
+		}
+		entries = append(entries, validationDiffEntry{
+			key:   key,
+			token: token,
+			values: ValidationRowValues{
+				Index:      row.Index,
+				Status:     strings.TrimSpace(row.Cells[ValidationStatusColumn].Value),
+				Commission: strings.TrimSpace(row.Cells[ValidationCommissionColumn].Value),
+			},
+		})
+	}
+	return entries
+}
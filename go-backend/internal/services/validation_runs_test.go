@@ -0,0 +1,104 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"nba-dashboard/internal/models"
+)
+
+func validationRow(index int, token string, status string, commission string) models.ValidatedRow {
+	cells := map[string]models.ValidatedCell{
+		ValidationStatusColumn:     {Value: status, Status: models.CellOK},
+		ValidationCommissionColumn: {Value: commission, Status: models.CellOK},
+	}
+	if token != "" {
+		cells[OrderTokenColumn] = models.ValidatedCell{Value: token, Status: models.CellOK}
+	}
+	return models.ValidatedRow{Index: index, Cells: cells}
+}
+
+func TestDiffValidationRuns(t *testing.T) {
+	tests := []struct {
+		name string
+		from []models.ValidatedRow
+		to   []models.ValidatedRow
+		want []string
+		sum  [4]int
+	}{
+		{
+			name: "status und commission geändert",
+			from: []models.ValidatedRow{validationRow(0, "A1", "Offen", "10.00"), validationRow(1, "A2", "Offen", "5.00")},
+			to:   []models.ValidatedRow{validationRow(0, "A1", "Bestätigt", "10.00"), validationRow(1, "A2", "Offen", "6.00")},
+			want: []string{"row:0#token:A1 changed status", "row:1#token:A2 changed commission"},
+			sum:  [4]int{2, 0, 0, 0},
+		},
+		{
+			name: "normalisierter token ist dieselbe zeile",
+			from: []models.ValidatedRow{validationRow(0, "0012345", "Offen", ""), validationRow(1, "1,2345E+4", "Offen", "")},
+			to:   []models.ValidatedRow{validationRow(0, "12345", "Offen", ""), validationRow(1, "12345.0", "Storniert", "")},
+			want: []string{"row:1#token:12345 changed status"},
+			sum:  [4]int{1, 0, 0, 1},
+		},
+		{
+			name: "gleicher token in mehreren zeilen bleibt getrennt",
+			from: []models.ValidatedRow{validationRow(0, "A1", "Offen", ""), validationRow(1, "A1", "Offen", "")},
+			to:   []models.ValidatedRow{validationRow(0, "A1", "Offen", ""), validationRow(1, "A1", "Bestätigt", "")},
+			want: []string{"row:1#token:A1 changed status"},
+			sum:  [4]int{1, 0, 0, 1},
+		},
+		{
+			name: "anderer token in derselben zeile",
+			from: []models.ValidatedRow{validationRow(0, "A1", "Offen", "")},
+			to:   []models.ValidatedRow{validationRow(0, "B1", "Offen", "")},
+			want: []string{"row:0#token:B1 added", "row:0#token:A1 removed"},
+			sum:  [4]int{0, 1, 1, 0},
+		},
+		{
+			name: "zeilen ohne token über index",
+			from: []models.ValidatedRow{validationRow(0, "", "Offen", ""), validationRow(1, "", "Offen", "")},
+			to:   []models.ValidatedRow{validationRow(0, "", "Bestätigt", "")},
+			want: []string{"row:0 changed status", "row:1 removed"},
+			sum:  [4]int{1, 0, 1, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := DiffValidationRuns(
+				models.ValidationResult{ID: 1, ValidatedRows: tt.from},
+				models.ValidationResult{ID: 2, ValidatedRows: tt.to},
+			)
+			got := make([]string, 0, len(diff.Rows))
+			for _, row := range diff.Rows {
+				entry := row.Key + " " + row.Change
+				if row.StatusChanged {
+					entry += " status"
+				}
+				if row.CommissionChanged {
+					entry += " commission"
+				}
+				got = append(got, entry)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rows = %v, want %v", got, tt.want)
+			}
+			if sum := [4]int{diff.Changed, diff.Added, diff.Removed, diff.Unchanged}; sum != tt.sum {
+				t.Errorf("changed/added/removed/unchanged = %v, want %v", sum, tt.sum)
+			}
+			if diff.FromRunID != 1 || diff.ToRunID != 2 {
+				t.Errorf("run ids = %d/%d, want 1/2", diff.FromRunID, diff.ToRunID)
+			}
+		})
+	}
+}
+
+func TestDiffValidationRunsLegacyTokenColumn(t *testing.T) {
+	row := models.ValidatedRow{Index: 3, Cells: map[string]models.ValidatedCell{
+		LegacyOrderTokenColumn: {Value: " 007 ", Status: models.CellOK},
+		ValidationStatusColumn: {Value: "Offen", Status: models.CellOK},
+	}}
+	diff := DiffValidationRuns(models.ValidationResult{}, models.ValidationResult{ValidatedRows: []models.ValidatedRow{row}})
+	if len(diff.Rows) != 1 || diff.Rows[0].Key != "row:3#token:7" || diff.Rows[0].OrderToken != "007" {
+		t.Errorf("rows = %+v, want one added row keyed row:3#token:7 with token 007", diff.Rows)
+	}
+}
@@ -3,6 +3,8 @@ package services
 import (
 	"fmt"
 	"log"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,20 +21,23 @@ type ValidationContext struct {
 	PublisherID       string
 	CommissionGroupID string
 	TriggerID         string
-}
-
-var Pflichtfelder = []string{
-	"Publisher ID",
-	"Vollständiger Name des Endkunden",
-	"Adresse des Endkunden",
-	"E-Mailadresse des Endkunden",
-	"Grund der Anfrage",
-	"Timestamp",
-	"SubID",
-	"Ordertoken/OrderID",
+	// Columns ist das aufgelöste Spaltenschema des Uploads; leer bedeutet DefaultClaimColumns.
+	Columns []models.ClaimColumn
+	// Checks schaltet die Endkunden-Prüfungen (aus dem Schema); Nullwert bedeutet keine Prüfung.
+	Checks models.CustomerDataChecks
+	// TokenPool sind weitere Orders der Kampagne, die nur für den normalisierten/unscharfen
+	// Ordertoken-Abgleich herangezogen werden (z. B. Zeitfenster aus campaign_orders).
+	TokenPool []ExternalOrder
+	// Progress wird (falls gesetzt) nach jeder Zeile mit (erledigt, gesamt) aufgerufen.
+	Progress func(done int, total int)
 }
 
 func (v *ValidationService) Validate(rows []map[string]string, orders []ExternalOrder, ctx ValidationContext) []models.ValidatedRow {
+	columns := ctx.Columns
+	if len(columns) == 0 {
+		columns = DefaultClaimColumns
+	}
+
 	ordersByToken := map[string]ExternalOrder{} // Jetzt mit vollständigem ExternalOrder
 	subidSet := map[string]struct{}{}
 
@@ -45,9 +50,11 @@ func (v *ValidationService) Validate(rows []map[string]string, orders []External
 			subidSet[o.SubID] = struct{}{}
 		}
 	}
-	log.Printf("📊 Total Orders: %d, OrderTokens in Map: %d", len(orders), len(ordersByToken))
+	tokenIndex := NewOrderTokenIndex(orders, ctx.TokenPool)
+	log.Printf("📊 Total Orders: %d, OrderTokens in Map: %d, Token-Pool: %d", len(orders), len(ordersByToken), len(ctx.TokenPool))
 	log.Printf("📊 CSV Rows zu verarbeiten: %d", len(rows))
 
+	customerChecks := newCustomerDataChecker(ctx.Checks, columns, rows)
 	out := make([]models.ValidatedRow, 0, len(rows))
 
 	for i, r := range rows {
@@ -79,7 +86,11 @@ func (v *ValidationService) Validate(rows []map[string]string, orders []External
 			log.Printf("🔍 Row %d - Order-Spalten gefunden: %v", i, orderCols)
 		}
 
-		for _, col := range Pflichtfelder {
+		for _, column := range columns {
+			if !column.Required {
+				continue
+			}
+			col := column.Name
 			val := strings.TrimSpace(r[col])
 			cell := models.ValidatedCell{Value: val, Status: models.CellOK}
 
@@ -89,8 +100,8 @@ func (v *ValidationService) Validate(rows []map[string]string, orders []External
 
 			switch col {
 			case "Ordertoken/OrderID":
-				// Prüfe ALLE Spaltenvarianten, die "order" im Namen haben
-				// Sammle alle möglichen Ordertoken-Werte aus allen relevanten Spalten
+				// Sammle die Ordertoken-Werte aus Standard- und alter Spalte. Abweichende Header
+				// (z. B. "Order ID") wurden vorher über das Header-Mapping auf diese Spalte abgebildet.
 				orderTokenCandidates := []string{}
 
 				// Prüfe Standard-Spalte
@@ -103,36 +114,30 @@ func (v *ValidationService) Validate(rows []map[string]string, orders []External
 					orderTokenCandidates = append(orderTokenCandidates, altVal)
 				}
 
-				// Prüfe ALLE Spalten, die "order" im Namen haben (case-insensitive)
-				for colName, colValue := range r {
-					colNameLower := strings.ToLower(colName)
-					if strings.Contains(colNameLower, "order") &&
-						colName != "Ordertoken/OrderID" &&
-						colName != "Ordertoken/Order ID" {
-						if trimmedVal := strings.TrimSpace(colValue); trimmedVal != "" {
-							orderTokenCandidates = append(orderTokenCandidates, trimmedVal)
-							// Debug: Log wenn zusätzliche Spalte gefunden wird
-							if i < 10 {
-								log.Printf("🔍 Row %d - Zusätzliche Order-Spalte gefunden: '%s'='%s'", i, colName, trimmedVal)
-							}
-						}
-					}
-				}
-
-				// Verwende den ersten Wert, der in der API gefunden wird
+				// Verwende den ersten Wert, der exakt oder normalisiert (Groß-/Kleinschreibung, Leerzeichen,
+				// führende Nullen, wissenschaftliche Notation) in den Orders gefunden wird
 				orderTokenVal := ""
+				matchedToken := ""
 				foundInAPI := false
+				matchKind := ""
 				for _, candidate := range orderTokenCandidates {
 					candidate = strings.TrimSpace(candidate)
 					if candidate == "" {
 						continue
 					}
-					if _, ok := ordersByToken[candidate]; ok {
+					if order, kind, ok := tokenIndex.Match(candidate); ok {
 						orderTokenVal = candidate
 						foundInAPI = true
+						matchKind = kind
+						// Status/Commission unter dem Netzwerk-Token ablegen; der Dateiwert bleibt in cell.Value
+						matchedToken = strings.TrimSpace(order.OrderToken)
+						ordersByToken[matchedToken] = order
+						if kind != OrderTokenMatchExact {
+							cell.Note = fmt.Sprintf("Ordertoken normalisiert zugeordnet (Netzwerk: %q)", matchedToken)
+						}
 						// Debug
 						if i < 10 {
-							log.Printf("✅ Row %d - OrderToken GEFUNDEN in API: '%s'", i, candidate)
+							log.Printf("✅ Row %d - OrderToken GEFUNDEN in API (%s): '%s'", i, kind, candidate)
 						}
 						break
 					}
@@ -154,16 +159,23 @@ func (v *ValidationService) Validate(rows []map[string]string, orders []External
 					cell.Value = ""
 				} else if foundInAPI {
 					cell.Value = orderTokenVal
+					cell.MatchedValue = matchedToken
+					cell.Match = matchKind
 					cell.Status = models.CellOK
 					remarkO = "Bereits im Netzwerk"
 					remarkP = "weitere Bearbeitung folgt nach Feedback vom Advertiser"
 					// Debug: Log was zurückgegeben wird
 					if i < 5 {
-						log.Printf("🔍 Row %d - Cell zurückgegeben: Value='%s', Status='%s'", i, cell.Value, cell.Status)
+						log.Printf("🔍 Row %d - Cell zurückgegeben: Value='%s', Status='%s', Match='%s'", i, cell.Value, cell.Status, matchKind)
 					}
 				} else {
 					cell.Value = orderTokenVal
 					cell.Status = models.CellInvalid
+					if tokenIndex.Len() > 0 {
+						// "Meinten Sie …?" mit Konfidenz statt nur invalid
+						cell.Suggestions = tokenIndex.Suggest(orderTokenVal)
+						cell.Note = OrderTokenSuggestionNote(cell.Suggestions)
+					}
 					// Debug: Log was zurückgegeben wird
 					if i < 5 {
 						log.Printf("🔍 Row %d - Cell zurückgegeben: Value='%s', Status='%s'", i, cell.Value, cell.Status)
@@ -199,18 +211,38 @@ func (v *ValidationService) Validate(rows []map[string]string, orders []External
 				}
 
 				if tok != "" {
-					if o, ok := ordersByToken[tok]; ok && o.Timestamp != "" {
+					if o, _, ok := tokenIndex.Match(tok); ok && o.Timestamp != "" {
 						if !sameDay(val, o.Timestamp) {
 							cell.Status = models.CellInvalid
 							cell.Note = "Timestamp passt nicht zum Netzwerk"
 						}
 					}
 				}
+
+			default:
+				if note := claimColumnValueError(column, val); note != "" {
+					cell.Status = models.CellInvalid
+					cell.Note = note
+				}
 			}
 
 			cells[col] = cell
 		}
 
+		// Optionale Spalten mit Typ/erlaubten Werten nur melden, wenn sie befüllt und ungültig sind.
+		for _, column := range columns {
+			if column.Required || column.Type == models.ClaimColumnTypeText {
+				continue
+			}
+			val := strings.TrimSpace(r[column.Name])
+			if note := claimColumnValueError(column, val); note != "" {
+				cells[column.Name] = models.ValidatedCell{Value: val, Status: models.CellInvalid, Note: note}
+			}
+		}
+
+		// Endkunden-Daten (E-Mail, Adresse/PLZ, Name, Dubletten) fachlich prüfen
+		customerChecks.apply(i, r, cells)
+
 		// Status aus API in Spalte "Status in der uppr Performance Platform" eintragen
 		statusColName := "Status in der uppr Performance Platform"
 		commissionColName := "Commission aus Netzwerk"
@@ -218,32 +250,27 @@ func (v *ValidationService) Validate(rows []map[string]string, orders []External
 		// Finde das OrderToken für diese Zeile
 		// Zuerst aus der bereits validierten Zelle (falls vorhanden)
 		orderTokenForStatus := ""
-		if orderTokenCell, ok := cells["Ordertoken/OrderID"]; ok && orderTokenCell.Value != "" {
-			orderTokenForStatus = orderTokenCell.Value
+		if orderTokenCell, ok := cells["Ordertoken/OrderID"]; ok && orderTokenCell.MatchedValue != "" {
+			orderTokenForStatus = orderTokenCell.MatchedValue
 		}
 
 		// Falls nicht gefunden, hole es direkt aus der CSV-Zeile
 		if orderTokenForStatus == "" {
 			// Prüfe Standard-Spalte
 			if val := strings.TrimSpace(r["Ordertoken/OrderID"]); val != "" {
-				if _, ok := ordersByToken[val]; ok {
-					orderTokenForStatus = val
+				if order, _, ok := tokenIndex.Match(val); ok {
+					orderTokenForStatus = strings.TrimSpace(order.OrderToken)
+					ordersByToken[orderTokenForStatus] = order
 				}
 			}
 		}
 
-		// Falls immer noch nicht gefunden, prüfe alternative Spaltennamen
+		// Falls immer noch nicht gefunden, prüfe die alte Schreibweise
 		if orderTokenForStatus == "" {
-			// Prüfe alle Order-Spalten in der CSV
-			for colName, colValue := range r {
-				colNameLower := strings.ToLower(colName)
-				if strings.Contains(colNameLower, "order") {
-					if trimmedVal := strings.TrimSpace(colValue); trimmedVal != "" {
-						if _, ok := ordersByToken[trimmedVal]; ok {
-							orderTokenForStatus = trimmedVal
-							break
-						}
-					}
+			if val := strings.TrimSpace(r["Ordertoken/Order ID"]); val != "" {
+				if order, _, ok := tokenIndex.Match(val); ok {
+					orderTokenForStatus = strings.TrimSpace(order.OrderToken)
+					ordersByToken[orderTokenForStatus] = order
 				}
 			}
 		}
@@ -346,6 +373,9 @@ func (v *ValidationService) Validate(rows []map[string]string, orders []External
 			RemarkO: remarkO,
 			RemarkP: remarkP,
 		})
+		if ctx.Progress != nil {
+			ctx.Progress(i+1, len(rows))
+		}
 	}
 
 	return out
@@ -490,6 +520,38 @@ func inferCommissionForRow(row map[string]string, orders []ExternalOrder, ctx Va
 	return best
 }
 
+// claimColumnValueError prüft einen befüllten Wert gegen den Spaltentyp und liefert sonst "".
+func claimColumnValueError(column models.ClaimColumn, val string) string {
+	if val == "" {
+		return ""
+	}
+	switch column.Type {
+	case models.ClaimColumnTypeEmail:
+		at := strings.Index(val, "@")
+		if at <= 0 || at == len(val)-1 || strings.ContainsAny(val, " ,;") {
+			return "E-Mail-Adresse ungültig"
+		}
+	case models.ClaimColumnTypeDate, models.ClaimColumnTypeDateTime:
+		if _, err := time.Parse("2006-01-02", val); err != nil && !looksLikeDate(val) {
+			return "Datum nicht lesbar"
+		}
+	case models.ClaimColumnTypeDecimal:
+		// Deutsches Format ("1.234,56") nur umschreiben, wenn ein Komma vorkommt; sonst gilt der Punkt als Dezimaltrenner
+		normalized := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(val), "€"))
+		if strings.Contains(normalized, ",") {
+			normalized = strings.ReplaceAll(strings.ReplaceAll(normalized, ".", ""), ",", ".")
+		}
+		if _, err := strconv.ParseFloat(normalized, 64); err != nil {
+			return "Keine gültige Zahl"
+		}
+	case models.ClaimColumnTypeEnum:
+		if !slices.ContainsFunc(column.AllowedValues, func(allowed string) bool { return strings.EqualFold(allowed, val) }) {
+			return "Erlaubt: " + strings.Join(column.AllowedValues, ", ")
+		}
+	}
+	return ""
+}
+
 // helpers
 func looksLikeDate(s string) bool {
 	_, err := parseFlexibleTime(s)
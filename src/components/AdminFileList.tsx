@@ -291,7 +291,7 @@ const AdminFileList = ({ advertisers, onGrantAccess }: AdminFileListProps) => {
   };
 
   const isAdvertiserManualRequest = (file: UploadItem): boolean =>
-    file.filename.toLowerCase().startsWith("manual_request_advertiser_");
+    file.kind === "advertiser_manual_request";
 
   const handleAdvertiserAssignment = async (uploadId: number) => {
     const currentFile = uploads.find((item) => item.id === uploadId);
@@ -193,8 +193,8 @@ const AdvertiserFileList = ({
     api.get('/users').then(res => setAllUsers(res.data)).catch(() => setAllUsers([]));
   }, []);
 
-  const getAdvertiserStatusMeta = (status: string, filename?: string): AdvertiserStatusMeta => {
-    const isAdvertiserManualRequest = (filename || "").toLowerCase().startsWith("manual_request_advertiser_");
+  const getAdvertiserStatusMeta = (status: string, kind?: string): AdvertiserStatusMeta => {
+    const isAdvertiserManualRequest = kind === "advertiser_manual_request";
 
     if (status === "nba_received" || status === "pending" || status === "assigned") {
       return {
@@ -706,8 +706,8 @@ const AdvertiserFileList = ({
     }
   };
 
-  const isAdvertiserManualRequest = (filename: string) =>
-    (filename || "").toLowerCase().startsWith("manual_request_advertiser_");
+  const isAdvertiserManualRequest = (kind?: string) =>
+    kind === "advertiser_manual_request";
 
   const handleComplete = async (uploadId: number) => {
     setIsCompleting(prev => ({ ...prev, [uploadId]: true }));
@@ -780,8 +780,8 @@ const AdvertiserFileList = ({
                 {file.filename}
               </div>
               <div className="col-span-2 flex items-center">
-                <span className={`rounded-full px-2 py-1 text-xs font-semibold ${getAdvertiserStatusMeta(file.status, file.filename).badgeClassName}`}>
-                  {getAdvertiserStatusMeta(file.status, file.filename).label}
+                <span className={`rounded-full px-2 py-1 text-xs font-semibold ${getAdvertiserStatusMeta(file.status, file.kind).badgeClassName}`}>
+                  {getAdvertiserStatusMeta(file.status, file.kind).label}
                 </span>
               </div>
               <div className="col-span-2 text-gray-600">
@@ -900,7 +900,7 @@ const AdvertiserFileList = ({
                 >
                   <ArrowDown size={16} className="text-gray-600" />
                 </Button>
-                {isAdvertiserManualRequest(file.filename) && file.status === "returned_to_publisher" && (
+                {isAdvertiserManualRequest(file.kind) && file.status === "returned_to_publisher" && (
                   <>
                     <Button
                       variant="outline"
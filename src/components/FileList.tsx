@@ -28,6 +28,7 @@ import { UploadListSortMenu } from "@/components/UploadListSortMenu";
 
 interface FileItem {
   name: string;
+  kind?: string;
   uploadDate: string;
   advertiser: string;
   participants?: Array<{ label: string; tone: "publisher" | "advertiser" }>;
@@ -237,7 +238,7 @@ const FileList = ({
   };
 
   const isAdvertiserManualRequest = (file: FileItem): boolean =>
-    (file.name || "").toLowerCase().startsWith("manual_request_advertiser_");
+    file.kind === "advertiser_manual_request";
 
   const getProgressVisual = (file: FileItem, fileId: number) => {
     if (file.status === "feedback_submitted") {
@@ -2,15 +2,18 @@ import Header from "@/components/Header";
 import AdvertiserFileList from "@/components/AdvertiserFileList";
 import { useEffect, useMemo, useState } from "react";
 import { uploadService } from "@/services/uploadService";
+import { advertiserService } from "@/services/advertiserService";
+import { authService } from "@/services/authService";
 import { Accordion, AccordionItem, AccordionTrigger, AccordionContent } from "@/components/ui/accordion";
 import FileList from "@/components/FileList";
-import { UploadItem } from "@/types/upload";
+import { ClaimColumn, UploadItem } from "@/types/upload";
 import { Card, CardContent } from "@/components/ui/card";
 import { Button } from "@/components/ui/button";
 import { Input } from "@/components/ui/input";
 import { sessionMeta } from "@/utils/sessionMeta";
 import { useToast } from "@/hooks/use-toast";
 import { sortUploads, type UploadListSortOrder } from "@/utils/uploadListSort";
+import { emptyManualRow, missingRequiredCells } from "@/utils/manualRequestColumns";
 
 type AdvertiserFilter =
   | "all"
@@ -28,25 +31,6 @@ interface SavedAdvertiserView {
 }
 
 const SAVED_ADVERTISER_VIEWS_KEY = "advertiserDashboardSavedViews";
-const MANUAL_REQUEST_COLUMNS = [
-  "Publisher ID",
-  "Vollständiger Name des Endkunden",
-  "Adresse des Endkunden",
-  "E-Mailadresse des Endkunden",
-  "Sonstige Daten/Dokumente des Endkunden (Optional)",
-  "Höhe der Provision (Optional)",
-  "Grund der Anfrage",
-  "Timestamp",
-  "SubID",
-  "Ordertoken/OrderID",
-  "Ordertoken/Order ID",
-  "Abgeschlossener Tarif",
-  "Belieferungsbeginn (Optional)",
-  "Feedback",
-  "Status in der uppr Performance Platform",
-  "Sonstiges Feedback",
-];
-const REQUIRED_MANUAL_COLUMNS = [0, 1, 2, 3, 6];
 type PublisherOption = { id: number; name: string; email: string };
 
 const toCompanyLabel = (value?: string) => {
@@ -63,9 +47,9 @@ const AdvertiserDashboard = () => {
   const [savedViews, setSavedViews] = useState<SavedAdvertiserView[]>([]);
   const [isLoading, setIsLoading] = useState(true);
   const [error, setError] = useState<string | null>(null);
-  const [manualRows, setManualRows] = useState<string[][]>([
-    Array(MANUAL_REQUEST_COLUMNS.length).fill(""),
-  ]);
+  // Spalten aus dem eigenen Schema (/api/templates/:advertiser.json), damit die Zeilen zum CSV-Header passen.
+  const [manualColumns, setManualColumns] = useState<ClaimColumn[]>([]);
+  const [manualRows, setManualRows] = useState<string[][]>([[]]);
   const [isSubmittingManualRequest, setIsSubmittingManualRequest] = useState(false);
   const [manualValidationErrors, setManualValidationErrors] = useState<string[]>([]);
   const [publishers, setPublishers] = useState<PublisherOption[]>([]);
@@ -93,6 +77,17 @@ const AdvertiserDashboard = () => {
     return () => window.removeEventListener("uploads-updated", reload);
   }, []);
 
+  useEffect(() => {
+    authService
+      .getMe()
+      .then((me) => advertiserService.getClaimSchema(me.id))
+      .then((schema) => {
+        setManualColumns(schema.columns);
+        setManualRows([emptyManualRow(schema.columns)]);
+      })
+      .catch(() => setManualColumns([]));
+  }, []);
+
   useEffect(() => {
     uploadService
       .getPublishers()
@@ -132,8 +127,8 @@ const AdvertiserDashboard = () => {
   }, []);
 
   const openFiles = useMemo(() => uploads.filter((u) => u.status !== "completed"), [uploads]);
-  const isAdvertiserManualRequest = (filename: string) =>
-    (filename || "").toLowerCase().startsWith("manual_request_advertiser_");
+  const isAdvertiserManualRequest = (kind?: string) =>
+    kind === "advertiser_manual_request";
   const completedFiles = useMemo(() => uploads.filter((u) => u.status === "completed"), [uploads]);
   const getParticipantsForFile = (file: UploadItem) => {
     const publisherLabel = toCompanyLabel(file.uploaded_by);
@@ -190,11 +185,11 @@ const AdvertiserDashboard = () => {
     [openFiles]
   );
   const feedbackPipelineManualCount = useMemo(
-    () => openFiles.filter((f) => f.status === "feedback" && isAdvertiserManualRequest(f.filename)).length,
+    () => openFiles.filter((f) => f.status === "feedback" && isAdvertiserManualRequest(f.kind)).length,
     [openFiles]
   );
   const feedbackPipelineDefaultCount = useMemo(
-    () => openFiles.filter((f) => f.status === "feedback" && !isAdvertiserManualRequest(f.filename)).length,
+    () => openFiles.filter((f) => f.status === "feedback" && !isAdvertiserManualRequest(f.kind)).length,
     [openFiles]
   );
   const feedbackReceivedCount = useMemo(
@@ -202,11 +197,11 @@ const AdvertiserDashboard = () => {
     [openFiles]
   );
   const feedbackReceivedManualCount = useMemo(
-    () => openFiles.filter((f) => f.status === "returned_to_publisher" && isAdvertiserManualRequest(f.filename)).length,
+    () => openFiles.filter((f) => f.status === "returned_to_publisher" && isAdvertiserManualRequest(f.kind)).length,
     [openFiles]
   );
   const feedbackReceivedDefaultCount = useMemo(
-    () => openFiles.filter((f) => f.status === "returned_to_publisher" && !isAdvertiserManualRequest(f.filename)).length,
+    () => openFiles.filter((f) => f.status === "returned_to_publisher" && !isAdvertiserManualRequest(f.kind)).length,
     [openFiles]
   );
   const statusLegend = useMemo(
@@ -275,7 +270,7 @@ const AdvertiserDashboard = () => {
   };
 
   const addManualRow = () => {
-    setManualRows((prev) => [...prev, Array(MANUAL_REQUEST_COLUMNS.length).fill("")]);
+    setManualRows((prev) => [...prev, emptyManualRow(manualColumns)]);
   };
 
   const removeManualRow = (rowIndex: number) => {
@@ -300,6 +295,14 @@ const AdvertiserDashboard = () => {
       });
       return;
     }
+    if (manualColumns.length === 0) {
+      toast({
+        title: "Spalten fehlen",
+        description: "Die Spalten der Nachbuchungsvorlage konnten nicht geladen werden. Bitte Seite aktualisieren.",
+        variant: "destructive",
+      });
+      return;
+    }
 
     const hasAnyContent = manualRows.some((row) => row.some((cell) => cell.trim() !== ""));
     if (!hasAnyContent) {
@@ -311,16 +314,11 @@ const AdvertiserDashboard = () => {
       return;
     }
 
-    const validationErrors: string[] = [];
-    manualRows.forEach((row, rowIndex) => {
-      const rowHasContent = row.some((cell) => cell.trim() !== "");
-      if (!rowHasContent) return;
-      REQUIRED_MANUAL_COLUMNS.forEach((columnIndex) => {
-        if (!(row[columnIndex] || "").trim()) {
-          validationErrors.push(`${rowIndex}-${columnIndex}`);
-        }
-      });
-    });
+    const validationErrors = missingRequiredCells(
+      manualRows,
+      manualColumns,
+      manualColumns.map((_, index) => index)
+    );
 
     if (validationErrors.length > 0) {
       setManualValidationErrors(validationErrors);
@@ -347,7 +345,7 @@ const AdvertiserDashboard = () => {
         title: "Anfrage übermittelt",
         description: "Die Anfrage wurde erstellt und als Advertiser-Rückfrage markiert.",
       });
-      setManualRows([Array(MANUAL_REQUEST_COLUMNS.length).fill("")]);
+      setManualRows([emptyManualRow(manualColumns)]);
       setManualValidationErrors([]);
       setSelectedPublisherOption("");
       window.dispatchEvent(new Event("uploads-updated"));
@@ -495,9 +493,10 @@ const AdvertiserDashboard = () => {
                   <thead className="bg-slate-100">
                     <tr>
                       <th className="whitespace-nowrap border-b px-3 py-2 text-left font-medium text-slate-700">Aktion</th>
-                      {MANUAL_REQUEST_COLUMNS.map((column) => (
-                        <th key={column} className="whitespace-nowrap border-b px-3 py-2 text-left font-medium text-slate-700">
-                          {column}
+                      {manualColumns.map((column) => (
+                        <th key={column.name} className="whitespace-nowrap border-b px-3 py-2 text-left font-medium text-slate-700">
+                          {column.name}
+                          {column.required ? " *" : ""}
                         </th>
                       ))}
                     </tr>
@@ -517,7 +516,7 @@ const AdvertiserDashboard = () => {
                             Entfernen
                           </Button>
                         </td>
-                        {MANUAL_REQUEST_COLUMNS.map((_, columnIndex) => (
+                        {manualColumns.map((_, columnIndex) => (
                           <td key={`manual-cell-${rowIndex}-${columnIndex}`} className="border-b px-2 py-2">
                             <Input
                               value={row[columnIndex] || ""}
@@ -549,6 +548,7 @@ const AdvertiserDashboard = () => {
                 onListSortOrderChange={setListSortOrder}
                 files={sortedCompletedFiles.map(file => ({
                   name: file.filename,
+                  kind: file.kind,
                   uploadDate: file.upload_date ? new Date(file.upload_date).toLocaleDateString('de-DE') : '',
                   advertiser: file.uploaded_by || '',
                   participants: getParticipantsForFile(file),
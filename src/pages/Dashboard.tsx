@@ -4,7 +4,7 @@ import UploadArea from "@/components/UploadArea";
 import FileList from "@/components/FileList";
 import { uploadService } from "@/services/uploadService";
 import { advertiserService } from "@/services/advertiserService";
-import { Advertiser, UploadItem } from "@/types/upload";
+import { Advertiser, ClaimColumn, UploadItem } from "@/types/upload";
 import { useToast } from "@/hooks/use-toast";
 import { Accordion, AccordionItem, AccordionTrigger, AccordionContent } from "@/components/ui/accordion";
 import { Card, CardContent } from "@/components/ui/card";
@@ -13,28 +13,15 @@ import { Input } from "@/components/ui/input";
 import { sessionMeta } from "@/utils/sessionMeta";
 import { getStatusMeta, isFeedbackPipelineStatus } from "@/utils/uploadStatus";
 import { sortUploads, type UploadListSortOrder } from "@/utils/uploadListSort";
+import {
+  emptyManualRow,
+  missingRequiredCells,
+  publisherVisibleColumnIndexes,
+  remapManualRows,
+} from "@/utils/manualRequestColumns";
 
 type PublisherFilter = "all" | "pending" | "assigned" | "feedback" | "feedback_submitted" | "feedback_submitted_advertiser" | "sent_to_publisher_advertiser" | "returned_to_publisher";
 
-const MANUAL_REQUEST_COLUMNS = [
-  "Publisher ID",
-  "Vollständiger Name des Endkunden",
-  "Adresse des Endkunden",
-  "E-Mailadresse des Endkunden",
-  "Sonstige Daten/Dokumente des Endkunden (Optional)",
-  "Höhe der Provision (Optional)",
-  "Grund der Anfrage",
-  "Timestamp",
-  "SubID",
-  "Ordertoken/OrderID",
-  "Ordertoken/Order ID",
-  "Abgeschlossener Tarif",
-  "Belieferungsbeginn (Optional)",
-  "Feedback",
-  "Status in der uppr Performance Platform",
-  "Sonstiges Feedback",
-];
-
 interface SavedPublisherView {
   id: string;
   name: string;
@@ -48,7 +35,6 @@ interface TokenMatchPreview {
 }
 
 const SAVED_VIEWS_KEY = "publisherDashboardSavedViews";
-const REQUIRED_MANUAL_COLUMNS = [0, 1, 2, 3, 6];
 const splitSearchTerms = (value: string): string[] =>
   value
     .toLowerCase()
@@ -70,9 +56,9 @@ const Dashboard = () => {
   const [savedViews, setSavedViews] = useState<SavedPublisherView[]>([]);
   const [isLoading, setIsLoading] = useState(true);
   const [error, setError] = useState<string | null>(null);
-  const [manualRows, setManualRows] = useState<string[][]>([
-    Array(MANUAL_REQUEST_COLUMNS.length).fill(""),
-  ]);
+  // Spalten kommen aus dem Schema des gewählten Advertisers (/api/templates/:advertiser.json).
+  const [manualColumns, setManualColumns] = useState<ClaimColumn[]>([]);
+  const [manualRows, setManualRows] = useState<string[][]>([[]]);
   const [isSubmittingManualRequest, setIsSubmittingManualRequest] = useState(false);
   const [advertisers, setAdvertisers] = useState<Advertiser[]>([]);
   const [selectedAdvertiserOption, setSelectedAdvertiserOption] = useState<string>("");
@@ -145,20 +131,10 @@ const Dashboard = () => {
     () => openFiles.filter((u) => u.status === "returned_to_publisher" || isFeedbackPipelineStatus(u.status)).length,
     [openFiles]
   );
-  const publisherVisibleManualColumnIndexes = useMemo(() => {
-    const orderTokenColumnIndexes = MANUAL_REQUEST_COLUMNS.reduce<number[]>((acc, column, index) => {
-      const normalized = column.toLowerCase().replace(/\s+/g, "");
-      if (normalized.includes("ordertoken/orderid")) {
-        acc.push(index);
-      }
-      return acc;
-    }, []);
-    if (orderTokenColumnIndexes.length < 2) {
-      return MANUAL_REQUEST_COLUMNS.map((_, index) => index);
-    }
-    const secondOrderTokenIndex = orderTokenColumnIndexes[1];
-    return MANUAL_REQUEST_COLUMNS.map((_, index) => index).filter((index) => index < secondOrderTokenIndex);
-  }, []);
+  const publisherVisibleManualColumnIndexes = useMemo(
+    () => publisherVisibleColumnIndexes(manualColumns),
+    [manualColumns]
+  );
   const filteredOpenFiles = useMemo(() => {
     const byFilter =
       activeFilter === "all"
@@ -421,8 +397,35 @@ const Dashboard = () => {
     }
   };
 
+  useEffect(() => {
+    const advertiserId = selectedAdvertiserOption.startsWith("id:")
+      ? Number(selectedAdvertiserOption.replace("id:", ""))
+      : 0;
+    if (!advertiserId) return;
+    let cancelled = false;
+    advertiserService
+      .getClaimSchema(advertiserId)
+      .then((schema) => {
+        if (cancelled) return;
+        setManualRows((prev) => remapManualRows(prev, manualColumns, schema.columns));
+        setManualColumns(schema.columns);
+        setManualValidationErrors([]);
+      })
+      .catch(() => {
+        if (cancelled) return;
+        toast({
+          title: "Spalten konnten nicht geladen werden",
+          description: "Bitte Advertiser erneut auswählen.",
+          variant: "destructive",
+        });
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, [selectedAdvertiserOption]);
+
   const addManualRow = () => {
-    setManualRows((prev) => [...prev, Array(MANUAL_REQUEST_COLUMNS.length).fill("")]);
+    setManualRows((prev) => [...prev, emptyManualRow(manualColumns)]);
   };
 
   const removeManualRow = (rowIndex: number) => {
@@ -476,14 +479,14 @@ const Dashboard = () => {
       const next = prev.map((row) => [...row]);
       const requiredRows = rowIndex + parsedRows.length;
       while (next.length < requiredRows) {
-        next.push(Array(MANUAL_REQUEST_COLUMNS.length).fill(""));
+        next.push(emptyManualRow(manualColumns));
       }
 
       parsedRows.forEach((cells, rowOffset) => {
         cells.forEach((cellValue, colOffset) => {
           const targetRow = rowIndex + rowOffset;
           const targetCol = columnIndex + colOffset;
-          if (targetCol < MANUAL_REQUEST_COLUMNS.length) {
+          if (targetCol < manualColumns.length) {
             next[targetRow][targetCol] = cellValue;
           }
         });
@@ -579,6 +582,14 @@ const Dashboard = () => {
       });
       return;
     }
+    if (manualColumns.length === 0) {
+      toast({
+        title: "Spalten fehlen",
+        description: "Die Spalten des Advertisers sind noch nicht geladen.",
+        variant: "destructive",
+      });
+      return;
+    }
 
     const hasAnyContent = manualRows.some((row) => row.some((cell) => cell.trim() !== ""));
     if (!hasAnyContent) {
@@ -590,16 +601,7 @@ const Dashboard = () => {
       return;
     }
 
-    const validationErrors: string[] = [];
-    manualRows.forEach((row, rowIndex) => {
-      const rowHasContent = row.some((cell) => cell.trim() !== "");
-      if (!rowHasContent) return;
-      REQUIRED_MANUAL_COLUMNS.forEach((columnIndex) => {
-        if (!(row[columnIndex] || "").trim()) {
-          validationErrors.push(`${rowIndex}-${columnIndex}`);
-        }
-      });
-    });
+    const validationErrors = missingRequiredCells(manualRows, manualColumns, publisherVisibleManualColumnIndexes);
     if (validationErrors.length > 0) {
       setManualValidationErrors(validationErrors);
       const [firstError] = validationErrors;
@@ -634,7 +636,7 @@ const Dashboard = () => {
         title: "Anfrage übermittelt",
         description: "Die händische Anfrage wurde als Datei erstellt und an den Admin übergeben.",
       });
-      setManualRows([Array(MANUAL_REQUEST_COLUMNS.length).fill("")]);
+      setManualRows([emptyManualRow(manualColumns)]);
       setManualValidationErrors([]);
       sessionMeta.setLastAction("Manuelle Anfrage erstellt und gesendet");
       fetchUploads();
@@ -690,14 +692,20 @@ const Dashboard = () => {
             </div>
           </div>
 
+          {manualColumns.length === 0 ? (
+            <p className="rounded-xl border px-3 py-6 text-center text-sm text-slate-500">
+              Bitte zuerst einen Advertiser wählen – die Spalten richten sich nach seiner Nachbuchungsvorlage.
+            </p>
+          ) : (
           <div className="overflow-x-auto rounded-xl border">
             <table className="min-w-[1100px] text-sm">
               <thead className="bg-slate-100">
                 <tr>
                   <th className="whitespace-nowrap border-b px-3 py-2 text-left font-medium text-slate-700">Aktion</th>
                   {publisherVisibleManualColumnIndexes.map((columnIndex) => (
-                    <th key={MANUAL_REQUEST_COLUMNS[columnIndex]} className="whitespace-nowrap border-b px-3 py-2 text-left font-medium text-slate-700">
-                      {MANUAL_REQUEST_COLUMNS[columnIndex]}
+                    <th key={manualColumns[columnIndex].name} className="whitespace-nowrap border-b px-3 py-2 text-left font-medium text-slate-700">
+                      {manualColumns[columnIndex].name}
+                      {manualColumns[columnIndex].required ? " *" : ""}
                     </th>
                   ))}
                 </tr>
@@ -743,6 +751,7 @@ const Dashboard = () => {
               </tbody>
             </table>
           </div>
+          )}
             </AccordionContent>
           </AccordionItem>
         </Accordion>
@@ -826,6 +835,7 @@ const Dashboard = () => {
                 onListSortOrderChange={setListSortOrder}
                 files={sortedOpenFiles.map(file => ({
                   name: file.filename,
+                  kind: file.kind,
                   uploadDate: file.upload_date ? new Date(file.upload_date).toLocaleDateString('de-DE') : '',
                   advertiser: file.uploaded_by || '',
                   participants: getParticipantsForFile(file),
@@ -850,6 +860,7 @@ const Dashboard = () => {
                 onListSortOrderChange={setListSortOrder}
                 files={(sortedCompletedFiles ?? []).map(file => ({
                   name: file.filename,
+                  kind: file.kind,
                   uploadDate: file.upload_date ? new Date(file.upload_date).toLocaleDateString('de-DE') : '',
                   advertiser: file.uploaded_by || '',
                   participants: getParticipantsForFile(file),
@@ -1,5 +1,5 @@
 import api from './api';
-import { Advertiser } from '@/types/upload';
+import { Advertiser, ClaimColumn, ClaimSchema, ClaimSchemaInput, ClaimTemplateSchema, CustomerDataChecks } from '@/types/upload';
 
 export const advertiserService = {
   // Get all advertisers
@@ -7,4 +7,39 @@ export const advertiserService = {
     const response = await api.get('/advertisers');
     return response.data;
   },
+
+  // Spalten der Nachbuchungsvorlage eines Advertisers
+  getClaimSchema: async (advertiserId: number, campaignId?: string): Promise<ClaimTemplateSchema> => {
+    const response = await api.get(`/templates/${advertiserId}.json`, { params: campaignId ? { campaignId } : undefined });
+    return response.data;
+  },
+
+  // Gespeicherte Spaltenschemas (Admin)
+  listClaimSchemas: async (): Promise<{ schemas: ClaimSchema[]; defaultColumns: ClaimColumn[]; defaultChecks: CustomerDataChecks }> => {
+    const response = await api.get('/claim-schemas');
+    return response.data;
+  },
+
+  // Schema für Advertiser + Kampagne anlegen oder ersetzen (Admin)
+  saveClaimSchema: async (input: ClaimSchemaInput): Promise<ClaimSchema> => {
+    const response = await api.put('/claim-schemas', input);
+    return response.data;
+  },
+
+  deleteClaimSchema: async (schemaId: number): Promise<void> => {
+    await api.delete(`/claim-schemas/${schemaId}`);
+  },
+
+  // Nachbuchungsvorlage als Excel oder CSV herunterladen
+  downloadClaimTemplate: async (advertiserId: number, format: 'xlsx' | 'csv' = 'xlsx'): Promise<void> => {
+    const response = await api.get(`/templates/${advertiserId}.${format}`, { responseType: 'blob' });
+    const url = window.URL.createObjectURL(new Blob([response.data]));
+    const link = document.createElement('a');
+    link.href = url;
+    link.setAttribute('download', `Nachbuchung_${advertiserId}.${format}`);
+    document.body.appendChild(link);
+    link.click();
+    link.parentNode?.removeChild(link);
+    window.URL.revokeObjectURL(url);
+  },
 }; 
\ No newline at end of file
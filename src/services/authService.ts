@@ -109,8 +109,8 @@ export const authService = {
     return response.data;
   },
 
-  getMe: async (): Promise<{ email: string; role: AuthRole; must_complete_profile: boolean; avatar_url?: string }> => {
-    const response = await api.get<{ email: string; role: AuthRole; must_complete_profile: boolean; avatar_url?: string }>("/auth/me");
+  getMe: async (): Promise<{ id: number; email: string; role: AuthRole; must_complete_profile: boolean; avatar_url?: string }> => {
+    const response = await api.get<{ id: number; email: string; role: AuthRole; must_complete_profile: boolean; avatar_url?: string }>("/auth/me");
     return response.data;
   },
 
@@ -1,6 +1,6 @@
 import axios from 'axios';
 import api from './api';
-import type { UploadItem, UploadValidationData } from '@/types/upload';
+import type { UploadItem, UploadValidationData, ValidationJob, ValidationParams, ValidationRun, ValidationRunDiff } from '@/types/upload';
 
 export interface BookingCSVExportPayload {
   campaignId: string;
@@ -35,6 +35,58 @@ export interface SaveContentWarning {
 export interface SaveFileContentResponse {
   message: string;
   warnings?: SaveContentWarning[];
+  revisionId?: number;
+  etag?: string;
+}
+
+export interface FileContentResponse {
+  data: string[][];
+  filename: string;
+  revisionId?: number;
+  etag?: string;
+}
+
+// Zuletzt gelesener Stand je Upload; wird beim Speichern als If-Match mitgeschickt (409 bei Konflikt).
+const contentETags = new Map<number, string>();
+
+export interface SplitUploadPayload {
+  column: string;
+  assign?: boolean;
+  advertiserMap?: Record<string, number>;
+  expiresAt?: string;
+}
+
+export interface SplitUploadResponse {
+  parentId: number;
+  column: string;
+  children: Array<{ uploadId: number; filename: string; value: string; rows: number; advertiserId?: number; status: string }>;
+  unmatched: string[];
+}
+
+export interface MergeUploadsResponse {
+  uploadId: number;
+  filename: string;
+  rows: number;
+  keyColumn: string;
+  sources: number[];
+  skippedRows: Array<{ sourceUploadId: number; sourceRowNo: number; orderToken: string; keptRowNo: number }>;
+  warnings: SaveContentWarning[];
+}
+
+export interface HeaderResolution {
+  index: number;
+  source: string;
+  column: string;
+  match: 'profile' | 'exact' | 'normalized' | 'alias' | 'none';
+  suggestions?: Array<{ column: string; score: number }>;
+}
+
+export interface UploadHeaderPreview {
+  uploadId: number;
+  schema: { schemaId: number | null; name: string; columns: Array<{ name: string; required: boolean }> };
+  profileIds: number[];
+  mapping: { headerRow: number; headers: HeaderResolution[]; missingRequired: string[] };
+  rowCount: number;
 }
 
 export interface ManualRequestPayload {
@@ -94,6 +146,53 @@ export const uploadService = {
     });
   },
 
+  // Große Dateien fortsetzbar in Teilstücken hochladen (Upload-Session)
+  uploadFileResumable: async (file: File, onProgress?: (uploaded: number, total: number) => void): Promise<void> => {
+    const { data: session } = await api.post('/uploads/sessions', {
+      filename: file.name,
+      size: file.size,
+      contentType: file.type,
+    });
+    let offset: number = session.offset;
+    while (offset < file.size) {
+      const chunk = file.slice(offset, offset + session.chunkMaxBytes);
+      try {
+        const { data } = await api.patch(`/uploads/sessions/${session.id}`, chunk, {
+          headers: { 'Content-Type': 'application/offset+octet-stream', 'Upload-Offset': String(offset) },
+        });
+        offset = data.offset;
+      } catch (error) {
+        // Bei Verbindungsabbruch oder Offset-Konflikt am Serverstand weitermachen
+        const { headers } = await api.head(`/uploads/sessions/${session.id}`);
+        const serverOffset = Number(headers['upload-offset']);
+        if (!Number.isFinite(serverOffset) || serverOffset <= offset) {
+          throw error;
+        }
+        offset = serverOffset;
+      }
+      onProgress?.(offset, file.size);
+    }
+    await api.post(`/uploads/sessions/${session.id}/finalize`);
+  },
+
+  // Sammeldatei nach Spalte in Teil-Uploads aufteilen (Admin)
+  splitUpload: async (uploadId: number, payload: SplitUploadPayload): Promise<SplitUploadResponse> => {
+    const response = await api.post(`/uploads/${uploadId}/split`, payload);
+    return response.data;
+  },
+
+  // Mehrere Uploads zu einer Sammeldatei zusammenführen (Admin); Quellen werden "superseded"
+  mergeUploads: async (ids: number[], filename?: string): Promise<MergeUploadsResponse> => {
+    const response = await api.post('/uploads/merge', { ids, filename });
+    return response.data;
+  },
+
+  // Vorschau, wie die Header eines Uploads auf das Schema abgebildet werden (Admin)
+  previewHeaders: async (uploadId: number, campaignId?: string): Promise<UploadHeaderPreview> => {
+    const response = await api.get(`/uploads/${uploadId}/headers`, { params: campaignId ? { campaignId } : undefined });
+    return response.data;
+  },
+
   createManualRequest: async (payload: ManualRequestPayload): Promise<void> => {
     await api.post('/uploads/manual-request', payload);
   },
@@ -133,14 +232,23 @@ export const uploadService = {
   },
 
   // Datei-Inhalt lesen
-  getFileContent: async (uploadId: number): Promise<{ data: string[][], filename: string }> => {
+  getFileContent: async (uploadId: number): Promise<FileContentResponse> => {
     const response = await api.get(`/uploads/${uploadId}/content`);
+    if (response.data?.etag) {
+      contentETags.set(uploadId, response.data.etag);
+    }
     return response.data;
   },
 
   // Datei-Inhalt speichern
   saveFileContent: async (uploadId: number, data: string[][]): Promise<SaveFileContentResponse> => {
-    const response = await api.post(`/uploads/${uploadId}/content`, { data });
+    const etag = contentETags.get(uploadId);
+    const response = await api.post(`/uploads/${uploadId}/content`, { data }, {
+      headers: etag ? { 'If-Match': etag } : undefined,
+    });
+    if (response.data?.etag) {
+      contentETags.set(uploadId, response.data.etag);
+    }
     return response.data;
   },
 
@@ -187,6 +295,32 @@ export const uploadService = {
       }
     },
 
+    // Validierung als Hintergrundjob einreihen; Fortschritt über getValidationJob abfragen
+    startValidationJob: async (
+      uploadId: number,
+      options?: {
+        campaignId?: string;
+        projectId?: string;
+        publisherId?: string;
+        commissionGroupId?: string;
+        triggerId?: string;
+        forceRefresh?: boolean;
+      }
+    ): Promise<{ jobId: number; job: ValidationJob }> => {
+      const response = await api.post(`/uploads/${uploadId}/validate`, options ?? {});
+      return response.data;
+    },
+
+    getValidationJob: async (jobId: number): Promise<ValidationJob> => {
+      const response = await api.get(`/validation-jobs/${jobId}`);
+      return response.data;
+    },
+
+    cancelValidationJob: async (jobId: number): Promise<ValidationJob> => {
+      const response = await api.post(`/validation-jobs/${jobId}/cancel`);
+      return response.data;
+    },
+
     // Gespeicherte Validierungsergebnisse laden
     getValidation: async (uploadId: number): Promise<UploadValidationData | null> => {
       try {
@@ -208,6 +342,21 @@ export const uploadService = {
       }
     },
 
+    // Historie der Validierungsläufe (ohne Zeilen, neueste zuerst)
+    listValidationRuns: async (uploadId: number): Promise<ValidationRun[]> => {
+      const response = await api.get(`/uploads/${uploadId}/validation-runs`);
+      return response.data.items;
+    },
+
+    // Zwei Läufe vergleichen; ohne runIds: vorheriger gegen neuesten Lauf
+    diffValidationRuns: async (
+      uploadId: number,
+      runIds?: { from: number; to: number }
+    ): Promise<{ uploadId: number; fromParams: ValidationParams; toParams: ValidationParams; diff: ValidationRunDiff }> => {
+      const response = await api.get(`/uploads/${uploadId}/validation-runs/diff`, { params: runIds });
+      return response.data;
+    },
+
     // Alle Validierungsergebnisse auf einmal laden
     getAllValidations: async (): Promise<Record<string, UploadValidationData>> => {
       try {
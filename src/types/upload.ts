@@ -5,9 +5,66 @@ export interface UploadItem {
   file_size: number;
   content_type: string;
   uploaded_by: string;
-  status: 'pending' | 'approved' | 'rejected' | 'granted' | 'returned_to_publisher' | 'completed' | 'assigned' | 'feedback' | 'feedback_submitted' | 'feedback_submitted_advertiser' | 'sent_to_publisher_advertiser';
+  status: 'pending' | 'approved' | 'rejected' | 'granted' | 'returned_to_publisher' | 'completed' | 'assigned' | 'feedback' | 'feedback_submitted' | 'feedback_submitted_advertiser' | 'sent_to_publisher_advertiser' | 'access_expired' | 'superseded';
+  kind?: 'publisher_file' | 'publisher_manual_request' | 'advertiser_manual_request' | 'admin_upload';
   advertiser_count?: number;
   feedback_message?: string;
+  sla_due_at?: string | null;
+  sha256?: string;
+  duplicate_of_upload_id?: number | null;
+  parent_upload_id?: number | null;
+  superseded_by_upload_id?: number | null;
+  scan_status?: '' | 'pending' | 'clean' | 'rejected' | 'error';
+  scan_findings?: Array<{ scanner: string; message: string }>;
+  scanned_at?: string | null;
+}
+
+export interface ClaimColumn {
+  name: string;
+  required: boolean;
+  type: 'text' | 'email' | 'datetime' | 'date' | 'decimal' | 'enum';
+  allowedValues?: string[];
+  description?: string;
+  example?: string;
+  role?: 'customer_name' | 'customer_address';
+}
+
+export interface ClaimTemplateSchema {
+  advertiserId: number;
+  advertiser: string;
+  schemaId: number | null;
+  schemaName: string;
+  columns: ClaimColumn[];
+}
+
+/** Endkunden-Prüfungen der Validierung, je Schema schaltbar. */
+export interface CustomerDataChecks {
+  emailSyntax: boolean;
+  disposableEmail: boolean;
+  postalCode: boolean;
+  address: boolean;
+  name: boolean;
+  duplicates: boolean;
+}
+
+export interface ClaimSchema {
+  id: number;
+  name: string;
+  advertiser_id: number;
+  campaign_external_id: string;
+  columns: ClaimColumn[];
+  checks: CustomerDataChecks | null;
+  updated_by: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface ClaimSchemaInput {
+  name: string;
+  advertiserId: number;
+  campaignId: string;
+  columns: ClaimColumn[];
+  checks?: CustomerDataChecks;
 }
 
 export interface Advertiser {
@@ -17,6 +74,23 @@ export interface Advertiser {
   company?: string;
 }
 
+/** Korrekturvorschlag für eine ungültige Zelle („Meinten Sie …?“). */
+export interface ValueSuggestion {
+  value: string;
+  confidence: number;
+  reason: 'normalized' | 'scientific' | 'prefix' | 'edit_distance';
+}
+
+export interface ValidatedCell {
+  value: string;
+  status: 'ok' | 'invalid' | 'empty';
+  note?: string;
+  /** Netzwerk-Token, dem der Dateiwert (value) zugeordnet wurde. */
+  matchedValue?: string;
+  match?: 'exact' | 'normalized';
+  suggestions?: ValueSuggestion[];
+}
+
 /** Gespeicherte Validierungs-Antwort (Auszug für Tabellen-UI). */
 export interface UploadValidationData {
   hasValidation?: boolean;
@@ -25,6 +99,75 @@ export interface UploadValidationData {
   }>;
 }
 
+export interface ValidationParams {
+  campaignId: string;
+  projectId?: string;
+  publisherId?: string;
+  commissionGroupId?: string;
+  triggerId?: string;
+  forceRefresh?: boolean;
+}
+
+export interface ValidationJob {
+  id: number;
+  upload_id: number;
+  result_id: number | null;
+  params: ValidationParams;
+  status: 'queued' | 'running' | 'succeeded' | 'failed' | 'cancelled';
+  phase: 'queued' | 'loading' | 'syncing' | 'matching' | 'saving' | 'done';
+  rows_total: number;
+  rows_done: number;
+  orders_count: number;
+  error: string;
+  error_detail: string;
+  cancel_requested: boolean;
+  attempts: number;
+  requested_by: string;
+  heartbeat_at: string | null;
+  started_at: string | null;
+  finished_at: string | null;
+  created_at: string;
+  updated_at: string;
+}
+
+/** Validierungslauf ohne Zeilen (Historie je Upload). */
+export interface ValidationRun {
+  id: number;
+  upload_id: number;
+  job_id?: number;
+  params: ValidationParams;
+  orders_count: number;
+  rows_count: number;
+  invalid_count: number;
+  empty_count: number;
+  validated_by: string;
+  validated_at: string;
+}
+
+export interface ValidationRowValues {
+  index: number;
+  status: string;
+  commission: string;
+}
+
+export interface ValidationRunDiff {
+  fromRunId: number;
+  toRunId: number;
+  changed: number;
+  added: number;
+  removed: number;
+  unchanged: number;
+  rows: Array<{
+    key: string;
+    orderToken: string;
+    change: 'changed' | 'added' | 'removed';
+    statusChanged: boolean;
+    commissionChanged: boolean;
+    from?: ValidationRowValues;
+    to?: ValidationRowValues;
+  }>;
+}
+
 /** Wie in localStorage (inkl. Metadaten). */
 export type StoredUploadValidation = UploadValidationData & {
   savedAt?: string;
@@ -0,0 +1,39 @@
+import type { ClaimColumn } from "@/types/upload";
+
+/** Leere Zeile in Schema-Breite; das Backend lehnt Zeilen mit abweichender Spaltenzahl ab. */
+export const emptyManualRow = (columns: ClaimColumn[]): string[] => Array(columns.length).fill("");
+
+/** Überträgt erfasste Werte per Spaltenname auf ein anderes Schema; neue Spalten bleiben leer. */
+export function remapManualRows(rows: string[][], from: ClaimColumn[], to: ClaimColumn[]): string[][] {
+  const fromIndex = new Map(from.map((column, index) => [column.name, index]));
+  return rows.map((row) =>
+    to.map((column) => {
+      const index = fromIndex.get(column.name);
+      return index === undefined ? "" : row[index] || "";
+    })
+  );
+}
+
+/** Publisher füllen nur die Spalten vor der zweiten Ordertoken-Spalte; danach folgen Advertiser-/Netzwerk-Felder. */
+export function publisherVisibleColumnIndexes(columns: ClaimColumn[]): number[] {
+  const indexes = columns.map((_, index) => index);
+  const orderTokenColumnIndexes = indexes.filter((index) =>
+    columns[index].name.toLowerCase().replace(/\s+/g, "").includes("ordertoken/orderid")
+  );
+  if (orderTokenColumnIndexes.length < 2) return indexes;
+  return indexes.filter((index) => index < orderTokenColumnIndexes[1]);
+}
+
+/** Zellschlüssel ("zeile-spalte") leerer Pflichtfelder in befüllten Zeilen. */
+export function missingRequiredCells(rows: string[][], columns: ClaimColumn[], columnIndexes: number[]): string[] {
+  const missing: string[] = [];
+  rows.forEach((row, rowIndex) => {
+    if (!row.some((cell) => cell.trim() !== "")) return;
+    columnIndexes.forEach((columnIndex) => {
+      if (columns[columnIndex]?.required && !(row[columnIndex] || "").trim()) {
+        missing.push(`${rowIndex}-${columnIndex}`);
+      }
+    });
+  });
+  return missing;
+}
@@ -64,6 +64,16 @@ const STATUS_META: Record<UploadStatus, StatusMeta> = {
     badgeClassName: "bg-green-100 text-green-700",
     accentClassName: "bg-green-500",
   },
+  access_expired: {
+    label: "Freigabe abgelaufen",
+    badgeClassName: "bg-orange-100 text-orange-700",
+    accentClassName: "bg-orange-500",
+  },
+  superseded: {
+    label: "Zusammengeführt",
+    badgeClassName: "bg-zinc-100 text-zinc-600",
+    accentClassName: "bg-zinc-400",
+  },
 };
 
 export const getStatusMeta = (status: string): StatusMeta => {